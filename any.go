@@ -0,0 +1,211 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MapAny binds already parsed generic data, such as decoded YAML, JSON or
+// documents from a NoSQL store, onto obj, which must be a pointer to a
+// struct, then validates obj. Keys are matched using the same tags as the form
+// bindings. Scalars are coerced like form values, so float64 numbers bind into
+// integer fields when they are integral and strings bind into time.Time fields
+// using the time_format tag. Nested maps bind into struct and map fields, and
+// lists into slice fields, or are encoded back to JSON for json.RawMessage
+// fields.
+func MapAny(obj interface{}, data map[string]interface{}) error {
+	m := &anyMapper{state: newBindState()}
+	if err := m.mapStruct(reflect.ValueOf(obj).Elem(), data, "", ""); err != nil {
+		return err
+	}
+	return m.state.validate(obj)
+}
+
+// anyMapper maps generic data onto a struct.
+type anyMapper struct {
+	state *bindState
+}
+
+// mapStruct maps data onto the struct val. prefix is the prefix tag of the
+// nested structs val is in, which the keys of its fields have in their path.
+func (m *anyMapper) mapStruct(val reflect.Value, data map[string]interface{}, path, prefix string) error {
+	info, err := cachedStructInfo(val.Type(), m.state.keyTag())
+	if err != nil {
+		return err
+	}
+	// fields with a conditional default are bound once the others are
+	var deferred []*fieldInfo
+	for _, field := range info.fields {
+		if field.sourceOnly {
+			continue
+		}
+		typ := val.Type().Field(field.index).Type
+		structField := val.Field(field.index)
+
+		if field.nested {
+			nestedData := prefixedData(data, field.prefix)
+			// recursive structs are only bound as deep as there are keys
+			if field.recursive && len(nestedData) == 0 {
+				continue
+			}
+			err := bindNested(structField, func(nested reflect.Value) error {
+				return m.mapStruct(nested, nestedData, path, prefix+field.prefix)
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		fieldPath := joinPath(path, prefix+field.key)
+		v, exists := data[field.key]
+		if !exists && field.alias != "" {
+			v, exists = data[field.alias]
+		}
+		if (!exists || v == nil) && field.defaultIf != nil {
+			deferred = append(deferred, field)
+			continue
+		}
+		var err error
+		switch {
+		case exists && v != nil:
+			if err = m.setAny(v, typ, structField, field, fieldPath); err == nil {
+				err = field.check(structField)
+			}
+		case field.defaultValue != "":
+			err = m.state.setDefault(field.defaultValue, typ, structField, field)
+		}
+		if err != nil {
+			if _, ok := err.(*FieldError); ok {
+				return err
+			}
+			return &FieldError{Path: fieldPath, Key: prefix + field.key, Err: err}
+		}
+	}
+	for _, field := range deferred {
+		value := field.conditionalDefault(val)
+		if value == "" {
+			continue
+		}
+		typ := val.Type().Field(field.index).Type
+		if err := m.state.setDefault(value, typ, val.Field(field.index), field); err != nil {
+			return &FieldError{Path: joinPath(path, prefix+field.key), Key: prefix + field.key, Err: err}
+		}
+	}
+	return nil
+}
+
+// prefixedData returns the entries of data whose key starts with prefix, with
+// prefix removed, or data itself if prefix is empty.
+func prefixedData(data map[string]interface{}, prefix string) map[string]interface{} {
+	if prefix == "" {
+		return data
+	}
+	prefixed := make(map[string]interface{})
+	for key, v := range data {
+		if k, ok := strings.CutPrefix(key, prefix); ok && k != "" {
+			prefixed[k] = v
+		}
+	}
+	return prefixed
+}
+
+// setAny binds v onto structField, whose type is typ. field is the struct
+// field being bound, also used for its elements.
+func (m *anyMapper) setAny(v interface{}, typ reflect.Type, structField reflect.Value, field *fieldInfo, path string) error {
+	if v == nil {
+		structField.Set(reflect.Zero(typ))
+		return nil
+	}
+	// interfaces hold the values as they are, strings once transformed
+	if typ.Kind() == reflect.Interface && reflect.TypeOf(v).AssignableTo(typ) {
+		if x, ok := v.(string); ok {
+			val, err := m.state.transform(x, field, StageLookup)
+			if err != nil {
+				return err
+			}
+			v = val
+		}
+		structField.Set(reflect.ValueOf(v))
+		return nil
+	}
+	switch x := v.(type) {
+	case string:
+		val, err := m.state.transform(x, field, StageLookup)
+		if err != nil {
+			return err
+		}
+		return setValue(val, typ, structField, field)
+	case json.Number:
+		return setValue(x.String(), typ, structField, field)
+	case float64:
+		return setValue(strconv.FormatFloat(x, 'f', -1, 64), typ, structField, field)
+	case float32:
+		return setValue(strconv.FormatFloat(float64(x), 'f', -1, 32), typ, structField, field)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return setValue(fmt.Sprint(x), typ, structField, field)
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		if structField.IsNil() {
+			structField.Set(reflect.New(typ.Elem()))
+		}
+		return m.setAny(v, typ.Elem(), structField.Elem(), field, path)
+	}
+
+	if typ == rawMessageType {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		structField.SetBytes(data)
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(typ) {
+		structField.Set(rv)
+		return nil
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Struct:
+			return m.mapStruct(structField, x, path, "")
+		case reflect.Map:
+			result := reflect.MakeMapWithSize(typ, len(x))
+			for key, elem := range x {
+				k, err := convertMapKey(key, typ.Key())
+				if err != nil {
+					return &MapEntryError{Key: key, Err: err}
+				}
+				elemValue := reflect.New(typ.Elem()).Elem()
+				if err := m.setAny(elem, typ.Elem(), elemValue, field, path+"."+key); err != nil {
+					return err
+				}
+				result.SetMapIndex(k, elemValue)
+			}
+			structField.Set(result)
+			return nil
+		}
+	case []interface{}:
+		if typ.Kind() != reflect.Slice {
+			break
+		}
+		result := reflect.MakeSlice(typ, len(x), len(x))
+		for i, elem := range x {
+			if err := m.setAny(elem, typ.Elem(), result.Index(i), field, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		structField.Set(result)
+		return nil
+	}
+	return fmt.Errorf("cannot bind %T into %s", v, typ)
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForAnyItem struct {
+	Name  string `form:"name"`
+	Price uint   `form:"price" max:"100"`
+}
+
+type FooStructForAny struct {
+	IntFoo   int                    `form:"int_foo"`
+	UintFoo  *uint8                 `form:"uint_foo"`
+	FloatFoo float32                `form:"float_foo"`
+	BoolFoo  bool                   `form:"bool_foo"`
+	StrFoo   string                 `form:"str_foo" alias:"old_str_foo"`
+	TimeFoo  time.Time              `form:"time_foo" time_format:"2006-01-02" time_utc:"1"`
+	Default  int                    `form:"default" default:"7"`
+	Item     FooStructForAnyItem    `form:"item"`
+	Items    []FooStructForAnyItem  `form:"items"`
+	Tags     []string               `form:"tags"`
+	Counts   map[string]int         `form:"counts"`
+	Extra    map[string]interface{} `form:"extra"`
+	Nested   struct {
+		NestedFoo string `form:"nested_foo"`
+	}
+}
+
+func TestMapAny(t *testing.T) {
+	var obj FooStructForAny
+	err := MapAny(&obj, map[string]interface{}{
+		"int_foo":     float64(42),
+		"uint_foo":    "8",
+		"float_foo":   1.5,
+		"bool_foo":    true,
+		"old_str_foo": "bar",
+		"time_foo":    "2018-09-15",
+		"item":        map[string]interface{}{"name": "one", "price": 10},
+		"items": []interface{}{
+			map[string]interface{}{"name": "two", "price": 20.0},
+			map[string]interface{}{"name": "three"},
+		},
+		"tags":       []interface{}{"a", "b"},
+		"counts":     map[string]interface{}{"x": 1.0, "y": "2"},
+		"extra":      map[string]interface{}{"k": "v"},
+		"nested_foo": "nested",
+		"unused":     nil,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, obj.IntFoo)
+	assert.Equal(t, uint8(8), *obj.UintFoo)
+	assert.Equal(t, float32(1.5), obj.FloatFoo)
+	assert.True(t, obj.BoolFoo)
+	assert.Equal(t, "bar", obj.StrFoo)
+	assert.Equal(t, time.Date(2018, 9, 15, 0, 0, 0, 0, time.UTC), obj.TimeFoo)
+	assert.Equal(t, 7, obj.Default)
+	assert.Equal(t, FooStructForAnyItem{Name: "one", Price: 10}, obj.Item)
+	assert.Equal(t, []FooStructForAnyItem{{Name: "two", Price: 20}, {Name: "three"}}, obj.Items)
+	assert.Equal(t, []string{"a", "b"}, obj.Tags)
+	assert.Equal(t, map[string]int{"x": 1, "y": 2}, obj.Counts)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, obj.Extra)
+	assert.Equal(t, "nested", obj.Nested.NestedFoo)
+}
+
+func TestMapAnyErrors(t *testing.T) {
+	var obj FooStructForAny
+	err := MapAny(&obj, map[string]interface{}{"int_foo": 1.5})
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "int_foo", fieldErr.Path)
+	}
+
+	err = MapAny(&obj, map[string]interface{}{"int_foo": []interface{}{1}})
+	assert.EqualError(t, err, `binding: field "int_foo": cannot bind []interface {} into int`)
+
+	err = MapAny(&obj, map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"price": 101}},
+	})
+	var rangeErr *RangeError
+	assert.True(t, errors.As(err, &rangeErr))
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "items[0].price", fieldErr.Path)
+	}
+}
+
+type FooStructForAnyInterface struct {
+	Value  interface{}            `form:"value"`
+	Values []interface{}          `form:"values"`
+	Extra  map[string]interface{} `form:"extra"`
+}
+
+func TestMapAnyInterface(t *testing.T) {
+	var obj FooStructForAnyInterface
+	err := MapAny(&obj, map[string]interface{}{
+		"value":  "x",
+		"values": []interface{}{3.0, true, "y", nil},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "x", obj.Value)
+	assert.Equal(t, []interface{}{3.0, true, "y", nil}, obj.Values)
+
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"value": 3.0}))
+	assert.Equal(t, 3.0, obj.Value)
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"value": map[string]interface{}{"a": 1}}))
+	assert.Equal(t, map[string]interface{}{"a": 1}, obj.Value)
+}
+
+func TestMapAnyDerive(t *testing.T) {
+	var obj FooStructForDerive
+	err := MapAny(&obj, map[string]interface{}{"email": "Ada@Example.com", "first": "Ada"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@example.com", obj.NormalizedEmail)
+	assert.Equal(t, "Ada", obj.FullName)
+
+	err = MapAny(&FooStructForDerive{}, map[string]interface{}{"email": "a@b.c"})
+	assert.EqualError(t, err, `binding: field "FullName": no name`)
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"strings"
+)
+
+// base58Alphabet is the Bitcoin base58 alphabet, without 0, O, I and l.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// decodeBase58 decodes s, each of its leading 1s being a zero byte.
+func decodeBase58(s string) ([]byte, error) {
+	// out is the big-endian number decoded so far, without leading zeros
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, fmt.Errorf("illegal base58 data at input byte %d", i)
+		}
+		for j := len(out) - 1; j >= 0; j-- {
+			carry += int(out[j]) * 58
+			out[j] = byte(carry)
+			carry >>= 8
+		}
+		for ; carry > 0; carry >>= 8 {
+			out = append([]byte{byte(carry)}, out...)
+		}
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), out...), nil
+}
+
+// encodeBase58 encodes data, each of its leading zero bytes as a 1.
+func encodeBase58(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	// digits are the little-endian base58 digits of the rest of data
+	var digits []byte
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for j := range digits {
+			carry += int(digits[j]) << 8
+			digits[j] = byte(carry % 58)
+			carry /= 58
+		}
+		for ; carry > 0; carry /= 58 {
+			digits = append(digits, byte(carry%58))
+		}
+	}
+	out := []byte(strings.Repeat("1", zeros))
+	for i := len(digits) - 1; i >= 0; i-- {
+		out = append(out, base58Alphabet[digits[i]])
+	}
+	return string(out)
+}
@@ -0,0 +1,29 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase58(t *testing.T) {
+	for encoded, data := range map[string][]byte{
+		"":                  {},
+		"1":                 {0},
+		"2NEpo7TZRRrLZSi2U": []byte("Hello World!"),
+		"11233QC4":          {0, 0, 0x28, 0x7f, 0xb4, 0xcd},
+		"5Q":                {0xff},
+	} {
+		decoded, err := decodeBase58(encoded)
+		assert.NoError(t, err, encoded)
+		assert.Equal(t, data, decoded, encoded)
+		assert.Equal(t, encoded, encodeBase58(data))
+	}
+
+	_, err := decodeBase58("3x0")
+	assert.EqualError(t, err, "illegal base58 data at input byte 2")
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+)
+
+// Content types of the record streams bound by BindEach.
+const (
+	MIMENDJSON = "application/x-ndjson"
+	MIMECSV    = "text/csv"
+)
+
+// BindEach binds the records of a bulk request body, newline-delimited JSON
+// or CSV with a header row naming the form keys of the columns, yielding
+// each one once bound and validated:
+//
+//	for row, err := range binding.BindEach[Measurement](req) {
+//		if err != nil {
+//			...
+//		}
+//		store(*row)
+//	}
+//
+// To keep allocations down, the same struct and decode buffers are reused for
+// every record, so the yielded struct is only valid until the next
+// iteration. Records failing to bind or validate yield a *RecordError and
+// iteration goes on; errors reading the body end it. The body is bounded by
+// the MaxBodySize option of DefaultOptions.
+func BindEach[T any](req *http.Request) iter.Seq2[*T, error] {
+	return bindEach[T](req, func() (*Options, error) { return &DefaultOptions, nil })
+}
+
+// BindEachWith binds the records of a bulk request body like BindEach, with
+// the Options of d.
+func BindEachWith[T any](d *Decoder, req *http.Request) iter.Seq2[*T, error] {
+	return bindEach[T](req, func() (*Options, error) { return d.options(req) })
+}
+
+func bindEach[T any](req *http.Request, options func() (*Options, error)) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		var bindRecords func(io.Reader, *bindState, func(*T, error) bool)
+		switch mediaType {
+		case MIMENDJSON, "application/jsonl":
+			bindRecords = bindJSONRecords[T]
+		case MIMECSV:
+			bindRecords = bindCSVRecords[T]
+		default:
+			yield(nil, &ContentTypeError{
+				ContentType: req.Header.Get("Content-Type"),
+				Method:      req.Method,
+				Accepted:    []string{MIMENDJSON, MIMECSV},
+			})
+			return
+		}
+		opts, err := options()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		s := &bindState{opts: opts}
+		if err := s.limitBody(req); err != nil {
+			yield(nil, err)
+			return
+		}
+		body, empty := openBody(req)
+		if empty {
+			return
+		}
+		r, _ := skipBOM(body)
+		bindRecords(r, s, yield)
+	}
+}
+
+func bindJSONRecords[T any](r io.Reader, s *bindState, yield func(*T, error) bool) {
+	dec := json.NewDecoder(r)
+	if EnableDecoderUseNumber {
+		dec.UseNumber()
+	}
+	obj := new(T)
+	for n := 1; ; n++ {
+		var zero T
+		*obj = zero
+		if err := dec.Decode(obj); err == io.EOF {
+			return
+		} else if err != nil {
+			yield(nil, &RecordError{Record: n, Err: err})
+			return
+		}
+		err := s.cleanStrings(obj)
+		if err == nil {
+			err = s.validate(obj)
+		}
+		if !yieldRecord(yield, obj, n, err) {
+			return
+		}
+	}
+}
+
+func bindCSVRecords[T any](r io.Reader, s *bindState, yield func(*T, error) bool) {
+	obj := new(T)
+	err := readCSVForms(r, func(n int, form map[string][]string) bool {
+		var zero T
+		*obj = zero
+		err := mapFormState(obj, form, s)
+		if err == nil {
+			err = s.validate(obj)
+		}
+		return yieldRecord(yield, obj, n, err)
+	})
+	if err != nil {
+		yield(nil, err)
+	}
+}
+
+// readCSVForms calls fn with the number and form of each record of the CSV
+// r, whose header row names the form keys of the columns, until fn returns
+// false. The form is reused for every record. Errors reading a record are
+// returned as a *RecordError.
+func readCSVForms(r io.Reader, fn func(n int, form map[string][]string) bool) error {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	keys := append([]string(nil), header...)
+	form := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		form[key] = make([]string, 1)
+	}
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &RecordError{Record: n, Err: err}
+		}
+		for i, key := range keys {
+			form[key][0] = record[i]
+		}
+		if !fn(n, form) {
+			return nil
+		}
+	}
+}
+
+// yieldRecord yields obj, or the error binding the nth record.
+func yieldRecord[T any](yield func(*T, error) bool, obj *T, n int, err error) bool {
+	if err != nil {
+		return yield(nil, &RecordError{Record: n, Err: err})
+	}
+	return yield(obj, nil)
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForRecord struct {
+	Name  string  `json:"name" form:"name" binding:"required"`
+	Value float64 `json:"value" form:"value"`
+}
+
+func collectRecords(t *testing.T, contentType, body string) ([]FooStructForRecord, []error) {
+	req := requestWithBody("POST", "/", body)
+	req.Header.Set("Content-Type", contentType)
+	var (
+		records []FooStructForRecord
+		errs    []error
+	)
+	for record, err := range BindEach[FooStructForRecord](req) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		records = append(records, *record)
+	}
+	return records, errs
+}
+
+func TestBindEachNDJSON(t *testing.T) {
+	records, errs := collectRecords(t, MIMENDJSON, "{\"name\":\"a\",\"value\":1}\n{\"value\":2}\n\n{\"name\":\"c\"}\n")
+	assert.Equal(t, []FooStructForRecord{{Name: "a", Value: 1}, {Name: "c"}}, records)
+	if assert.Len(t, errs, 1) {
+		var recordErr *RecordError
+		assert.True(t, errors.As(errs[0], &recordErr))
+		assert.Equal(t, 2, recordErr.Record)
+	}
+
+	records, errs = collectRecords(t, MIMENDJSON, "{\"name\":\"a\"}\n{\"name\":")
+	assert.Len(t, records, 1)
+	assert.Len(t, errs, 1)
+}
+
+func TestBindEachCSV(t *testing.T) {
+	records, errs := collectRecords(t, "text/csv; charset=utf-8", "name,value\na,1.5\n,2\nc,\n")
+	assert.Equal(t, []FooStructForRecord{{Name: "a", Value: 1.5}, {Name: "c"}}, records)
+	assert.Len(t, errs, 1)
+
+	records, errs = collectRecords(t, MIMECSV, "name,value\na,x\nb,1,extra\nc,3\n")
+	assert.Empty(t, records)
+	if assert.Len(t, errs, 2) {
+		assert.Contains(t, errs[0].Error(), "record 1")
+		assert.Contains(t, errs[1].Error(), "record 2")
+	}
+}
+
+func TestBindEachStop(t *testing.T) {
+	req := requestWithBody("POST", "/", "name\na\nb\nc\n")
+	req.Header.Set("Content-Type", MIMECSV)
+	var names []string
+	for record, err := range BindEach[FooStructForRecord](req) {
+		assert.NoError(t, err)
+		names = append(names, record.Name)
+		if len(names) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestBindEachUnsupported(t *testing.T) {
+	_, errs := collectRecords(t, MIMEJSON, "[]")
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &ContentTypeError{}, errs[0])
+	}
+}
+
+func TestBindEachMaxBodySize(t *testing.T) {
+	defer withOptions(Options{MaxBodySize: 16})()
+
+	_, errs := collectRecords(t, MIMENDJSON, "{\"name\":\"a\"}\n{\"name\":\"b\"}\n")
+	var limitErr *LimitError
+	if assert.Len(t, errs, 1) && assert.True(t, errors.As(errs[0], &limitErr)) {
+		assert.Equal(t, LimitBodySize, limitErr.Limit)
+	}
+}
+
+func TestBindEachWith(t *testing.T) {
+	d := NewDecoder(Options{MaxBodySize: 16})
+	req, _ := http.NewRequest("POST", "/", io.MultiReader(strings.NewReader("name\na\nb\nc\nd\ne\nf\n")))
+	req.Header.Set("Content-Type", MIMECSV)
+	var errs []error
+	for _, err := range BindEachWith[FooStructForRecord](d, req) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var limitErr *LimitError
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.As(errs[0], &limitErr))
+	}
+}
@@ -5,9 +5,16 @@
 package binding
 
 import (
+	"bytes"
+	"context"
 	"net/http"
+	"reflect"
+	"runtime/pprof"
+	"time"
 )
 
+// Content types of the request bodies bound by Default, see Supports and
+// RegisterBinding, and of the responses of the package.
 const (
 	MIMEJSON              = "application/json"
 	MIMEHTML              = "text/html"
@@ -70,6 +77,9 @@ func Default(method, contentType string) Binding {
 	if method == "GET" {
 		return Form
 	}
+	if b, ok := registeredBinding(contentType); ok {
+		return b
+	}
 
 	switch contentType {
 	case MIMEJSON:
@@ -85,6 +95,119 @@ func Default(method, contentType string) Binding {
 	}
 }
 
+// stateBinding is implemented by the bindings which thread a bindState
+// through the binding.
+type stateBinding interface {
+	bind(*http.Request, interface{}, *bindState) error
+}
+
+// bindState carries the per-call state of a binding.
+type bindState struct {
+	opts *Options
+
+	collectWarnings bool
+	warnings        Warnings
+
+	// digest hashes the body when it is verified, see watchDigest.
+	digest *bodyDigest
+	// perms guards fields against mass assignment, see BindWithPermissions.
+	perms *permCheck
+	// keyOrder numbers the form keys in request order, see trackKeyOrder.
+	keyOrder map[string]int
+	// files are the files of a multipart form, by key.
+	files map[string][]*UploadedFile
+	// req is the request fields tagged with in are resolved from, see
+	// BindRequest, and query its parsed query.
+	req   *http.Request
+	query map[string][]string
+	// fromQuery is set by the Query binding, whose keys are read from query
+	// tags first, see keyTag.
+	fromQuery bool
+}
+
+func newBindState() *bindState {
+	return &bindState{opts: &DefaultOptions}
+}
+
+// run binds req onto obj with b, first zeroing obj if the ZeroBeforeBind
+// option is set and bounding its body to the MaxBodySize option, under pprof
+// labels if the ProfileLabels option is set. The Strict option is applied to
+// the warnings of the bind. The request is recorded by the Corpus option, if
+// set, and errors wrapped in a *NegotiatedError if the NegotiateErrors option
+// is set. The bind is counted by the Stats option, if set.
+func (s *bindState) run(b stateBinding, req *http.Request, obj interface{}) error {
+	stats := s.opts.Stats
+	if stats == nil {
+		return s.runBinding(b, req, obj)
+	}
+	start := time.Now()
+	body := stats.watch(req)
+	err := s.runBinding(b, req, obj)
+	stats.record(obj, time.Since(start), body, err)
+	return err
+}
+
+func (s *bindState) runBinding(b stateBinding, req *http.Request, obj interface{}) error {
+	s.zero(obj)
+	if err := s.limitBody(req); err != nil {
+		return s.opts.negotiate(req, err)
+	}
+	corpus := s.opts.Corpus
+	var body *bytes.Buffer
+	if corpus != nil {
+		body = corpus.watch(req)
+	}
+	var err error
+	if s.opts.ProfileLabels {
+		pprof.Do(req.Context(), profileLabels(b, req, obj), func(context.Context) {
+			err = b.bind(req, obj, s)
+		})
+	} else {
+		err = b.bind(req, obj, s)
+	}
+	err = s.checkStrict(req, err)
+	if corpus != nil {
+		corpus.record(req, obj, body, err)
+	}
+	return s.opts.negotiate(req, err)
+}
+
+// zero sets obj, a pointer, to its zero value if the ZeroBeforeBind option is
+// set.
+func (s *bindState) zero(obj interface{}) {
+	if !s.opts.ZeroBeforeBind {
+		return
+	}
+	if val := reflect.ValueOf(obj); val.Kind() == reflect.Ptr && !val.IsNil() {
+		val.Elem().Set(reflect.Zero(val.Elem().Type()))
+	}
+}
+
+func (s *bindState) warn(w Warning) {
+	if s.checking() {
+		s.warnings = append(s.warnings, w)
+	}
+}
+
+// validate resolves the fields of obj tagged with in, filters its guarded
+// fields and computes its derived fields, then validates it and calls its
+// AfterBind method, see Stage.
+func (s *bindState) validate(obj interface{}) error {
+	if err := s.resolveSources(obj); err != nil {
+		return err
+	}
+	if err := s.perms.apply(); err != nil {
+		return err
+	}
+	if err := derive(obj); err != nil {
+		return err
+	}
+	if err := validate(obj); err != nil {
+		return err
+	}
+	return afterBind(obj)
+}
+
 func validate(obj interface{}) error {
 	if Validator == nil {
 		return nil
@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -388,6 +389,35 @@ func TestBindingQueryFail2(t *testing.T) {
 		"map_foo=unused", "")
 }
 
+type FooStructForQueryTag struct {
+	Page   int    `query:"page" form:"page_number"`
+	Sort   string `query:"sort" json:"order"`
+	Secret string `query:"-" form:"secret"`
+	Name   string `form:"name"`
+}
+
+func TestBindingQueryTag(t *testing.T) {
+	req := requestWithBody("POST", "/?page=2&sort=asc&secret=x&name=q&page_number=9", "page_number=3&order=desc&secret=y&name=b")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+
+	var obj FooStructForQueryTag
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, FooStructForQueryTag{Page: 2, Sort: "asc", Name: "q"}, obj)
+
+	obj = FooStructForQueryTag{}
+	assert.NoError(t, FormPost.Bind(req, &obj))
+	assert.Equal(t, FooStructForQueryTag{Page: 3, Sort: "desc", Secret: "y", Name: "b"}, obj)
+
+	obj = FooStructForQueryTag{}
+	assert.NoError(t, MapForm(&obj, url.Values{"page": {"4"}, "page_number": {"5"}}, "query"))
+	assert.Equal(t, 4, obj.Page)
+	obj = FooStructForQueryTag{}
+	assert.NoError(t, MapForm(&obj, url.Values{"page": {"4"}, "page_number": {"5"}}, "form"))
+	assert.Equal(t, 5, obj.Page)
+
+	assert.NotPanics(t, MustValidateStruct[FooStructForQueryTag])
+}
+
 func TestBindingXML(t *testing.T) {
 	testBodyBinding(t,
 		XML, "xml",
@@ -642,7 +672,8 @@ func testFormBindingForTimeNotFormat(t *testing.T, method, path, badPath, body,
 		req.Header.Add("Content-Type", MIMEPOSTForm)
 	}
 	err := b.Bind(req, &obj)
-	assert.Error(t, err)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 11, 15, 0, 0, 0, 0, time.Local), obj.TimeFoo)
 
 	obj = FooStructForTimeTypeNotFormat{}
 	req = requestWithBody(method, badPath, badBody)
@@ -878,7 +909,8 @@ func testFormBindingForType(t *testing.T, method, path, badPath, body, badBody s
 	case "Slice":
 		obj := FooStructForSliceType{}
 		err := b.Bind(req, &obj)
-		assert.Error(t, err)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, obj.SliceFoo)
 
 		obj = FooStructForSliceType{}
 		req = requestWithBody(method, badPath, badBody)
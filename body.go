@@ -0,0 +1,49 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrEmptyBody is returned by the JSON, XML and MsgPack bindings for requests
+// without a body, unless the EmptyBodyIgnore policy is in use. Form bindings
+// treat an empty body as an empty form, and ProtoBuf as a message with all
+// fields unset, since both are valid encodings.
+var ErrEmptyBody = errors.New("binding: empty request body")
+
+// EmptyBodyPolicy selects how body bindings handle requests without a body.
+type EmptyBodyPolicy int
+
+const (
+	// EmptyBodyError fails the bind with ErrEmptyBody.
+	EmptyBodyError EmptyBodyPolicy = iota
+	// EmptyBodyIgnore skips decoding, leaving obj as is. obj is still
+	// validated.
+	EmptyBodyIgnore
+)
+
+// openBody returns a buffered reader for the body of req, and whether the
+// body is empty.
+func openBody(req *http.Request) (body *bufio.Reader, empty bool) {
+	if req.Body == nil {
+		return bufio.NewReader(strings.NewReader("")), true
+	}
+	body = bufio.NewReader(req.Body)
+	_, err := body.Peek(1)
+	return body, err == io.EOF
+}
+
+// bindEmptyBody applies the empty body policy.
+func (s *bindState) bindEmptyBody(obj interface{}) error {
+	if s.opts.EmptyBody == EmptyBodyIgnore {
+		return s.validate(obj)
+	}
+	return ErrEmptyBody
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForEmptyBody struct {
+	Foo string `json:"foo" xml:"foo" msgpack:"foo"`
+}
+
+func TestEmptyBodyPolicy(t *testing.T) {
+	defer withOptions(Options{})()
+
+	for _, b := range []Binding{JSON, XML, MsgPack} {
+		obj := FooStructForEmptyBody{Foo: "default"}
+		req := requestWithBody("POST", "/", "")
+		assert.Equal(t, ErrEmptyBody, b.Bind(req, &obj), b.Name())
+
+		req, _ = http.NewRequest("POST", "/", nil)
+		assert.Equal(t, ErrEmptyBody, b.Bind(req, &obj), b.Name())
+	}
+
+	DefaultOptions.EmptyBody = EmptyBodyIgnore
+	for _, b := range []Binding{JSON, XML, MsgPack} {
+		obj := FooStructForEmptyBody{Foo: "default"}
+		req := requestWithBody("POST", "/", "")
+		assert.NoError(t, b.Bind(req, &obj), b.Name())
+		assert.Equal(t, "default", obj.Foo)
+
+		// validation still runs
+		req = requestWithBody("POST", "/", "")
+		assert.Error(t, b.Bind(req, &FooStruct{}), b.Name())
+	}
+}
+
+func TestEmptyBodyNotEmpty(t *testing.T) {
+	obj := FooStructForEmptyBody{}
+	req := requestWithBody("POST", "/", " ")
+	err := JSON.Bind(req, &obj)
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrEmptyBody, err)
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16BE = []byte{0xfe, 0xff}
+	bomUTF16LE = []byte{0xff, 0xfe}
+)
+
+// skipBOM returns a reader for br with its leading byte order mark removed,
+// if any. Bodies starting with a UTF-16 BOM are transcoded to UTF-8, in which
+// case transcoded is true.
+func skipBOM(br *bufio.Reader) (r io.Reader, transcoded bool) {
+	// a short body is fine, Peek returns what there is
+	prefix, _ := br.Peek(len(bomUTF8))
+	switch {
+	case bytes.HasPrefix(prefix, bomUTF8):
+		br.Discard(len(bomUTF8))
+		return br, false
+	case bytes.HasPrefix(prefix, bomUTF16BE):
+		return transform.NewReader(br, unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()), true
+	case bytes.HasPrefix(prefix, bomUTF16LE):
+		return transform.NewReader(br, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()), true
+	}
+	return br, false
+}
+
+// utf16CharsetReader lets the XML decoder accept an UTF-16 encoding
+// declaration on a body skipBOM already transcoded to UTF-8.
+func utf16CharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	if strings.HasPrefix(strings.ToLower(charset), "utf-16") {
+		return input, nil
+	}
+	return nil, fmt.Errorf("xml: unsupported charset %q", charset)
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeUTF16(s string, bigEndian bool) string {
+	b := []byte{0xff, 0xfe}
+	if bigEndian {
+		b = []byte{0xfe, 0xff}
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return string(b)
+}
+
+func TestBindingJSONWithBOM(t *testing.T) {
+	for _, body := range []string{
+		"\xef\xbb\xbf" + `{"foo": "bär"}`,
+		encodeUTF16(`{"foo": "bär"}`, true),
+		encodeUTF16(`{"foo": "bär"}`, false),
+	} {
+		obj := FooStruct{}
+		req := requestWithBody("POST", "/", body)
+		assert.NoError(t, JSON.Bind(req, &obj))
+		assert.Equal(t, "bär", obj.Foo)
+	}
+}
+
+func TestBindingXMLWithBOM(t *testing.T) {
+	for _, body := range []string{
+		"\xef\xbb\xbf<?xml version=\"1.0\" encoding=\"UTF-8\"?><root><foo>bär</foo></root>",
+		encodeUTF16("<?xml version=\"1.0\" encoding=\"UTF-16\"?><root><foo>bär</foo></root>", true),
+		encodeUTF16("<root><foo>bär</foo></root>", false),
+	} {
+		obj := FooStruct{}
+		req := requestWithBody("POST", "/", body)
+		assert.NoError(t, XML.Bind(req, &obj))
+		assert.Equal(t, "bär", obj.Foo)
+	}
+}
+
+func TestSkipBOMShortBody(t *testing.T) {
+	obj := FooStruct{}
+	req := requestWithBody("POST", "/", "{")
+	assert.Error(t, JSON.Bind(req, &obj))
+}
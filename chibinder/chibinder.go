@@ -0,0 +1,29 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package chibinder makes github.com/go-chi/render decode requests with the
+// binding package:
+//
+//	chibinder.Install(binding.DefaultOptions)
+package chibinder
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/ppltools/binding"
+)
+
+// Install sets render.Decode to a decoder binding requests with a
+// binding.Decoder using opts.
+func Install(opts binding.Options) {
+	render.Decode = Decoder(opts)
+}
+
+// Decoder returns a function suitable for render.Decode binding requests with
+// a binding.Decoder using opts.
+func Decoder(opts binding.Options) func(*http.Request, interface{}) error {
+	d := binding.NewDecoder(opts)
+	return d.Bind
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package chibinder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/ppltools/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name string `json:"name" form:"name"`
+	Age  int    `json:"age" form:"age" min:"0"`
+}
+
+func (*user) Bind(r *http.Request) error {
+	return nil
+}
+
+func TestInstall(t *testing.T) {
+	decode := render.Decode
+	defer func() { render.Decode = decode }()
+	Install(binding.DefaultOptions)
+
+	var obj user
+	req := httptest.NewRequest("POST", "/?name=manu&age=30", nil)
+	req.Header.Set("Content-Type", binding.MIMEPOSTForm)
+	assert.NoError(t, render.Bind(req, &obj))
+	assert.Equal(t, user{Name: "manu", Age: 30}, obj)
+
+	req = httptest.NewRequest("GET", "/?age=-1", nil)
+	assert.Error(t, render.Bind(req, &obj))
+}
@@ -0,0 +1,238 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Command bindsim binds sample payloads onto a struct type with the binding
+// package and prints the resulting struct, the warnings and the errors, to
+// iterate on struct tags without writing a test harness:
+//
+//	bindsim [-method POST] [-content-type type] [-query q] import/path.Type payload...
+//
+// The content type of a payload defaults from its extension: .json, .xml,
+// .form for URL-encoded forms and .msgpack, while .http files hold whole
+// HTTP/1.1 requests, headers included. The tags of the type are checked
+// first, as binding.MustValidateStruct does.
+//
+// The payloads are bound out of process, by a program generated in a
+// temporary directory of the current directory and run with go run, so the
+// current module has to be able to import the package of the type. bindsim
+// exits with status 1 if a payload fails to bind.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/ppltools/binding"
+)
+
+// program is the configuration of the generated program.
+type program struct {
+	Package, Type string
+	Method        string
+	ContentType   string
+	Query         string
+	Payloads      []payload
+}
+
+// payload is a payload file, sent with content type ContentType, or read as
+// a whole request if empty.
+type payload struct {
+	Path, ContentType string
+}
+
+func main() {
+	var p program
+	flag.StringVar(&p.Method, "method", "POST", "method of the requests")
+	flag.StringVar(&p.ContentType, "content-type", "", "content type of the payloads, from their extension by default")
+	flag.StringVar(&p.Query, "query", "", "query string of the requests")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: bindsim [flags] import/path.Type payload...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var err error
+	if p.Package, p.Type, err = splitType(flag.Arg(0)); err != nil {
+		fatal(err)
+	}
+	for _, arg := range flag.Args()[1:] {
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			fatal(err)
+		}
+		ct, err := contentType(path, p.ContentType)
+		if err != nil {
+			fatal(err)
+		}
+		p.Payloads = append(p.Payloads, payload{path, ct})
+	}
+	os.Exit(run(p))
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "bindsim:", err)
+	os.Exit(2)
+}
+
+// splitType splits the qualified type name arg into the import path of its
+// package and its name.
+func splitType(arg string) (pkg, name string, err error) {
+	i := strings.LastIndex(arg, ".")
+	if i <= strings.LastIndex(arg, "/") || i == len(arg)-1 {
+		return "", "", fmt.Errorf("%q is not a qualified type name such as example.com/api.Order", arg)
+	}
+	return arg[:i], arg[i+1:], nil
+}
+
+// contentTypes are the content types of payloads by extension.
+var contentTypes = map[string]string{
+	".json":    binding.MIMEJSON,
+	".xml":     binding.MIMEXML,
+	".form":    binding.MIMEPOSTForm,
+	".msgpack": binding.MIMEMSGPACK,
+}
+
+// contentType returns the content type the payload file path is sent with,
+// override if set, empty for .http files.
+func contentType(path, override string) (string, error) {
+	ext := filepath.Ext(path)
+	if override != "" && ext != ".http" {
+		return override, nil
+	}
+	if ct, ok := contentTypes[ext]; ok || ext == ".http" {
+		return ct, nil
+	}
+	return "", fmt.Errorf("unknown content type of %s, use -content-type", path)
+}
+
+// run generates the program binding the payloads of p and runs it, returning
+// its exit status.
+func run(p program) int {
+	src, err := p.source()
+	if err != nil {
+		fatal(err)
+	}
+	dir, err := os.MkdirTemp(".", "_bindsim")
+	if err != nil {
+		fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		fatal(err)
+	}
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return exit.ExitCode()
+		}
+		fatal(err)
+	}
+	return 0
+}
+
+// source returns the source of the program binding the payloads of p.
+func (p *program) source() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := programTemplate.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+var programTemplate = template.Must(template.New("main.go").Funcs(template.FuncMap{
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+}).Parse(`// Code generated by bindsim. DO NOT EDIT.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/ppltools/binding"
+	target {{quote .Package}}
+)
+
+type payload struct {
+	path, contentType string
+}
+
+func main() {
+	if err := checkTags(); err != nil {
+		fmt.Println("tags:", err)
+		os.Exit(1)
+	}
+	status := 0
+	for _, p := range []payload{
+		{{- range .Payloads}}
+		{ {{- quote .Path}}, {{quote .ContentType}} },
+		{{- end}}
+	} {
+		fmt.Printf("== %s\n", p.path)
+		if !bind(p) {
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+func checkTags() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	binding.MustValidateStruct[target.{{.Type}}]()
+	return nil
+}
+
+func bind(p payload) bool {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		fmt.Println("error:", err)
+		return false
+	}
+	var req *http.Request
+	if p.contentType == "" {
+		if req, err = http.ReadRequest(bufio.NewReader(bytes.NewReader(data))); err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+	} else {
+		req = httptest.NewRequest({{quote .Method}}, "/?"+{{quote .Query}}, bytes.NewReader(data))
+		req.Header.Set("Content-Type", p.contentType)
+	}
+
+	var obj target.{{.Type}}
+	warnings, err := binding.BindWithWarnings(req, &obj, binding.Default(req.Method, req.Header.Get("Content-Type")))
+	out, _ := json.MarshalIndent(obj, "", "  ")
+	fmt.Println(string(out))
+	for _, w := range warnings {
+		fmt.Println("warning:", w)
+	}
+	if err != nil {
+		fmt.Println("error:", err)
+		return false
+	}
+	return true
+}
+`))
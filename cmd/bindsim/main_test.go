@@ -0,0 +1,61 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/ppltools/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitType(t *testing.T) {
+	pkg, name, err := splitType("example.com/shop/api.Order")
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com/shop/api", pkg)
+	assert.Equal(t, "Order", name)
+
+	for _, arg := range []string{"Order", "example.com/api", "example.com/api."} {
+		_, _, err = splitType(arg)
+		assert.Error(t, err, arg)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	for _, tt := range []struct {
+		path, override, want string
+	}{
+		{"order.json", "", binding.MIMEJSON},
+		{"order.form", "", binding.MIMEPOSTForm},
+		{"order.http", "", ""},
+		{"order.http", binding.MIMEJSON, ""},
+		{"order.txt", binding.MIMEXML, binding.MIMEXML},
+	} {
+		ct, err := contentType(tt.path, tt.override)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, ct, tt.path)
+	}
+	_, err := contentType("order.txt", "")
+	assert.EqualError(t, err, "unknown content type of order.txt, use -content-type")
+}
+
+func TestSource(t *testing.T) {
+	p := program{
+		Package:  "example.com/shop/api",
+		Type:     "Order",
+		Method:   "POST",
+		Query:    "dry_run=1",
+		Payloads: []payload{{"/tmp/order.json", binding.MIMEJSON}, {"/tmp/order.http", ""}},
+	}
+	src, err := p.source()
+	assert.NoError(t, err)
+	f, err := parser.ParseFile(token.NewFileSet(), "main.go", src, parser.ImportsOnly)
+	assert.NoError(t, err)
+	assert.Len(t, f.Imports, 9)
+	assert.Contains(t, string(src), `{"/tmp/order.json", "application/json"},`)
+	assert.Contains(t, string(src), "binding.MustValidateStruct[target.Order]()")
+}
@@ -0,0 +1,272 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind classifies a BreakingChange.
+type ChangeKind string
+
+// The kinds of breaking changes reported by CheckCompatibility.
+const (
+	// ChangeRemoved is a key no longer bound.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeType is a key bound into a different type, other than a wider
+	// number of the same kind.
+	ChangeType ChangeKind = "type"
+	// ChangeFormat is a changed time format.
+	ChangeFormat ChangeKind = "format"
+	// ChangePattern is a new or changed pattern.
+	ChangePattern ChangeKind = "pattern"
+	// ChangeRange is a raised min or lowered max.
+	ChangeRange ChangeKind = "range"
+	// ChangeRequired is a key newly required.
+	ChangeRequired ChangeKind = "required"
+	// ChangeDefault is a removed or changed default.
+	ChangeDefault ChangeKind = "default"
+	// ChangePermission is a key newly guarded by permissions.
+	ChangePermission ChangeKind = "permission"
+)
+
+// BreakingChange is a change of the binding of a key which may reject, or
+// bind differently, requests accepted by the previous version of a struct.
+type BreakingChange struct {
+	// Key is the key, prefixed with "header:" for header keys.
+	Key  string
+	Kind ChangeKind
+	// Old and New describe the binding before and after the change.
+	Old, New string
+}
+
+func (c BreakingChange) String() string {
+	return fmt.Sprintf("%s: %s changed from %q to %q", c.Key, c.Kind, c.Old, c.New)
+}
+
+// CheckCompatibility reports the breaking changes of the bindings of New
+// compared to Old, two versions of a request struct, sorted by key. It is
+// meant to gate API changes in CI:
+//
+//	changes, err := binding.CheckCompatibility[v1.CreateOrder, v2.CreateOrder]()
+//
+// Keys still bound through an alias aren't removed.
+func CheckCompatibility[Old, New any]() ([]BreakingChange, error) {
+	oldKeys, err := bindingKeys(reflect.TypeOf((*Old)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	newKeys, err := bindingKeys(reflect.TypeOf((*New)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	var changes []BreakingChange
+	for key, old := range oldKeys {
+		cur, ok := newKeys[key]
+		if !ok {
+			changes = append(changes, BreakingChange{Key: key, Kind: ChangeRemoved, Old: old.typ.String()})
+			continue
+		}
+		changes = append(changes, compareKeys(key, old, cur)...)
+	}
+	for key, cur := range newKeys {
+		if _, ok := oldKeys[key]; !ok && cur.required && !cur.alias {
+			changes = append(changes, BreakingChange{Key: key, Kind: ChangeRequired, Old: "absent", New: "required"})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Key != changes[j].Key {
+			return changes[i].Key < changes[j].Key
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes, nil
+}
+
+// keyBinding describes how a key is bound, for CheckCompatibility.
+type keyBinding struct {
+	field    *fieldInfo
+	typ      reflect.Type
+	required bool
+	// alias is set for the deprecated alias of a field.
+	alias bool
+}
+
+// bindingKeys returns the bindings of the keys of the struct typ.
+func bindingKeys(typ reflect.Type) (map[string]*keyBinding, error) {
+	keys := make(map[string]*keyBinding)
+	for _, tag := range []string{"", "header"} {
+		prefix := ""
+		if tag != "" {
+			prefix = tag + ":"
+		}
+		if err := collectBindingKeys(typ, tag, prefix, keys); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func collectBindingKeys(typ reflect.Type, tag, prefix string, keys map[string]*keyBinding) error {
+	info, err := cachedStructInfo(typ, tag)
+	if err != nil {
+		return err
+	}
+	for _, field := range info.fields {
+		typeField := typ.Field(field.index)
+		// the keys of recursive structs are endless, so they're left out
+		if field.recursive {
+			continue
+		}
+		if field.nested {
+			if err := collectBindingKeys(nestedType(typeField.Type), tag, prefix+field.prefix, keys); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.sourceOnly {
+			continue
+		}
+		kb := &keyBinding{
+			field:    field,
+			typ:      typeField.Type,
+			required: hasRule(typeField.Tag.Get("binding"), "required"),
+		}
+		keys[prefix+field.key] = kb
+		if field.alias != "" {
+			alias := *kb
+			alias.alias = true
+			keys[prefix+field.alias] = &alias
+		}
+	}
+	return nil
+}
+
+// hasRule reports whether the validation tag lists rule.
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func compareKeys(key string, old, cur *keyBinding) []BreakingChange {
+	var changes []BreakingChange
+	add := func(kind ChangeKind, o, n string) {
+		changes = append(changes, BreakingChange{Key: key, Kind: kind, Old: o, New: n})
+	}
+	if !compatibleTypes(old.typ, cur.typ) {
+		add(ChangeType, old.typ.String(), cur.typ.String())
+	}
+	of, nf := old.field, cur.field
+	if of.timeFormat != nf.timeFormat {
+		add(ChangeFormat, of.timeFormat, nf.timeFormat)
+	}
+	if of.separator != nf.separator {
+		add(ChangeFormat, collectionFormat(of.separator), collectionFormat(nf.separator))
+	}
+	if op, np := patternString(of), patternString(nf); op != np && np != "" {
+		add(ChangePattern, op, np)
+	}
+	if tightened(of.min, nf.min, 1) {
+		add(ChangeRange, "min "+boundString(of.min), "min "+boundString(nf.min))
+	}
+	if tightened(of.max, nf.max, -1) {
+		add(ChangeRange, "max "+boundString(of.max), "max "+boundString(nf.max))
+	}
+	if cur.required && !old.required {
+		add(ChangeRequired, "optional", "required")
+	}
+	if of.defaultValue != "" && of.defaultValue != nf.defaultValue {
+		add(ChangeDefault, of.defaultValue, nf.defaultValue)
+	}
+	if len(nf.perms) > 0 && strings.Join(of.perms, ",") != strings.Join(nf.perms, ",") {
+		add(ChangePermission, strings.Join(of.perms, ","), strings.Join(nf.perms, ","))
+	}
+	return changes
+}
+
+// compatibleTypes reports whether values bound into old are bound into cur
+// alike: same types, pointers of them, or wider numbers of the same kind.
+func compatibleTypes(old, cur reflect.Type) bool {
+	if old.Kind() == reflect.Ptr {
+		old = old.Elem()
+	}
+	if cur.Kind() == reflect.Ptr {
+		cur = cur.Elem()
+	}
+	if old == cur {
+		return true
+	}
+	if numberClass(old) == "" || numberClass(old) != numberClass(cur) {
+		return false
+	}
+	return old.Bits() <= cur.Bits()
+}
+
+// numberClass returns the class of the numeric type typ, empty for others.
+func numberClass(typ reflect.Type) string {
+	if typ.PkgPath() != "" {
+		// named types may convert differently
+		return ""
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	}
+	return ""
+}
+
+func patternString(f *fieldInfo) string {
+	if f.pattern == nil {
+		return ""
+	}
+	return f.pattern.String()
+}
+
+func boundString(b *numBound) string {
+	if b == nil {
+		return "none"
+	}
+	return b.raw
+}
+
+// tightened reports whether the bound cur is tighter than old, dir being 1 for
+// min bounds and -1 for max bounds.
+func tightened(old, cur *numBound, dir float64) bool {
+	if cur == nil {
+		return false
+	}
+	if old == nil {
+		return true
+	}
+	o, err1 := strconv.ParseFloat(old.raw, 64)
+	c, err2 := strconv.ParseFloat(cur.raw, 64)
+	if err1 != nil || err2 != nil {
+		return old.raw != cur.raw
+	}
+	return (c-o)*dir > 0
+}
+
+// collectionFormat returns the name of the collection_format splitting values
+// by sep.
+func collectionFormat(sep string) string {
+	for name, s := range collectionFormats {
+		if s == sep {
+			return name
+		}
+	}
+	return sep
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForCompatV1 struct {
+	Name     string    `form:"name"`
+	Count    int32     `form:"count" max:"100"`
+	Price    float32   `form:"price" min:"0"`
+	Since    time.Time `form:"since" time_format:"2006-01-02"`
+	Code     string    `form:"code"`
+	Page     int       `form:"page" default:"1"`
+	Kind     string    `form:"kind"`
+	Dropped  bool      `form:"dropped"`
+	Renamed  string    `form:"old_name"`
+	TraceID  string    `header:"X-Trace-Id" form:"-"`
+	Optional string    `form:"optional"`
+	IDs      []int     `form:"ids"`
+}
+
+type FooStructForCompatV2 struct {
+	Name     *string   `form:"name"`
+	Count    int64     `form:"count" max:"50"`
+	Price    float64   `form:"price" min:"1"`
+	Since    time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Code     string    `form:"code" pattern:"^[A-Z]+$"`
+	Page     int       `form:"page"`
+	Kind     int       `form:"kind"`
+	Renamed  string    `form:"new_name" alias:"old_name"`
+	TraceID  int       `header:"x-trace-id" form:"-"`
+	Optional string    `form:"optional" binding:"required"`
+	Added    string    `form:"added" binding:"required"`
+	Extra    string    `form:"extra"`
+	IDs      []int     `form:"ids" collection_format:"csv"`
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	changes, err := CheckCompatibility[FooStructForCompatV1, FooStructForCompatV2]()
+	assert.NoError(t, err)
+	assert.Equal(t, []BreakingChange{
+		{Key: "added", Kind: ChangeRequired, Old: "absent", New: "required"},
+		{Key: "code", Kind: ChangePattern, Old: "", New: "^[A-Z]+$"},
+		{Key: "count", Kind: ChangeRange, Old: "max 100", New: "max 50"},
+		{Key: "dropped", Kind: ChangeRemoved, Old: "bool"},
+		{Key: "header:X-Trace-Id", Kind: ChangeType, Old: "string", New: "int"},
+		{Key: "ids", Kind: ChangeFormat, Old: "multi", New: "csv"},
+		{Key: "kind", Kind: ChangeType, Old: "string", New: "int"},
+		{Key: "optional", Kind: ChangeRequired, Old: "optional", New: "required"},
+		{Key: "page", Kind: ChangeDefault, Old: "1", New: ""},
+		{Key: "price", Kind: ChangeRange, Old: "min 0", New: "min 1"},
+		{Key: "since", Kind: ChangeFormat, Old: "2006-01-02", New: "2006-01-02T15:04:05Z07:00"},
+	}, changes)
+	assert.Equal(t, `kind: type changed from "string" to "int"`, changes[6].String())
+
+	changes, err = CheckCompatibility[FooStructForCompatV1, FooStructForCompatV1]()
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = CheckCompatibility[FooStructForCompatV1, int]()
+	assert.Error(t, err)
+}
+
+func TestCompatibleTypes(t *testing.T) {
+	for _, tc := range []struct {
+		old, cur   interface{}
+		compatible bool
+	}{
+		{int8(0), int64(0), true},
+		{int64(0), int32(0), false},
+		{uint16(0), uint(0), true},
+		{float64(0), float32(0), false},
+		{int(0), uint(0), false},
+		{time.Duration(0), int64(0), false},
+	} {
+		assert.Equal(t, tc.compatible, compatibleTypes(reflect.TypeOf(tc.old), reflect.TypeOf(tc.cur)), "%T -> %T", tc.old, tc.cur)
+	}
+}
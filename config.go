@@ -0,0 +1,153 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Config is a read-only snapshot of the effective configuration of a
+// Decoder, for startup logging and support diagnostics, see Decoder.Config.
+// It marshals to JSON.
+type Config struct {
+	// MaxBodySize, MaxFormValues, MaxSliceIndex and MaxDepth are the limits
+	// applied, defaults included, 0 for none.
+	MaxBodySize   int64 `json:"max_body_size"`
+	MaxFormValues int   `json:"max_form_values"`
+	MaxSliceIndex int   `json:"max_slice_index"`
+	MaxDepth      int   `json:"max_depth"`
+
+	MultipartWorkers int `json:"multipart_workers,omitempty"`
+
+	// Tags are the struct tags the keys of the form bindings are read from,
+	// the first present winning.
+	Tags []string `json:"tags"`
+
+	// Policies are the policies applied, by option name, e.g.
+	// "Normalization": "nfc".
+	Policies map[string]string `json:"policies"`
+	// Enabled lists the other options set, by name.
+	Enabled []string `json:"enabled,omitempty"`
+
+	// Bindings are the names of the bindings used by Bind, by media type.
+	Bindings map[string]string `json:"bindings"`
+	// Converters are the types with a registered converter, see
+	// RegisterConverter.
+	Converters []string `json:"converters,omitempty"`
+	// Steps are the stages of the registered steps, by name, see
+	// RegisterStep.
+	Steps map[string]string `json:"steps,omitempty"`
+	// Implementations are the implementations registered for interface
+	// types, see RegisterImplementations.
+	Implementations map[string][]string `json:"implementations,omitempty"`
+	// Validator is the type of the Validator.
+	Validator string `json:"validator,omitempty"`
+}
+
+// Config returns a snapshot of the configuration of d. The options of
+// requests bound with a Resolve function may differ.
+func (d *Decoder) Config() Config {
+	opts := &d.Options
+	c := Config{
+		MaxBodySize:      opts.MaxBodySize,
+		MaxFormValues:    opts.MaxFormValues,
+		MaxSliceIndex:    opts.maxSliceIndex(),
+		MaxDepth:         opts.maxDepth(),
+		MultipartWorkers: opts.MultipartWorkers,
+		Tags:             []string{"json", "form"},
+		Policies: map[string]string{
+			"Normalization":      policyName([]string{"none", "nfc", "nfkc"}, int(opts.Normalization)),
+			"InvalidUTF8":        policyName([]string{"allow", "reject", "replace", "strip"}, int(opts.InvalidUTF8)),
+			"ControlChars":       policyName([]string{"allow", "reject", "strip"}, int(opts.ControlChars)),
+			"EmptyBody":          policyName([]string{"error", "ignore"}, int(opts.EmptyBody)),
+			"Strict":             policyName([]string{"off", "report", "enforce"}, int(opts.Strict)),
+			"UnknownContentType": policyName([]string{"form", "reject", "json", "sniff"}, int(opts.UnknownContentType)),
+			"FieldNaming":        policyName([]string{"field", "snake", "camel", "kebab"}, int(opts.FieldNaming)),
+		},
+		Bindings: make(map[string]string),
+	}
+	if opts.FormTagFirst {
+		c.Tags = []string{"form", "json"}
+	}
+	if opts.MaxBodySize < 0 {
+		c.MaxBodySize = 0
+	}
+	if opts.MaxFormValues < 0 {
+		c.MaxFormValues = 0
+	}
+	for _, enabled := range []struct {
+		name string
+		set  bool
+	}{
+		{"InternStrings", opts.InternStrings},
+		{"VerifyDigest", opts.VerifyDigest},
+		{"LazyMultipart", opts.LazyMultipart},
+		{"InspectFile", opts.InspectFile != nil},
+		{"StoreFile", opts.StoreFile != nil},
+		{"ProfileLabels", opts.ProfileLabels},
+		{"CollectFieldErrors", opts.CollectFieldErrors},
+		{"HTMLBools", opts.HTMLBools},
+		{"CaseInsensitiveKeys", opts.CaseInsensitiveKeys},
+		{"FormTagFirst", opts.FormTagFirst},
+		{"NoJSONValues", opts.NoJSONValues},
+		{"WindowsTimeZones", opts.WindowsTimeZones},
+		{"ReportStrict", opts.ReportStrict != nil},
+		{"CheckHeaders", opts.CheckHeaders},
+		{"Corpus", opts.Corpus != nil},
+		{"Stats", opts.Stats != nil},
+		{"NegotiateErrors", opts.NegotiateErrors},
+		{"ZeroBeforeBind", opts.ZeroBeforeBind},
+		{"Resolve", d.Resolve != nil},
+	} {
+		if enabled.set {
+			c.Enabled = append(c.Enabled, enabled.name)
+		}
+	}
+	for _, mediaType := range SupportedMediaTypes() {
+		c.Bindings[mediaType] = Default("POST", mediaType).Name()
+	}
+	converters.Range(func(key, _ interface{}) bool {
+		c.Converters = append(c.Converters, key.(reflect.Type).String())
+		return true
+	})
+	sort.Strings(c.Converters)
+	steps.Range(func(key, value interface{}) bool {
+		if c.Steps == nil {
+			c.Steps = make(map[string]string)
+		}
+		c.Steps[key.(string)] = value.(Step).Stage.String()
+		return true
+	})
+	implementations.Range(func(key, value interface{}) bool {
+		if c.Implementations == nil {
+			c.Implementations = make(map[string][]string)
+		}
+		set := value.(*implSet)
+		names := make([]string, 0, len(set.types))
+		if set.key != "" {
+			names = sortedKeys(set.names)
+		} else {
+			for _, typ := range set.types {
+				names = append(names, typ.String())
+			}
+		}
+		c.Implementations[key.(reflect.Type).String()] = names
+		return true
+	})
+	if Validator != nil {
+		c.Validator = fmt.Sprintf("%T", Validator)
+	}
+	return c
+}
+
+// policyName returns the name of the policy v among names.
+func policyName(names []string, v int) string {
+	if v < 0 || v >= len(names) {
+		return fmt.Sprint(v)
+	}
+	return names[v]
+}
@@ -0,0 +1,44 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderConfig(t *testing.T) {
+	d := NewDecoder(Options{
+		MaxBodySize:   1 << 20,
+		MaxDepth:      8,
+		Normalization: NormalizeNFC,
+		Strict:        StrictEnforce,
+		InternStrings: true,
+	})
+	d.Resolve = func(*http.Request) (*Options, error) { return nil, nil }
+
+	c := d.Config()
+	assert.Equal(t, int64(1<<20), c.MaxBodySize)
+	assert.Equal(t, 0, c.MaxFormValues)
+	assert.Equal(t, defaultMaxSliceIndex, c.MaxSliceIndex)
+	assert.Equal(t, 8, c.MaxDepth)
+	assert.Equal(t, []string{"json", "form"}, c.Tags)
+	assert.Equal(t, "nfc", c.Policies["Normalization"])
+	assert.Equal(t, "enforce", c.Policies["Strict"])
+	assert.Equal(t, "form", c.Policies["UnknownContentType"])
+	assert.Equal(t, []string{"InternStrings", "Resolve"}, c.Enabled)
+	assert.Equal(t, "json", c.Bindings[MIMEJSON])
+	assert.Equal(t, "form", c.Bindings[MIMEMultipartPOSTForm])
+	assert.Contains(t, c.Converters, "binding.testPoint")
+	assert.Equal(t, []string{"binding.testCircle", "*binding.testSquare", "binding.testTiles"}, c.Implementations["binding.testShape"])
+	assert.Equal(t, []string{"card", "transfer"}, c.Implementations["binding.testPayment"])
+	assert.Equal(t, "*binding.defaultValidator", c.Validator)
+
+	_, err := json.Marshal(c)
+	assert.NoError(t, err)
+}
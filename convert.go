@@ -0,0 +1,292 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// converters holds the registered converters, see RegisterConverter.
+var converters sync.Map // map[reflect.Type]func(string) (reflect.Value, error)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Unmarshaler is implemented by types taking control of their own binding
+// from form values, queries and headers, like json.Unmarshaler does for JSON.
+// UnmarshalForm receives all the values of the key of the field, e.g.
+// ["a", "b"] for tag=a&tag=b, after the clean and modify stages of the
+// pipeline. It is called with the single value of defaults, of the elements of
+// slices and maps, and of the scalars of MapAny. Unmarshaler takes precedence
+// over encoding.TextUnmarshaler.
+type Unmarshaler interface {
+	UnmarshalForm(values []string) error
+}
+
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+// isUnmarshaler reports whether typ, or its element if a pointer, implements
+// Unmarshaler.
+func isUnmarshaler(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return reflect.PtrTo(typ).Implements(unmarshalerType)
+}
+
+// unmarshalForm sets value, whose type is an Unmarshaler, from values,
+// allocating it if it is a nil pointer.
+func unmarshalForm(values []string, value reflect.Value) error {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	return value.Addr().Interface().(Unmarshaler).UnmarshalForm(values)
+}
+
+// RegisterConverter registers fn to convert form values into values of type
+// T, e.g.
+//
+//	binding.RegisterConverter(uuid.Parse)
+//
+// Converters apply to fields of type T and *T as well as to the elements of
+// slice and map fields, before any other conversion. Types implementing
+// encoding.TextUnmarshaler need no converter. Converters are meant to be
+// registered at init time.
+func RegisterConverter[T any](fn func(string) (T, error)) {
+	converters.Store(reflect.TypeOf((*T)(nil)).Elem(), func(val string) (reflect.Value, error) {
+		v, err := fn(val)
+		return reflect.ValueOf(&v).Elem(), err
+	})
+}
+
+// ConvertString converts value to a value of type t with the rules form
+// values are bound to fields of type t with, registered converters included,
+// so that they can be reused outside of struct binding, e.g.
+//
+//	v, err := binding.ConvertString("42", reflect.TypeOf(0))
+func ConvertString(value string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if err := setValue(value, t, v, &fieldInfo{timeLocation: time.Local}); err != nil {
+		return reflect.Value{}, err
+	}
+	return v, nil
+}
+
+// convertible reports whether values of typ are converted by a converter,
+// Unmarshaler or encoding.TextUnmarshaler, kept raw by Lazy, or sql.Null* or
+// time zone values. Pointers are convertible if their element is, as setValue
+// allocates it.
+func convertible(typ reflect.Type) bool {
+	if isSQLNull(typ) || typ == locationType.Elem() {
+		return true
+	}
+	if _, ok := converters.Load(typ); ok {
+		return true
+	}
+	if typ.Kind() == reflect.Ptr {
+		return convertible(typ.Elem())
+	}
+	ptr := reflect.PtrTo(typ)
+	return ptr.Implements(unmarshalerType) || ptr.Implements(textUnmarshalerType) || ptr.Implements(lazyValueType)
+}
+
+// convertValue sets value from val with the converter registered for its
+// type, its UnmarshalForm or UnmarshalText method, or keeps val raw for a
+// Lazy value, reporting whether it did any. All take precedence over the kind
+// of value, so that e.g. a named int implementing encoding.TextUnmarshaler is
+// bound from its text rather than parsed as a number.
+func convertValue(val string, value reflect.Value, field *fieldInfo) (bool, error) {
+	if value.Kind() != reflect.Ptr && value.CanAddr() {
+		if l, ok := value.Addr().Interface().(lazyValue); ok {
+			l.setRaw(val, field)
+			return true, nil
+		}
+	}
+	if fn, ok := converters.Load(value.Type()); ok {
+		v, err := fn.(func(string) (reflect.Value, error))(val)
+		if err != nil {
+			return true, err
+		}
+		value.Set(v)
+		return true, nil
+	}
+	if value.Kind() != reflect.Ptr && value.CanAddr() {
+		if u, ok := value.Addr().Interface().(Unmarshaler); ok {
+			return true, u.UnmarshalForm([]string{val})
+		}
+		if u, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return true, u.UnmarshalText([]byte(val))
+		}
+	}
+	return false, nil
+}
+
+// repeatable reports whether typ is the element type of slices bound from
+// the repeated values of a key, e.g. tag=a&tag=b, one element per value:
+// strings, bools and numbers, pointers to them, and convertible types.
+func repeatable(typ reflect.Type) bool {
+	if convertible(typ) {
+		return true
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// jsonArray reports whether values, the values of a key bound into a slice
+// of typ, are a single JSON array, as slices of strings, bools and numbers
+// were bound before they could be repeated. Other values starting with a
+// bracket, e.g. tag=[draft], are bound as elements.
+func jsonArray(values []string, typ reflect.Type) bool {
+	return len(values) == 1 && !convertible(typ.Elem()) && strings.HasPrefix(strings.TrimSpace(values[0]), "[") && json.Valid([]byte(values[0]))
+}
+
+// timeArray returns the strings of values, the values of a key bound into a
+// slice of times, if they are a single JSON array of strings, so that each of
+// them is parsed with the time_format of the field.
+func timeArray(values []string, typ reflect.Type) ([]string, bool) {
+	if len(values) != 1 || nestedType(typ.Elem()) != timeType || !strings.HasPrefix(strings.TrimSpace(values[0]), "[") {
+		return nil, false
+	}
+	var times []string
+	if json.Unmarshal([]byte(values[0]), &times) != nil {
+		return nil, false
+	}
+	return times, true
+}
+
+// collectionFormats are the separators of the values of slice fields by
+// collection_format tag, e.g. `collection_format:"csv"` for ids=1,2,3, as in
+// OpenAPI 2.0. multi, the default, binds repeated keys only.
+var collectionFormats = map[string]string{
+	"csv":   ",",
+	"ssv":   " ",
+	"tsv":   "\t",
+	"pipes": "|",
+	"multi": "",
+}
+
+// splitValues returns the elements of values split by sep, empty values
+// having none.
+func splitValues(values []string, sep string) []string {
+	var elems []string
+	for _, val := range values {
+		if val != "" {
+			elems = append(elems, strings.Split(val, sep)...)
+		}
+	}
+	return elems
+}
+
+// setSliceField sets the slice value from values, converting each of them to
+// an element, up to the max_items tag of field.
+func setSliceField(values []string, typ reflect.Type, value reflect.Value, field *fieldInfo) error {
+	if field.maxItems > 0 && len(values) > field.maxItems {
+		return &CountError{Received: len(values), Accepted: field.maxItems, Dropped: values[field.maxItems:]}
+	}
+	slice := reflect.MakeSlice(typ, len(values), len(values))
+	for i, val := range values {
+		if err := setValue(val, typ.Elem(), slice.Index(i), field); err != nil {
+			return err
+		}
+	}
+	value.Set(slice)
+	return nil
+}
+
+// setArrayField sets the array value from values, converting each of them to
+// an element, the elements past them being zero.
+func setArrayField(values []string, typ reflect.Type, value reflect.Value, field *fieldInfo) error {
+	if len(values) > typ.Len() {
+		return &CountError{Received: len(values), Accepted: typ.Len(), Dropped: values[typ.Len():]}
+	}
+	array := reflect.New(typ).Elem()
+	for i, val := range values {
+		if err := setValue(val, typ.Elem(), array.Index(i), field); err != nil {
+			return err
+		}
+	}
+	value.Set(array)
+	return nil
+}
+
+// checkJSONCount returns a CountError if val, if a JSON array, has more than
+// accepted elements, which decoding it into an array would drop.
+func checkJSONCount(val string, accepted int) error {
+	var elems []json.RawMessage
+	if json.Unmarshal([]byte(val), &elems) != nil || len(elems) <= accepted {
+		return nil
+	}
+	dropped := make([]string, 0, len(elems)-accepted)
+	for _, elem := range elems[accepted:] {
+		dropped = append(dropped, string(elem))
+	}
+	return &CountError{Received: len(elems), Accepted: accepted, Dropped: dropped}
+}
+
+// setConvertedMapField sets the map value from val, a JSON object of strings,
+// converting each of them to an element.
+func setConvertedMapField(val string, typ reflect.Type, value reflect.Value, field *fieldInfo) error {
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(val), &raw); err != nil {
+		return err
+	}
+	result := reflect.MakeMapWithSize(typ, len(raw))
+	for key, elemVal := range raw {
+		if err := setMapEntry(result, key, elemVal, field, nil); err != nil {
+			return err
+		}
+	}
+	value.Set(result)
+	return nil
+}
+
+// setMapEntry converts key and val and stores them in the map m. val is
+// transformed with s, unless nil, see bindState.transform.
+func setMapEntry(m reflect.Value, key, val string, field *fieldInfo, s *bindState) error {
+	typ := m.Type()
+	k, err := convertMapKey(key, typ.Key())
+	if err != nil {
+		return &MapEntryError{Key: key, Err: err}
+	}
+	if s != nil {
+		if (field.noJSON || s.opts.NoJSONValues) && jsonValued(typ.Elem()) {
+			return &MapEntryError{Key: key, Err: ErrJSONValue}
+		}
+		if val, err = s.transform(val, field, StageLookup); err != nil {
+			return &MapEntryError{Key: key, Err: err}
+		}
+	}
+	elem := reflect.New(typ.Elem()).Elem()
+	if err := setValue(val, typ.Elem(), elem, field); err != nil {
+		return &MapEntryError{Key: key, Err: err}
+	}
+	m.SetMapIndex(k, elem)
+	return nil
+}
+
+// convertMapKey converts key to typ like values are converted.
+func convertMapKey(key string, typ reflect.Type) (reflect.Value, error) {
+	k := reflect.New(typ).Elem()
+	if err := setValue(key, typ, k, &fieldInfo{}); err != nil {
+		return reflect.Value{}, err
+	}
+	return k, nil
+}
@@ -0,0 +1,268 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMoney implements encoding.TextUnmarshaler.
+type testMoney struct {
+	Amount   int
+	Currency string
+}
+
+func (m *testMoney) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d %s", &m.Amount, &m.Currency)
+	return err
+}
+
+// testPoint is bound with a registered converter.
+type testPoint struct {
+	X, Y int
+}
+
+func parseTestPoint(val string) (testPoint, error) {
+	var p testPoint
+	if !strings.Contains(val, ",") {
+		return p, errors.New("invalid point")
+	}
+	_, err := fmt.Sscanf(val, "%d,%d", &p.X, &p.Y)
+	return p, err
+}
+
+func init() {
+	RegisterConverter(parseTestPoint)
+}
+
+type FooStructForConverters struct {
+	Price  testMoney            `form:"price"`
+	Origin *testPoint           `form:"origin"`
+	Path   []testPoint          `form:"path"`
+	Prices map[string]testMoney `form:"prices"`
+	Center testPoint
+}
+
+func TestMapFormConverters(t *testing.T) {
+	var obj FooStructForConverters
+	err := mapForm(&obj, map[string][]string{
+		"price":  {"10 EUR"},
+		"origin": {"1,2"},
+		"path":   {"1,2", "3,4"},
+		"prices": {`{"a": "5 USD", "b": "7 EUR"}`},
+		"Center": {"0,1"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, testMoney{10, "EUR"}, obj.Price)
+	assert.Equal(t, testPoint{1, 2}, *obj.Origin)
+	assert.Equal(t, []testPoint{{1, 2}, {3, 4}}, obj.Path)
+	assert.Equal(t, map[string]testMoney{"a": {5, "USD"}, "b": {7, "EUR"}}, obj.Prices)
+	assert.Equal(t, testPoint{0, 1}, obj.Center)
+
+	err = mapForm(&obj, map[string][]string{"path": {"1,2", "3"}})
+	assert.EqualError(t, err, `binding: field "path": invalid point`)
+
+	err = mapForm(&obj, map[string][]string{"prices": {`{"a": "x"}`}})
+	assert.Error(t, err)
+}
+
+func TestMapAnyConverters(t *testing.T) {
+	var obj FooStructForConverters
+	err := MapAny(&obj, map[string]interface{}{
+		"price": "3 GBP",
+		"path":  []interface{}{"5,6"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, testMoney{3, "GBP"}, obj.Price)
+	assert.Equal(t, []testPoint{{5, 6}}, obj.Path)
+}
+
+// testLevel is a named int implementing encoding.TextUnmarshaler.
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("invalid level %q", text)
+	}
+	return nil
+}
+
+type FooStructForTextUnmarshaler struct {
+	Level   testLevel             `form:"level"`
+	Levels  []testLevel           `form:"levels"`
+	Price   *testMoney            `form:"price"`
+	Prices  []*testMoney          `form:"prices"`
+	Budgets map[string]*testMoney `form:"budgets"`
+	Default testLevel             `form:"default" default:"high"`
+}
+
+func TestMapFormTextUnmarshaler(t *testing.T) {
+	var obj FooStructForTextUnmarshaler
+	err := mapForm(&obj, map[string][]string{
+		"level":       {"low"},
+		"levels":      {"high", "low"},
+		"price":       {"10 EUR"},
+		"prices":      {"1 USD", "2 GBP"},
+		"budgets[q1]": {"5 EUR"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, testLevel(1), obj.Level)
+	assert.Equal(t, []testLevel{2, 1}, obj.Levels)
+	assert.Equal(t, &testMoney{10, "EUR"}, obj.Price)
+	assert.Equal(t, []*testMoney{{1, "USD"}, {2, "GBP"}}, obj.Prices)
+	assert.Equal(t, map[string]*testMoney{"q1": {5, "EUR"}}, obj.Budgets)
+	assert.Equal(t, testLevel(2), obj.Default)
+
+	err = mapForm(&obj, map[string][]string{"level": {"1"}})
+	assert.EqualError(t, err, `binding: field "level": invalid level "1"`)
+}
+
+// testBounds implements Unmarshaler from a lower and an upper value, or
+// from a single value for both.
+type testBounds struct {
+	Lower, Upper int
+}
+
+func (b *testBounds) UnmarshalForm(values []string) error {
+	if len(values) > 2 {
+		return fmt.Errorf("%d bounds", len(values))
+	}
+	var err error
+	if b.Lower, err = strconv.Atoi(values[0]); err != nil {
+		return err
+	}
+	b.Upper, err = strconv.Atoi(values[len(values)-1])
+	return err
+}
+
+// UnmarshalText is ignored for Unmarshaler.
+func (b *testBounds) UnmarshalText(text []byte) error {
+	return errors.New("unexpected UnmarshalText")
+}
+
+type FooStructForUnmarshaler struct {
+	Bounds  testBounds            `form:"bounds"`
+	Price   *testBounds           `form:"price"`
+	Steps   []testBounds          `form:"steps"`
+	Ranges  map[string]testBounds `form:"ranges"`
+	Default testBounds            `form:"default" default:"7"`
+}
+
+func TestMapFormUnmarshaler(t *testing.T) {
+	var obj FooStructForUnmarshaler
+	err := mapForm(&obj, map[string][]string{
+		"bounds":     {"1", "5"},
+		"price":      {"10"},
+		"steps":      {"2", "3"},
+		"ranges[ok]": {"4"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, testBounds{1, 5}, obj.Bounds)
+	assert.Equal(t, &testBounds{10, 10}, obj.Price)
+	assert.Equal(t, []testBounds{{2, 2}, {3, 3}}, obj.Steps)
+	assert.Equal(t, map[string]testBounds{"ok": {4, 4}}, obj.Ranges)
+	assert.Equal(t, testBounds{7, 7}, obj.Default)
+
+	err = mapForm(&obj, map[string][]string{"bounds": {"1", "2", "3"}})
+	assert.EqualError(t, err, `binding: field "bounds": 3 bounds`)
+}
+
+type FooStructForMapKeys struct {
+	Scores map[int]string       `form:"scores"`
+	Spots  map[testPoint]int    `form:"spots"`
+	Meta   map[string]string    `form:"meta"`
+	Prices map[string]testMoney `form:"prices"`
+}
+
+func TestMapFormMapKeys(t *testing.T) {
+	var obj FooStructForMapKeys
+	err := mapForm(&obj, map[string][]string{
+		"scores[1]":  {"a"},
+		"scores[20]": {"b"},
+		"spots[1,2]": {"3"},
+		"meta[env]":  {"prod"},
+		"prices[a]":  {"5 USD"},
+		"meta[a][b]": {"ignored"},
+		"scoresx[1]": {"ignored"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "a", 20: "b"}, obj.Scores)
+	assert.Equal(t, map[testPoint]int{{1, 2}: 3}, obj.Spots)
+	assert.Equal(t, map[string]string{"env": "prod"}, obj.Meta)
+	assert.Equal(t, map[string]testMoney{"a": {5, "USD"}}, obj.Prices)
+
+	obj = FooStructForMapKeys{}
+	err = mapForm(&obj, map[string][]string{"scores[x]": {"a"}})
+	var entryErr *MapEntryError
+	if assert.True(t, errors.As(err, &entryErr)) {
+		assert.Equal(t, "x", entryErr.Key)
+	}
+	assert.Contains(t, err.Error(), `binding: field "scores": map key "x": `)
+
+	err = mapForm(&obj, map[string][]string{"spots[1,2]": {"x"}})
+	assert.Contains(t, err.Error(), `map key "1,2": `)
+
+	err = mapForm(&obj, map[string][]string{"spots": {`{"3,4": "5"}`}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[testPoint]int{{3, 4}: 5}, obj.Spots)
+}
+
+func TestMapAnyMapKeys(t *testing.T) {
+	var obj FooStructForMapKeys
+	err := MapAny(&obj, map[string]interface{}{
+		"scores": map[string]interface{}{"7": "x"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{7: "x"}, obj.Scores)
+
+	err = MapAny(&obj, map[string]interface{}{
+		"scores": map[string]interface{}{"y": "x"},
+	})
+	var entryErr *MapEntryError
+	assert.True(t, errors.As(err, &entryErr))
+}
+
+func TestConvertString(t *testing.T) {
+	v, err := ConvertString("42", reflect.TypeOf(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v.Interface())
+
+	v, err = ConvertString("3,4", reflect.TypeOf(testPoint{}))
+	assert.NoError(t, err)
+	assert.Equal(t, testPoint{3, 4}, v.Interface())
+
+	v, err = ConvertString("5,6", reflect.TypeOf(&testPoint{}))
+	assert.NoError(t, err)
+	assert.Equal(t, &testPoint{5, 6}, v.Interface())
+
+	v, err = ConvertString("12 EUR", reflect.TypeOf(testMoney{}))
+	assert.NoError(t, err)
+	assert.Equal(t, testMoney{12, "EUR"}, v.Interface())
+
+	v, err = ConvertString("[1, 2]", reflect.TypeOf([]int{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, v.Interface())
+
+	v, err = ConvertString("true", reflect.TypeOf(false))
+	assert.NoError(t, err)
+	assert.Equal(t, true, v.Interface())
+
+	_, err = ConvertString("300", reflect.TypeOf(uint8(0)))
+	assert.Error(t, err)
+	_, err = ConvertString("3", reflect.TypeOf(testPoint{}))
+	assert.EqualError(t, err, "invalid point")
+}
@@ -0,0 +1,275 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Copy assigns the fields of src, a struct or a pointer to one, to the fields
+// of dst, which must be a pointer to a struct, matching them by their binding
+// keys, i.e. their json or form tags, the same way the form bindings do. It
+// lets services separating transport structs from domain models drop their
+// hand-written mapping code.
+//
+// Values are converted like form values, so a number can be copied into a
+// string field, a string into a numeric or time.Time field, and min, max and
+// pattern tags of dst apply. A time.Time is copied into a string field using
+// the time_format tag of the field, RFC 3339 by default, and a []byte using
+// its encoding tag. Structs, slices and maps are copied field by
+// field and element by element, map keys being converted like values.
+// Values assignable to their destination are
+// assigned as is, so slices and maps of the same type are shared. Copy doesn't
+// validate dst. A nil src, or a dst other than a non-nil pointer to a
+// struct, fails with an error.
+func Copy(dst, src interface{}) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: Copy destination %T is not a non-nil pointer to a struct", dst)
+	}
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Kind() == reflect.Ptr && srcValue.IsNil() {
+		return fmt.Errorf("binding: Copy source %T is nil", src)
+	}
+	srcValue = reflect.Indirect(srcValue)
+	if srcValue.Kind() != reflect.Struct {
+		return fmt.Errorf("binding: Copy source %T is not a struct", src)
+	}
+	values, err := copySource(srcValue)
+	if err != nil {
+		return err
+	}
+	return copyStruct(dstValue.Elem(), values, "", "")
+}
+
+// copySource returns the fields of the struct val keyed by their binding keys.
+func copySource(val reflect.Value) (map[string]reflect.Value, error) {
+	values := make(map[string]reflect.Value)
+	return values, collectCopyValues(val, values, "")
+}
+
+// collectCopyValues adds the fields of the struct val to values, keyed by
+// their binding keys after prefix.
+func collectCopyValues(val reflect.Value, values map[string]reflect.Value, prefix string) error {
+	info, err := cachedStructInfo(val.Type(), "")
+	if err != nil {
+		return err
+	}
+	for _, field := range info.fields {
+		if field.sourceOnly {
+			continue
+		}
+		if field.nested {
+			nested := nestedValue(val.Field(field.index))
+			if !nested.IsValid() {
+				continue
+			}
+			if err := collectCopyValues(nested, values, prefix+field.prefix); err != nil {
+				return err
+			}
+			continue
+		}
+		values[prefix+field.key] = val.Field(field.index)
+	}
+	return nil
+}
+
+// copyStruct copies values onto the struct val, matching its fields by their
+// binding keys after prefix.
+func copyStruct(val reflect.Value, values map[string]reflect.Value, path, prefix string) error {
+	info, err := cachedStructInfo(val.Type(), "")
+	if err != nil {
+		return err
+	}
+	for _, field := range info.fields {
+		if field.sourceOnly {
+			continue
+		}
+		structField := val.Field(field.index)
+		if field.nested {
+			// recursive structs are only copied as deep as there are values
+			if field.recursive && !hasKeyPrefix(values, prefix+field.prefix) {
+				continue
+			}
+			err := bindNested(structField, func(nested reflect.Value) error {
+				return copyStruct(nested, values, path, prefix+field.prefix)
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		src, ok := values[prefix+field.key]
+		if !ok {
+			continue
+		}
+		fieldPath := joinPath(path, prefix+field.key)
+		if err := copyValue(structField, src, field, fieldPath); err != nil {
+			if _, ok := err.(*FieldError); ok {
+				return err
+			}
+			return &FieldError{Path: fieldPath, Key: prefix + field.key, Err: err}
+		}
+	}
+	return nil
+}
+
+// copyValue copies src onto dst. field is the struct field being copied,
+// also used for its elements.
+func copyValue(dst, src reflect.Value, field *fieldInfo, path string) error {
+	// time zones are copied by name
+	if loc, ok := src.Interface().(*time.Location); ok && dst.Kind() == reflect.String {
+		if loc != nil {
+			dst.SetString(loc.String())
+		}
+		return nil
+	}
+	if dst.Type() == locationType && src.Kind() == reflect.String {
+		return setLocationField(src.String(), dst)
+	}
+	for src.Kind() == reflect.Ptr || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+	// sql.Null* values are copied as the value they wrap, or not at all
+	if isSQLNull(src.Type()) && nestedType(dst.Type()) != src.Type() {
+		if !src.Field(1).Bool() {
+			return nil
+		}
+		src = src.Field(0)
+	}
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return copyValue(dst.Elem(), src, field, path)
+	}
+
+	if isBytes(src.Type()) && dst.Kind() == reflect.String {
+		dst.SetString(encodeBytes(src.Bytes(), field.encoding))
+		return nil
+	}
+	if t, ok := src.Interface().(time.Time); ok && dst.Kind() == reflect.String {
+		format := field.timeFormat
+		if format == "" {
+			format = time.RFC3339
+		}
+		dst.SetString(formatTime(t, format))
+		return nil
+	}
+
+	if src.Kind() == reflect.Struct && isSQLNull(dst.Type()) {
+		if err := copyValue(dst.Field(0), src, field, path); err != nil {
+			return err
+		}
+		dst.Field(1).SetBool(true)
+		return nil
+	}
+	switch src.Kind() {
+	case reflect.String:
+		return setValue(src.String(), dst.Type(), dst, field)
+	case reflect.Bool:
+		return setValue(strconv.FormatBool(src.Bool()), dst.Type(), dst, field)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setValue(strconv.FormatInt(src.Int(), 10), dst.Type(), dst, field)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setValue(strconv.FormatUint(src.Uint(), 10), dst.Type(), dst, field)
+	case reflect.Float32, reflect.Float64:
+		return setValue(strconv.FormatFloat(src.Float(), 'f', -1, src.Type().Bits()), dst.Type(), dst, field)
+	case reflect.Struct:
+		if dst.Kind() != reflect.Struct {
+			break
+		}
+		values, err := copySource(src)
+		if err != nil {
+			return err
+		}
+		return copyStruct(dst, values, path, "")
+	case reflect.Slice, reflect.Array:
+		if dst.Kind() != reflect.Slice {
+			break
+		}
+		if src.Kind() == reflect.Slice && src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		result := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := copyValue(result.Index(i), src.Index(i), field, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(result)
+		return nil
+	case reflect.Map:
+		if dst.Kind() != reflect.Map {
+			break
+		}
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		result := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			key, err := copyMapKey(iter.Key(), dst.Type().Key())
+			if err != nil {
+				return fmt.Errorf("cannot copy key %v of %s into %s: %v", iter.Key(), src.Type(), dst.Type(), err)
+			}
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := copyValue(elem, iter.Value(), field, fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return err
+			}
+			result.SetMapIndex(key, elem)
+		}
+		dst.Set(result)
+		return nil
+	}
+	return fmt.Errorf("cannot copy %s into %s", src.Type(), dst.Type())
+}
+
+// copyMapKey converts the map key key to typ. Keys of the same kind are
+// converted directly, others through their string form, like values.
+func copyMapKey(key reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if key.Kind() == typ.Kind() && key.Type().ConvertibleTo(typ) {
+		return key.Convert(typ), nil
+	}
+	var s string
+	switch key.Kind() {
+	case reflect.String:
+		s = key.String()
+	case reflect.Bool:
+		s = strconv.FormatBool(key.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(key.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		s = strconv.FormatFloat(key.Float(), 'f', -1, key.Type().Bits())
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported key type %s", key.Type())
+	}
+	return convertMapKey(s, typ)
+}
+
+// hasKeyPrefix reports whether values has keys starting with prefix.
+func hasKeyPrefix(values map[string]reflect.Value, prefix string) bool {
+	for key := range values {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
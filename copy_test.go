@@ -0,0 +1,133 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForCopyLineDTO struct {
+	SKU      string `json:"sku"`
+	Quantity string `json:"quantity"`
+}
+
+type FooStructForCopyDTO struct {
+	ID      string                    `json:"id"`
+	Price   float64                   `json:"price"`
+	Created string                    `json:"created"`
+	Active  *bool                     `json:"active"`
+	Lines   []FooStructForCopyLineDTO `json:"lines"`
+	Labels  map[string]int            `json:"labels"`
+	Meta    struct {
+		Note string `form:"note"`
+	}
+	Ignored string `json:"ignored"`
+}
+
+type FooStructForCopyLine struct {
+	SKU      string `form:"sku"`
+	Quantity uint   `form:"quantity" max:"10"`
+}
+
+type FooStructForCopyModel struct {
+	ID      int                    `form:"id"`
+	Price   string                 `form:"price"`
+	Created time.Time              `form:"created" time_format:"2006-01-02" time_utc:"1"`
+	Active  bool                   `form:"active"`
+	Lines   []FooStructForCopyLine `form:"lines"`
+	Labels  map[string]string      `form:"labels"`
+	Note    *string                `form:"note"`
+	Missing string                 `form:"missing"`
+}
+
+func TestCopy(t *testing.T) {
+	active := true
+	src := FooStructForCopyDTO{
+		ID:      "42",
+		Price:   9.5,
+		Created: "2018-09-15",
+		Active:  &active,
+		Lines:   []FooStructForCopyLineDTO{{SKU: "a", Quantity: "2"}},
+		Labels:  map[string]int{"x": 1},
+	}
+	src.Meta.Note = "note"
+
+	dst := FooStructForCopyModel{Missing: "kept"}
+	assert.NoError(t, Copy(&dst, &src))
+	assert.Equal(t, 42, dst.ID)
+	assert.Equal(t, "9.5", dst.Price)
+	assert.Equal(t, time.Date(2018, 9, 15, 0, 0, 0, 0, time.UTC), dst.Created)
+	assert.True(t, dst.Active)
+	assert.Equal(t, []FooStructForCopyLine{{SKU: "a", Quantity: 2}}, dst.Lines)
+	assert.Equal(t, map[string]string{"x": "1"}, dst.Labels)
+	assert.Equal(t, "note", *dst.Note)
+	assert.Equal(t, "kept", dst.Missing)
+
+	back := FooStructForCopyDTO{}
+	assert.NoError(t, Copy(&back, dst))
+	assert.Equal(t, "42", back.ID)
+	assert.Equal(t, 9.5, back.Price)
+	assert.Equal(t, "2018-09-15T00:00:00Z", back.Created)
+	assert.Equal(t, "note", back.Meta.Note)
+}
+
+func TestCopyFail(t *testing.T) {
+	dst := FooStructForCopyModel{}
+	err := Copy(&dst, FooStructForCopyDTO{ID: "abc"})
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "id", fieldErr.Path)
+	}
+
+	err = Copy(&dst, FooStructForCopyDTO{Lines: []FooStructForCopyLineDTO{{Quantity: "11"}}})
+	var rangeErr *RangeError
+	assert.True(t, errors.As(err, &rangeErr))
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "lines[0].quantity", fieldErr.Path)
+	}
+
+	err = Copy(&dst, struct {
+		Lines map[string]string `json:"lines"`
+	}{Lines: map[string]string{}})
+	assert.EqualError(t, err, `binding: field "lines": cannot copy map[string]string into []binding.FooStructForCopyLine`)
+}
+
+type FooStructForCopyKeys struct {
+	Counts map[string]string `json:"counts"`
+	Scores map[int]string    `json:"scores"`
+}
+
+func TestCopyMapKeys(t *testing.T) {
+	var dst FooStructForCopyKeys
+	err := Copy(&dst, struct {
+		Counts map[int]string    `json:"counts"`
+		Scores map[string]string `json:"scores"`
+	}{Counts: map[int]string{65: "a"}, Scores: map[string]string{"7": "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"65": "a"}, dst.Counts)
+	assert.Equal(t, map[int]string{7: "b"}, dst.Scores)
+
+	err = Copy(&dst, struct {
+		Scores map[string]string `json:"scores"`
+	}{Scores: map[string]string{"x": "b"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `binding: field "scores": cannot copy key x of map[string]string into map[int]string`)
+}
+
+func TestCopyInvalid(t *testing.T) {
+	dst := FooStructForCopyModel{}
+	assert.NotPanics(t, func() {
+		assert.EqualError(t, Copy(&dst, (*FooStructForCopyDTO)(nil)), "binding: Copy source *binding.FooStructForCopyDTO is nil")
+		assert.EqualError(t, Copy(&dst, nil), "binding: Copy source <nil> is not a struct")
+		assert.EqualError(t, Copy(&dst, "abc"), "binding: Copy source string is not a struct")
+		assert.EqualError(t, Copy(nil, FooStructForCopyDTO{}), "binding: Copy destination <nil> is not a non-nil pointer to a struct")
+		assert.EqualError(t, Copy((*FooStructForCopyModel)(nil), FooStructForCopyDTO{}), "binding: Copy destination *binding.FooStructForCopyModel is not a non-nil pointer to a struct")
+		assert.EqualError(t, Copy(dst, FooStructForCopyDTO{}), "binding: Copy destination binding.FooStructForCopyModel is not a non-nil pointer to a struct")
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+)
+
+// maxCorpusBody bounds the bodies recorded by a CorpusRecorder.
+const maxCorpusBody = 1 << 20
+
+// CorpusRecorder records the shape of the requests bound, with every value
+// anonymized, into a corpus directory to seed fuzzing and regression suites
+// with production-shaped inputs, see Options.Corpus. Each request is written
+// to Dir/<ok|fail>/<struct>/<hash>.json as a CorpusEntry, requests of the same
+// shape sharing a file.
+//
+// String values are replaced with a keyed hash, so that equal values stay
+// equal, numbers with 0 and booleans with false. Only JSON and form bodies
+// are recorded, up to 1MB.
+type CorpusRecorder struct {
+	Dir string
+	// Key keys the hash of values, keep it secret.
+	Key []byte
+}
+
+// CorpusEntry is a request recorded by a CorpusRecorder.
+type CorpusEntry struct {
+	ContentType string          `json:"content_type,omitempty"`
+	Query       string          `json:"query,omitempty"`
+	Form        string          `json:"form,omitempty"`
+	JSON        json.RawMessage `json:"json,omitempty"`
+}
+
+// watch returns a buffer receiving the body of req as it is read.
+func (c *CorpusRecorder) watch(req *http.Request) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	if req.Body != nil {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(req.Body, &limitedWriter{buf, maxCorpusBody}), req.Body}
+	}
+	return buf
+}
+
+// record writes the anonymized entry of req, whose body read while binding
+// obj is body, with the outcome err. Failing to write it is ignored.
+func (c *CorpusRecorder) record(req *http.Request, obj interface{}, body *bytes.Buffer, err error) {
+	entry := CorpusEntry{Query: c.anonymizeForm(parseQuery(req.URL.RawQuery))}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case body.Len() >= maxCorpusBody:
+	case mediaType == MIMEJSON && body.Len() > 0:
+		var v interface{}
+		if json.Unmarshal(body.Bytes(), &v) == nil {
+			entry.JSON, _ = json.Marshal(c.anonymizeJSON(v))
+			entry.ContentType = mediaType
+		}
+	case mediaType == MIMEPOSTForm:
+		if values, perr := url.ParseQuery(body.String()); perr == nil {
+			entry.Form = c.anonymizeForm(values)
+			entry.ContentType = mediaType
+		}
+	case mediaType == MIMEMultipartPOSTForm && req.MultipartForm != nil:
+		entry.Form = c.anonymizeForm(req.MultipartForm.Value)
+		entry.ContentType = mediaType
+	}
+	data, merr := json.MarshalIndent(entry, "", "  ")
+	if merr != nil {
+		return
+	}
+	outcome := "ok"
+	if err != nil {
+		outcome = "fail"
+	}
+	typ := reflect.TypeOf(obj)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	sum := sha256.Sum256(data)
+	dir := filepath.Join(c.Dir, outcome, typ.String())
+	if os.MkdirAll(dir, 0o755) != nil {
+		return
+	}
+	ioutil.WriteFile(filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"), data, 0o644)
+}
+
+// anonymize replaces a string value with its keyed hash.
+func (c *CorpusRecorder) anonymize(s string) string {
+	h := hmac.New(sha256.New, c.Key)
+	io.WriteString(h, s)
+	return "h" + hex.EncodeToString(h.Sum(nil)[:6])
+}
+
+func (c *CorpusRecorder) anonymizeJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return c.anonymize(v)
+	case float64:
+		return 0
+	case bool:
+		return false
+	case []interface{}:
+		for i := range v {
+			v[i] = c.anonymizeJSON(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = c.anonymizeJSON(v[key])
+		}
+	}
+	return v
+}
+
+// anonymizeForm encodes values anonymized, values parsing as numbers or
+// booleans keeping their type.
+func (c *CorpusRecorder) anonymizeForm(values map[string][]string) string {
+	anon := make(url.Values, len(values))
+	for key, vs := range values {
+		for _, v := range vs {
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				v = "0"
+			} else if _, err := strconv.ParseBool(v); err == nil {
+				v = "false"
+			} else if v != "" {
+				v = c.anonymize(v)
+			}
+			anon[key] = append(anon[key], v)
+		}
+	}
+	return anon.Encode()
+}
+
+// limitedWriter writes up to n bytes to w, silently dropping the rest.
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if rest := l.n; int64(len(p)) > rest {
+		if rest > 0 {
+			l.w.Write(p[:rest])
+		}
+		l.n = 0
+		return len(p), nil
+	}
+	l.n -= int64(len(p))
+	l.w.Write(p)
+	return len(p), nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readCorpus(t *testing.T, dir string) []CorpusEntry {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.NoError(t, err)
+	var entries []CorpusEntry
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		var entry CorpusEntry
+		assert.NoError(t, json.Unmarshal(data, &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestCorpusRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "binding-corpus-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	c := &CorpusRecorder{Dir: dir, Key: []byte("secret")}
+	defer withOptions(Options{Corpus: c})()
+
+	for i := 0; i < 2; i++ {
+		req := requestWithBody("POST", "/?page=2&q=alice", `{"foo": "alice", "n": 42, "ok": true, "tags": ["x", null]}`)
+		req.Header.Set("Content-Type", MIMEJSON)
+		assert.NoError(t, JSON.Bind(req, &FooStruct{}))
+	}
+	entries := readCorpus(t, filepath.Join(dir, "ok", "binding.FooStruct"))
+	if assert.Len(t, entries, 1) {
+		hashed := c.anonymize("alice")
+		assert.Equal(t, MIMEJSON, entries[0].ContentType)
+		assert.Equal(t, "page=0&q="+hashed, entries[0].Query)
+		assert.JSONEq(t, `{"foo": "`+hashed+`", "n": 0, "ok": false, "tags": ["`+c.anonymize("x")+`", null]}`, string(entries[0].JSON))
+		assert.NotContains(t, string(entries[0].JSON), "alice")
+	}
+
+	req, _ := http.NewRequest("POST", "/", strings.NewReader("bar=secret&flag=true"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	assert.Error(t, FormPost.Bind(req, &FooStruct{}))
+	entries = readCorpus(t, filepath.Join(dir, "fail", "binding.FooStruct"))
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, MIMEPOSTForm, entries[0].ContentType)
+		assert.Equal(t, "bar="+c.anonymize("secret")+"&flag=false", entries[0].Form)
+	}
+}
+
+func TestLimitedWriter(t *testing.T) {
+	var sb strings.Builder
+	w := &limitedWriter{&sb, 5}
+	n, _ := w.Write([]byte("abc"))
+	assert.Equal(t, 3, n)
+	n, _ = w.Write([]byte("defgh"))
+	assert.Equal(t, 5, n)
+	w.Write([]byte("ijk"))
+	assert.Equal(t, "abcde", sb.String())
+}
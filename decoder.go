@@ -0,0 +1,143 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"mime"
+	"net/http"
+)
+
+// Decoder binds requests using its own Options rather than DefaultOptions, so
+// that different parts of an application can bind differently.
+type Decoder struct {
+	Options Options
+
+	// Resolve, if set, returns the Options each request is bound with
+	// instead of Options, so that e.g. the limits and policies of a SaaS
+	// platform can vary per tenant.
+	Resolve OptionsResolver
+}
+
+// OptionsResolver returns the Options to bind req with, e.g. those of the
+// tenant named by one of its headers, or nil to use the Options of the
+// Decoder. An error fails the bind.
+type OptionsResolver func(req *http.Request) (*Options, error)
+
+// NewDecoder returns a Decoder using opts.
+func NewDecoder(opts Options) *Decoder {
+	return &Decoder{Options: opts}
+}
+
+// Bind binds req onto obj with the binding handling its method and content
+// type, like Default. Requests with a missing or unrecognized content type are
+// handled according to the UnknownContentType option.
+func (d *Decoder) Bind(req *http.Request, obj interface{}) error {
+	opts, err := d.options(req)
+	if err != nil {
+		return d.Options.negotiate(req, err)
+	}
+	r, b, err := bindingFor(req, opts)
+	if err != nil {
+		return opts.negotiate(req, err)
+	}
+	return bindWith(r, obj, b, opts)
+}
+
+// BindWith binds req onto obj with b. Bindings which don't support Options
+// are run as is.
+func (d *Decoder) BindWith(req *http.Request, obj interface{}, b Binding) error {
+	opts, err := d.options(req)
+	if err != nil {
+		return d.Options.negotiate(req, err)
+	}
+	return bindWith(req, obj, b, opts)
+}
+
+func bindWith(req *http.Request, obj interface{}, b Binding, opts *Options) error {
+	sb, ok := b.(stateBinding)
+	if !ok {
+		return b.Bind(req, obj)
+	}
+	s := &bindState{opts: opts}
+	return s.run(sb, req, obj)
+}
+
+// options returns the Options to bind req with, see Resolve.
+func (d *Decoder) options(req *http.Request) (*Options, error) {
+	if d.Resolve == nil {
+		return &d.Options, nil
+	}
+	opts, err := d.Resolve(req)
+	if err != nil || opts != nil {
+		return opts, err
+	}
+	return &d.Options, nil
+}
+
+// bindingFor returns the binding handling req, according to the
+// UnknownContentType option of opts if its content type is missing or
+// unrecognized, along with the request to bind.
+func bindingFor(req *http.Request, opts *Options) (*http.Request, Binding, error) {
+	if b := knownBinding(req); b != nil {
+		return req, b, nil
+	}
+	switch opts.UnknownContentType {
+	case ContentTypeReject:
+		return nil, nil, &ContentTypeError{
+			ContentType: req.Header.Get("Content-Type"),
+			Method:      req.Method,
+			Accepted:    SupportedMediaTypes(),
+		}
+	case ContentTypeJSON:
+		return req, JSON, nil
+	case ContentTypeSniff:
+		return req, Sniff, nil
+	default:
+		return withContentType(req, MIMEPOSTForm), Form, nil
+	}
+}
+
+// knownBinding returns the binding handling req, or nil if its content type
+// is missing or unrecognized.
+func knownBinding(req *http.Request) Binding {
+	if req.Method == "GET" {
+		return Form
+	}
+	contentType := req.Header.Get("Content-Type")
+	if !Supports(contentType) {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return Default(req.Method, mediaType)
+}
+
+// withContentType returns a shallow copy of req with its content type set to
+// contentType.
+func withContentType(req *http.Request, contentType string) *http.Request {
+	r := *req
+	r.Header = req.Header.Clone()
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set("Content-Type", contentType)
+	return &r
+}
+
+// ContentTypePolicy selects how requests with a missing or unrecognized
+// content type are bound.
+type ContentTypePolicy int
+
+const (
+	// ContentTypeForm binds them as URL-encoded forms.
+	ContentTypeForm ContentTypePolicy = iota
+	// ContentTypeReject fails the bind with a *ContentTypeError.
+	ContentTypeReject
+	// ContentTypeJSON binds them as JSON, which suits APIs called with curl
+	// -d and no -H.
+	ContentTypeJSON
+	// ContentTypeSniff binds them with Sniff, according to the first bytes
+	// of their body.
+	ContentTypeSniff
+)
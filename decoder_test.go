@@ -0,0 +1,112 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderBind(t *testing.T) {
+	d := NewDecoder(Options{})
+
+	obj := FooStruct{}
+	req := requestWithBody("POST", "/", `{"foo": "bar"}`)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	assert.NoError(t, d.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+
+	obj = FooStruct{}
+	req = requestWithBody("GET", "/?foo=baz", "")
+	assert.NoError(t, d.Bind(req, &obj))
+	assert.Equal(t, "baz", obj.Foo)
+}
+
+func TestDecoderUnknownContentType(t *testing.T) {
+	for _, contentType := range []string{"", "text/plain"} {
+		obj := FooStruct{}
+		req := requestWithBody("POST", "/", "foo=bar")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		err := NewDecoder(Options{}).Bind(req, &obj)
+		assert.NoError(t, err)
+		assert.Equal(t, "bar", obj.Foo)
+		assert.Equal(t, contentType, req.Header.Get("Content-Type"))
+
+		obj = FooStruct{}
+
+		req = requestWithBody("POST", "/", `{"foo": "bar"}`)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		err = NewDecoder(Options{UnknownContentType: ContentTypeJSON}).Bind(req, &obj)
+		assert.NoError(t, err)
+		assert.Equal(t, "bar", obj.Foo)
+
+		err = NewDecoder(Options{UnknownContentType: ContentTypeReject}).Bind(req, &obj)
+		var ctErr *ContentTypeError
+		if assert.True(t, errors.As(err, &ctErr)) {
+			assert.Equal(t, contentType, ctErr.ContentType)
+		}
+	}
+
+	req := requestWithBody("POST", "/", "")
+	err := NewDecoder(Options{UnknownContentType: ContentTypeReject}).Bind(req, &FooStruct{})
+	assert.EqualError(t, err, "binding: missing content type")
+	req.Header.Set("Content-Type", "text/csv")
+	err = NewDecoder(Options{UnknownContentType: ContentTypeReject}).Bind(req, &FooStruct{})
+	assert.EqualError(t, err, `binding: unsupported content type "text/csv"`)
+}
+
+func TestDecoderOptions(t *testing.T) {
+	d := NewDecoder(Options{EmptyBody: EmptyBodyIgnore})
+	req := requestWithBody("POST", "/", "")
+	req.Header.Set("Content-Type", MIMEJSON)
+	assert.NoError(t, d.Bind(req, &FooStructForAlias{}))
+
+	req = requestWithBody("POST", "/", "")
+	req.Header.Set("Content-Type", MIMEJSON)
+	assert.Equal(t, ErrEmptyBody, JSON.Bind(req, &FooStructForAlias{}))
+}
+
+func TestDecoderResolve(t *testing.T) {
+	tenants := map[string]*Options{
+		"small": {MaxBodySize: 8},
+		"json":  {UnknownContentType: ContentTypeJSON},
+	}
+	d := &Decoder{Resolve: func(req *http.Request) (*Options, error) {
+		tenant := req.Header.Get("X-Tenant")
+		if tenant == "banned" {
+			return nil, errors.New("tenant banned")
+		}
+		return tenants[tenant], nil
+	}}
+
+	req := requestWithBody("POST", "/", `{"foo": "bar"}`)
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("X-Tenant", "small")
+	var limitErr *LimitError
+	assert.True(t, errors.As(d.Bind(req, &FooStruct{}), &limitErr))
+
+	obj := FooStruct{}
+	req = requestWithBody("POST", "/", `{"foo": "bar"}`)
+	req.Header.Set("X-Tenant", "json")
+	assert.NoError(t, d.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+
+	obj = FooStruct{}
+	req = requestWithBody("POST", "/", `foo=baz`)
+	assert.NoError(t, d.BindRequest(req, &obj))
+	assert.Equal(t, "baz", obj.Foo)
+
+	req = requestWithBody("POST", "/", `{"foo": "bar"}`)
+	req.Header.Set("X-Tenant", "banned")
+	assert.EqualError(t, d.BindWith(req, &obj, JSON), "tenant banned")
+	assert.EqualError(t, d.Bind(req, &obj), "tenant banned")
+}
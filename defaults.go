@@ -0,0 +1,171 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCase is a case of the default_if tag of a field, which sets the
+// default of the field from the value of another field of its struct, e.g.
+//
+//	Currency string  `form:"currency"`
+//	Amount   float64 `form:"amount" default_if:"Currency=JPY:0|Currency=*:0.00"`
+//
+// The cases are evaluated in order once the other fields are bound, * matching
+// any value. Without a matching case, the default tag applies.
+type defaultCase struct {
+	// index is the index of the field named by the case.
+	index []int
+	// want is the value of the field the case matches, * for any.
+	want  string
+	value string
+}
+
+func compileDefaultIf(typ reflect.Type, typeField reflect.StructField) ([]defaultCase, error) {
+	tag := typeField.Tag.Get("default_if")
+	if tag == "" {
+		return nil, nil
+	}
+	var cases []defaultCase
+	for _, part := range strings.Split(tag, "|") {
+		cond, value, ok := strings.Cut(part, ":")
+		name, want, ok2 := strings.Cut(cond, "=")
+		if !ok || !ok2 || name == "" {
+			return nil, fmt.Errorf("invalid default_if %q", tag)
+		}
+		other, ok := typ.FieldByName(name)
+		if !ok || other.Name == typeField.Name {
+			return nil, fmt.Errorf("default_if: no field %s", name)
+		}
+		defaultValue := reflect.New(typeField.Type).Elem()
+		if err := setDefaultValue(typeField.Type, value, defaultValue, &fieldInfo{}); err != nil {
+			return nil, fmt.Errorf("invalid default_if %q: %v", value, err)
+		}
+		cases = append(cases, defaultCase{index: other.Index, want: want, value: value})
+	}
+	return cases, nil
+}
+
+// conditionalDefault returns the default of the field of the struct val, from
+// the first case of its default_if tag matching the other fields of val, or
+// its default tag.
+func (f *fieldInfo) conditionalDefault(val reflect.Value) string {
+	for _, c := range f.defaultIf {
+		if c.want == "*" || c.want == fieldString(val.FieldByIndex(c.index)) {
+			return c.value
+		}
+	}
+	return f.defaultValue
+}
+
+// fieldString formats the value of a field to be compared with a default_if
+// case, the empty string for nil pointers.
+func fieldString(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// deferredDefault is a field bound with its conditional default once the
+// other fields of its struct are bound, see defaultCase.
+type deferredDefault struct {
+	field       *fieldInfo
+	typ         reflect.Type
+	structField reflect.Value
+	seq         int
+	path, key   string
+}
+
+// setDeferredDefaults sets the fields deferred from the n-th on, whose
+// struct val is bound.
+func (m *formMapper) setDeferredDefaults(val reflect.Value, n int) error {
+	deferred := m.deferred[n:]
+	m.deferred = m.deferred[:n]
+	for _, d := range deferred {
+		value := d.field.conditionalDefault(val)
+		if value == "" {
+			continue
+		}
+		if err := m.state.setDefault(value, d.typ, d.structField, d.field); err != nil {
+			if err := m.failSeq(d.seq, &FieldError{Path: d.path, Key: d.key, Err: err}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setDefaultValue sets structField, of type typ, from val, the default value
+// of field. The defaults of time fields may be relative to the time of
+// binding, see relativeTime.
+func setDefaultValue(typ reflect.Type, val string, structField reflect.Value, field *fieldInfo) error {
+	if base := nestedType(typ); base == timeType || base == nullTimeType {
+		if t, ok, err := relativeTime(val, field.timeLocation); ok {
+			if err != nil {
+				return err
+			}
+			if typ.Kind() == reflect.Ptr {
+				structField.Set(reflect.New(base))
+				structField = structField.Elem()
+			}
+			if base == nullTimeType {
+				structField.Set(reflect.ValueOf(sql.NullTime{Time: t, Valid: true}))
+			} else {
+				structField.Set(reflect.ValueOf(t))
+			}
+			return nil
+		}
+	}
+	return setValue(val, typ, structField, field)
+}
+
+// relativeTime returns the time of val, a default value relative to the
+// time of binding, in loc, reporting whether val is one: now or today, its
+// midnight, optionally followed by an offset, a duration or a number of days,
+// e.g. now+24h, now-90m or today-7d.
+func relativeTime(val string, loc *time.Location) (time.Time, bool, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	base := timeNow().In(loc)
+	offset, ok := strings.CutPrefix(val, "now")
+	if !ok {
+		if offset, ok = strings.CutPrefix(val, "today"); !ok {
+			return time.Time{}, false, nil
+		}
+		base = time.Date(base.Year(), base.Month(), base.Day(), 0, 0, 0, 0, loc)
+	}
+	if offset == "" {
+		return base, true, nil
+	}
+	if offset[0] != '+' && offset[0] != '-' {
+		return time.Time{}, true, fmt.Errorf("invalid relative time %q", val)
+	}
+	if days, ok := strings.CutSuffix(offset, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("invalid relative time %q", val)
+		}
+		return base.AddDate(0, 0, n), true, nil
+	}
+	d, err := time.ParseDuration(offset)
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	return base.Add(d), true, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForConditionalDefault struct {
+	Amount   string `form:"amount" default_if:"Currency=JPY:0|Currency=*:0.00"`
+	Currency string `form:"currency"`
+	Express  *bool  `form:"express"`
+	Days     int    `form:"days" default:"5" default_if:"Express=true:1"`
+}
+
+type FooStructForBadDefaultIf struct {
+	Amount   int `form:"amount" default_if:"Currency=JPY:zero"`
+	Currency string
+}
+
+type FooStructForUnknownDefaultIf struct {
+	Amount int `form:"amount" default_if:"Kind=JPY:0"`
+}
+
+func TestMappingConditionalDefault(t *testing.T) {
+	for _, tt := range []struct {
+		form   map[string][]string
+		amount string
+		days   int
+	}{
+		{map[string][]string{"currency": {"JPY"}}, "0", 5},
+		{map[string][]string{"currency": {"USD"}, "express": {"true"}}, "0.00", 1},
+		{map[string][]string{"express": {"false"}}, "0.00", 5},
+		{map[string][]string{"currency": {"JPY"}, "amount": {"12"}, "days": {"3"}}, "12", 3},
+	} {
+		var obj FooStructForConditionalDefault
+		assert.NoError(t, mapForm(&obj, tt.form))
+		assert.Equal(t, tt.amount, obj.Amount)
+		assert.Equal(t, tt.days, obj.Days)
+	}
+
+	var obj FooStructForConditionalDefault
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"currency": "JPY", "express": true}))
+	assert.Equal(t, "0", obj.Amount)
+	assert.Equal(t, 1, obj.Days)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadDefaultIf]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForUnknownDefaultIf]() })
+}
+
+type FooStructForRelativeDefault struct {
+	CreatedAt time.Time  `form:"created_at" default:"now" time_utc:"1"`
+	ExpiresAt *time.Time `form:"expires_at" default:"now+24h" time_utc:"1"`
+	Since     time.Time  `form:"since" default_if:"Recent=true:now-1h" time_utc:"1"`
+	Recent    bool       `form:"recent"`
+}
+
+type FooStructForBadRelativeDefault struct {
+	At time.Time `form:"at" default:"now+1 day"`
+}
+
+func TestMappingRelativeDefault(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	var obj FooStructForRelativeDefault
+	assert.NoError(t, mapForm(&obj, map[string][]string{"recent": {"true"}}))
+	assert.Equal(t, now, obj.CreatedAt)
+	assert.Equal(t, now.Add(24*time.Hour), *obj.ExpiresAt)
+	assert.Equal(t, now.Add(-time.Hour), obj.Since)
+
+	obj = FooStructForRelativeDefault{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"created_at": {"2024-01-01T00:00:00Z"}}))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), obj.CreatedAt)
+	assert.True(t, obj.Since.IsZero())
+
+	err := mapForm(&obj, map[string][]string{"created_at": {"now"}})
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadRelativeDefault]() })
+}
+
+type FooStructForTimeDefaults struct {
+	From  *time.Time    `form:"from" default:"today-7d" time_utc:"1"`
+	To    sql.NullTime  `form:"to" default:"today" time_utc:"1"`
+	Since *sql.NullTime `form:"since" default:"01/02/2024" time_format:"02/01/2006" time_utc:"1"`
+	Until sql.NullTime  `form:"until" time_format:"dateonly" time_utc:"1"`
+}
+
+func TestMappingTimeDefaults(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	today := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	var obj FooStructForTimeDefaults
+	assert.NoError(t, mapForm(&obj, map[string][]string{}))
+	assert.Equal(t, today.AddDate(0, 0, -7), *obj.From)
+	assert.Equal(t, sql.NullTime{Time: today, Valid: true}, obj.To)
+	assert.Equal(t, sql.NullTime{Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Valid: true}, *obj.Since)
+	assert.False(t, obj.Until.Valid)
+
+	obj = FooStructForTimeDefaults{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"until": {"2024-04-01"}, "to": {""}}))
+	assert.Equal(t, sql.NullTime{Time: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Valid: true}, obj.Until)
+	assert.False(t, obj.To.Valid)
+
+	var dst struct {
+		Until string    `form:"until" time_format:"dateonly"`
+		To    time.Time `form:"to"`
+	}
+	assert.NoError(t, Copy(&dst, obj))
+	assert.Equal(t, "2024-04-01", dst.Until)
+	assert.True(t, dst.To.IsZero())
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// derivation computes a field from the other fields of its struct once it is
+// bound, by calling a method of the struct named by the derive tag of the
+// field, e.g.
+//
+//	Email           string `form:"email"`
+//	NormalizedEmail string `form:"-" derive:"method=NormalizeEmail"`
+//
+//	func (f *SignupForm) NormalizeEmail() string
+//
+// The method may also return an error, failing the bind.
+type derivation struct {
+	index int
+	name  string
+	// method is the index of the method in the method set of the pointer
+	// to the struct.
+	method int
+}
+
+func compileDerivation(typ reflect.Type, typeField reflect.StructField) (*derivation, error) {
+	tag := typeField.Tag.Get("derive")
+	if tag == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(tag, "method=") || len(tag) == len("method=") {
+		return nil, fmt.Errorf("invalid derive %q", tag)
+	}
+	name := tag[len("method="):]
+	method, ok := reflect.PtrTo(typ).MethodByName(name)
+	if !ok {
+		return nil, fmt.Errorf("no method %s to derive from", name)
+	}
+	mt := method.Type
+	valid := mt.NumIn() == 1 && mt.NumOut() >= 1 && mt.NumOut() <= 2 &&
+		mt.Out(0).AssignableTo(typeField.Type) &&
+		(mt.NumOut() == 1 || mt.Out(1) == errorType)
+	if !valid {
+		return nil, errors.New("derive method must return the field type and optionally an error")
+	}
+	return &derivation{index: typeField.Index[0], name: typeField.Name, method: method.Index}, nil
+}
+
+// derive computes the derived fields of obj.
+func derive(obj interface{}) error {
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return deriveStruct(val.Elem(), "")
+}
+
+func deriveStruct(val reflect.Value, path string) error {
+	info, err := cachedStageInfo(val.Type(), "")
+	if err != nil {
+		return err
+	}
+	for _, field := range info.fields {
+		if field.nested {
+			nested := nestedValue(val.Field(field.index))
+			if !nested.IsValid() {
+				continue
+			}
+			if err := deriveStruct(nested, joinPath(path, field.name)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, d := range info.derived {
+		out := val.Addr().Method(d.method).Call(nil)
+		if len(out) == 2 && !out[1].IsNil() {
+			fieldPath := joinPath(path, d.name)
+			return &FieldError{Path: fieldPath, Key: fieldPath, Err: out[1].Interface().(error)}
+		}
+		val.Field(d.index).Set(out[0])
+	}
+	return nil
+}
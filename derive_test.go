@@ -0,0 +1,94 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForDerive struct {
+	Email           string `form:"email"`
+	First           string `form:"first"`
+	Last            string `form:"last"`
+	NormalizedEmail string `form:"-" derive:"method=NormalizeEmail"`
+	FullName        string `form:"-" derive:"method=JoinName" binding:"required"`
+}
+
+func (f *FooStructForDerive) NormalizeEmail() string {
+	return strings.ToLower(strings.TrimSpace(f.Email))
+}
+
+func (f *FooStructForDerive) JoinName() (string, error) {
+	if f.First == "" && f.Last == "" {
+		return "", errors.New("no name")
+	}
+	return strings.TrimSpace(f.First + " " + f.Last), nil
+}
+
+type FooStructForBadDerive struct {
+	Name string `derive:"method=Missing"`
+}
+
+type FooStructForBadDeriveType struct {
+	Count int `derive:"method=Name"`
+}
+
+func (f *FooStructForBadDeriveType) Name() string {
+	return ""
+}
+
+func TestDerive(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?email=+Ada@Example.COM&first=Ada&last=Lovelace", nil)
+	var obj FooStructForDerive
+	assert.NoError(t, Form.Bind(req, &obj))
+	assert.Equal(t, "ada@example.com", obj.NormalizedEmail)
+	assert.Equal(t, "Ada Lovelace", obj.FullName)
+
+	req = requestWithBody("POST", "/", `{"email": "Bob@Example.com", "first": "Bob"}`)
+	obj = FooStructForDerive{}
+	assert.NoError(t, JSON.Bind(req, &obj))
+	assert.Equal(t, "bob@example.com", obj.NormalizedEmail)
+	assert.Equal(t, "Bob", obj.FullName)
+}
+
+func TestDeriveFail(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?email=a@b.c", nil)
+	err := Form.Bind(req, &FooStructForDerive{})
+	assert.EqualError(t, err, `binding: field "FullName": no name`)
+}
+
+func TestBadDeriveTag(t *testing.T) {
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadDerive]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadDeriveType]() })
+}
+
+type fooDeriveMixin struct {
+	Email           string `form:"email"`
+	NormalizedEmail string `form:"-" derive:"method=NormalizeEmail"`
+}
+
+func (f *fooDeriveMixin) NormalizeEmail() string {
+	return strings.ToLower(f.Email)
+}
+
+type FooStructForUnexportedDerive struct {
+	fooDeriveMixin
+}
+
+func TestDeriveUnexportedEmbedded(t *testing.T) {
+	req := requestWithBody("POST", "/", "email=Ada@Example.com")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	var obj FooStructForUnexportedDerive
+	assert.NotPanics(t, func() {
+		err := FormPost.Bind(req, &obj)
+		assert.EqualError(t, err, "binding: FooStructForUnexportedDerive.fooDeriveMixin: derive tag needs an exported embedded struct")
+	})
+	assert.Panics(t, func() { MustValidateStruct[FooStructForUnexportedDerive]() })
+}
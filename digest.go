@@ -0,0 +1,116 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ErrDigestMismatch is returned when VerifyDigest is set and the request body
+// doesn't match its Content-MD5 or Digest header.
+var ErrDigestMismatch = errors.New("binding: request body does not match its digest")
+
+// digestAlgorithms are the Digest header algorithms bodies can be verified
+// against, by lower case name.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"md5":     md5.New,
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+type bodyHash struct {
+	hash     hash.Hash
+	expected []byte
+}
+
+// bodyDigest hashes a request body as the binding reads it.
+type bodyDigest struct {
+	body   io.ReadCloser
+	hashes []bodyHash
+}
+
+func (d *bodyDigest) Read(p []byte) (int, error) {
+	n, err := d.body.Read(p)
+	for _, h := range d.hashes {
+		h.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+func (d *bodyDigest) Close() error {
+	return d.body.Close()
+}
+
+// watchDigest replaces the body of req by a reader hashing it, if the
+// VerifyDigest option is set and req has a Content-MD5 or Digest header with
+// a supported algorithm.
+func (s *bindState) watchDigest(req *http.Request) error {
+	if !s.opts.VerifyDigest || req.Body == nil {
+		return nil
+	}
+	hashes, err := parseDigestHeaders(req.Header)
+	if err != nil || len(hashes) == 0 {
+		return err
+	}
+	s.digest = &bodyDigest{body: req.Body, hashes: hashes}
+	req.Body = s.digest
+	return nil
+}
+
+func parseDigestHeaders(header http.Header) ([]bodyHash, error) {
+	var hashes []bodyHash
+	if value := header.Get("Content-MD5"); value != "" {
+		expected, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("binding: invalid Content-MD5 header: %v", err)
+		}
+		hashes = append(hashes, bodyHash{hash: md5.New(), expected: expected})
+	}
+	for _, value := range header["Digest"] {
+		for _, digest := range strings.Split(value, ",") {
+			parts := strings.SplitN(strings.TrimSpace(digest), "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("binding: invalid Digest header %q", value)
+			}
+			newHash, ok := digestAlgorithms[strings.ToLower(parts[0])]
+			if !ok {
+				continue
+			}
+			expected, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("binding: invalid Digest header: %v", err)
+			}
+			hashes = append(hashes, bodyHash{hash: newHash(), expected: expected})
+		}
+	}
+	return hashes, nil
+}
+
+// verify reads the rest of the body and checks it against its digests.
+func (d *bodyDigest) verify() error {
+	if d == nil {
+		return nil
+	}
+	if _, err := io.Copy(ioutil.Discard, d); err != nil {
+		return err
+	}
+	for _, h := range d.hashes {
+		if !bytes.Equal(h.hash.Sum(nil), h.expected) {
+			return ErrDigestMismatch
+		}
+	}
+	return nil
+}
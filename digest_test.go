@@ -0,0 +1,59 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDigest(t *testing.T) {
+	defer withOptions(Options{VerifyDigest: true})()
+
+	body := `{"foo": "bar"}` + "\n\n"
+	md5Sum := md5.Sum([]byte(body))
+	sha256Sum := sha256.Sum256([]byte(body))
+
+	obj := FooStruct{}
+	req := requestWithBody("POST", "/", body)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	assert.NoError(t, JSON.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+
+	req = requestWithBody("POST", "/", body)
+	req.Header.Set("Digest", "UNIXsum=30637, SHA-256="+base64.StdEncoding.EncodeToString(sha256Sum[:]))
+	assert.NoError(t, JSON.Bind(req, &obj))
+
+	req = requestWithBody("POST", "/", body+" ")
+	req.Header.Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sha256Sum[:]))
+	assert.Equal(t, ErrDigestMismatch, JSON.Bind(req, &obj))
+
+	req = requestWithBody("POST", "/", body)
+	req.Header.Set("Content-MD5", "not base64!")
+	assert.Error(t, JSON.Bind(req, &obj))
+
+	formBody := "foo=bar&bar=foo"
+	md5Sum = md5.Sum([]byte(formBody))
+	req = requestWithBody("POST", "/", formBody)
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	assert.NoError(t, FormPost.Bind(req, &FooBarStruct{}))
+
+	req = requestWithBody("POST", "/", formBody+"&baz=1")
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	assert.Equal(t, ErrDigestMismatch, FormPost.Bind(req, &FooBarStruct{}))
+}
+
+func TestVerifyDigestDisabled(t *testing.T) {
+	obj := FooStruct{}
+	req := requestWithBody("POST", "/", `{"foo": "bar"}`)
+	req.Header.Set("Content-MD5", "AAAAAAAAAAAAAAAAAAAAAA==")
+	assert.NoError(t, JSON.Bind(req, &obj))
+}
@@ -0,0 +1,322 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxSliceIndex is the default of Options.MaxSliceIndex.
+const defaultMaxSliceIndex = 1000
+
+// defaultMaxDepth is the default of Options.MaxDepth.
+const defaultMaxDepth = 32
+
+// maxSliceIndex returns the MaxSliceIndex option, or its default.
+func (o *Options) maxSliceIndex() int {
+	if o.MaxSliceIndex <= 0 {
+		return defaultMaxSliceIndex
+	}
+	return o.MaxSliceIndex
+}
+
+// keySeparator returns the KeySeparator option, or its default.
+func (o *Options) keySeparator() string {
+	if o.KeySeparator == "" {
+		return "."
+	}
+	return o.KeySeparator
+}
+
+// maxDepth returns the MaxDepth option, or its default.
+func (o *Options) maxDepth() int {
+	if o.MaxDepth <= 0 {
+		return defaultMaxDepth
+	}
+	return o.MaxDepth
+}
+
+// dotted reports whether fields of typ, the type of a field bound from key,
+// are bound from keys in dot or bracket notation, e.g. user.address.city=Oslo
+// or user[address][city]=Oslo for the key user, rather than from a JSON
+// object: typ is a struct, or a pointer to one, decoded as JSON.
+func dotted(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && !isOrderedMap(typ) && decodedAsJSON(typ)
+}
+
+// subKey returns the key of its own key names after prefix: the rest of key
+// after the separator sep, or after its first bracketed name, which is
+// unwrapped, e.g. address[city] for user[address][city] with the prefix
+// user. Names keep their escapes, see unescapeKey.
+func subKey(key, prefix, sep string) (string, bool) {
+	if !strings.HasPrefix(key, prefix) {
+		return "", false
+	}
+	rest := key[len(prefix):]
+	switch {
+	case len(rest) > len(sep) && strings.HasPrefix(rest, sep):
+		return rest[len(sep):], true
+	case len(rest) > 2 && rest[0] == '[':
+		end := closingBracket(rest)
+		if end < 2 {
+			return "", false
+		}
+		return rest[1:end] + rest[end+1:], true
+	}
+	return "", false
+}
+
+// closingBracket returns the index of the first unescaped ] of key, -1 if
+// none.
+func closingBracket(key string) int {
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeKey returns the name key with its escaped brackets, backslashes
+// and separators sep unescaped, e.g. config.yaml for config\.yaml, and
+// reports whether it has no unescaped brackets.
+func unescapeKey(key, sep string) (string, bool) {
+	if !strings.Contains(key, "\\") {
+		if strings.ContainsAny(key, "[]") {
+			return "", false
+		}
+		return key, true
+	}
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c == '[' || c == ']':
+			return "", false
+		case c != '\\' || i+1 == len(key):
+			b.WriteByte(c)
+		case key[i+1] == '\\' || key[i+1] == '[' || key[i+1] == ']':
+			b.WriteByte(key[i+1])
+			i++
+		case strings.HasPrefix(key[i+1:], sep):
+			b.WriteString(sep)
+			i += len(sep)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), true
+}
+
+// subForm returns the values of the keys of form split turns into a key of
+// their own, by that key. keys, if not nil, maps them back to the keys of
+// form.
+func subForm[V any](form map[string]V, split func(string) (string, bool), keys map[string]string) map[string]V {
+	var sub map[string]V
+	for key, values := range form {
+		if k, ok := split(key); ok {
+			if sub == nil {
+				sub = make(map[string]V)
+			}
+			sub[k] = values
+			if keys != nil {
+				keys[k] = key
+			}
+		}
+	}
+	return sub
+}
+
+// mapNested binds the struct field from the keys naming its fields in dot
+// or bracket notation, or the slice of structs field from the keys naming
+// the fields of its elements, see elems. It reports whether there are such
+// keys, and the key of the field has no values itself.
+func (m *formMapper) mapNested(field *fieldInfo, typ reflect.Type, structField reflect.Value, path string) (bool, error) {
+	if len(m.form[field.key]) > 0 || len(m.files(field.key)) > 0 {
+		return false, nil
+	}
+	if dotted(typ) {
+		sub := m.sub(field.key)
+		if sub.form == nil {
+			return false, nil
+		}
+		if structField.Kind() == reflect.Ptr {
+			if structField.IsNil() {
+				structField.Set(reflect.New(typ.Elem()))
+			}
+			structField = structField.Elem()
+		}
+		return true, m.mapSub(sub, structField, m.keyPath(path, field.key))
+	}
+	if typ.Kind() != reflect.Slice || !dotted(typ.Elem()) {
+		return false, nil
+	}
+	elems, indexed, err := m.elems(field.key, path)
+	if elems == nil || err != nil {
+		return err != nil, err
+	}
+	slice := structField
+	if !indexed {
+		slice = reflect.MakeSlice(typ, 0, len(elems))
+	}
+	if n := len(elems); slice.Len() < n {
+		slice = reflect.AppendSlice(slice, reflect.MakeSlice(typ, n-slice.Len(), n-slice.Len()))
+	}
+	for i, elem := range elems {
+		if elem == nil {
+			continue
+		}
+		value := slice.Index(i)
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(typ.Elem().Elem()))
+			}
+			value = value.Elem()
+		}
+		if err := m.mapSub(elem, value, fmt.Sprintf("%s[%d]", m.keyPath(path, field.key), i)); err != nil {
+			return true, err
+		}
+	}
+	structField.Set(slice)
+	return true, nil
+}
+
+// elems returns the mappers of the elements of the slice bound from key,
+// from the keys naming the fields of its elements after their index, e.g.
+// items.0.name=a or items[0][name]=a, nil for elements without keys, and
+// reports whether there are such keys. Otherwise, it returns the mappers of
+// the elements bound from the keys naming their fields after empty brackets,
+// e.g. items[][name]=a&items[][name]=b, the i-th value of each key going to
+// the i-th element. Indices above the MaxSliceIndex option fail with an
+// *IndexError.
+func (m *formMapper) elems(key, path string) ([]*formMapper, bool, error) {
+	maxIndex := m.state.opts.maxSliceIndex()
+	sep := m.state.opts.keySeparator()
+	sub := m.sub(key)
+	var elems []*formMapper
+	for _, k := range sortedKeys(sub.form) {
+		digits := len(k) - len(strings.TrimLeft(k, "0123456789"))
+		rest, ok := subKey(k, k[:digits], sep)
+		if digits == 0 || !ok {
+			continue
+		}
+		i, err := strconv.Atoi(k[:digits])
+		if err != nil || i > maxIndex {
+			return nil, true, &FieldError{
+				Path: fmt.Sprintf("%s[%s]", m.keyPath(path, key), k[:digits]),
+				Key:  m.inputKey(sub.keys[k]),
+				Err:  &IndexError{Index: k[:digits], Max: maxIndex},
+			}
+		}
+		for len(elems) <= i {
+			elems = append(elems, nil)
+		}
+		if elems[i] == nil {
+			elems[i] = &formMapper{
+				form:     make(map[string][]string),
+				tag:      m.tag,
+				state:    m.state,
+				parent:   m,
+				keys:     make(map[string]string),
+				fileForm: subForm(sub.fileForm, func(f string) (string, bool) { return subKey(f, k[:digits], sep) }, nil),
+				prefix:   key + sep + k[:digits] + sep,
+			}
+		}
+		elems[i].form[rest] = sub.form[k]
+		elems[i].keys[rest] = sub.keys[k]
+	}
+	if elems != nil {
+		return elems, true, nil
+	}
+
+	sub = m.sub(key + "[]")
+	n := 0
+	for _, values := range sub.form {
+		if len(values) > n {
+			n = len(values)
+		}
+	}
+	for i := 0; i < n; i++ {
+		elem := *sub
+		elem.form = make(map[string][]string, len(sub.form))
+		for k, values := range sub.form {
+			if i < len(values) {
+				elem.form[k] = values[i : i+1]
+			}
+		}
+		elem.fileForm = make(map[string][]*UploadedFile, len(sub.fileForm))
+		for k, files := range sub.fileForm {
+			if i < len(files) {
+				elem.fileForm[k] = files[i : i+1]
+			}
+		}
+		elems = append(elems, &elem)
+	}
+	return elems, false, nil
+}
+
+// sub returns the mapper of the keys of the form and files of m naming a key
+// of their own after prefix, see subKey.
+func (m *formMapper) sub(prefix string) *formMapper {
+	sep := m.state.opts.keySeparator()
+	return m.subMapper(prefix+sep, func(key string) (string, bool) {
+		return subKey(key, prefix, sep)
+	})
+}
+
+// prefixed returns the mapper of the keys of the form and files of m starting
+// with prefix, with prefix removed.
+func (m *formMapper) prefixed(prefix string) *formMapper {
+	return m.subMapper(prefix, func(key string) (string, bool) {
+		k, ok := strings.CutPrefix(key, prefix)
+		return k, ok && k != ""
+	})
+}
+
+// subMapper returns the mapper of the keys of the form and files of m split
+// turns into a key of their own. keyPrefix is the prefix the keys missing
+// from the form are assumed to have in the form of m.
+func (m *formMapper) subMapper(keyPrefix string, split func(string) (string, bool)) *formMapper {
+	sub := &formMapper{tag: m.tag, state: m.state, parent: m, keys: make(map[string]string), prefix: keyPrefix}
+	sub.form = subForm(m.form, split, sub.keys)
+	if m.parent == nil {
+		sub.fileForm = subForm(m.state.files, split, sub.keys)
+	} else {
+		sub.fileForm = subForm(m.fileForm, split, sub.keys)
+	}
+	return sub
+}
+
+// mapSub maps the form of sub onto val, merging its state back into m. Subs
+// nested deeper than the MaxDepth option fail with a *LimitError.
+func (m *formMapper) mapSub(sub *formMapper, val reflect.Value, path string) error {
+	maxDepth := m.state.opts.maxDepth()
+	depth := 0
+	for p := sub; p.parent != nil; p = p.parent {
+		depth++
+	}
+	if depth > maxDepth {
+		return m.state.limitError(LimitDepth, int64(maxDepth), int64(depth))
+	}
+	sub.seq = m.seq
+	if m.used != nil {
+		sub.used = make(map[string]bool, len(sub.form))
+	}
+	err := sub.mapStruct(val, path)
+	m.jobs = append(m.jobs, sub.jobs...)
+	m.seq, m.errs = sub.seq, append(m.errs, sub.errs...)
+	for key := range sub.used {
+		m.markUsed(sub.keys[key])
+	}
+	return err
+}
@@ -0,0 +1,340 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForAddress struct {
+	City string `form:"city" json:"city"`
+	Zip  int    `form:"zip" json:"zip"`
+}
+
+type FooStructForDotted struct {
+	Name string `form:"name"`
+	User struct {
+		Email   string               `form:"email"`
+		Address FooStructForAddress  `form:"address"`
+		Billing *FooStructForAddress `form:"billing"`
+		Avatar  *UploadedFile        `form:"avatar"`
+	} `form:"user"`
+	Shipping *FooStructForAddress    `form:"shipping"`
+	Parent   *FooStructForDottedNode `form:"parent"`
+}
+
+type FooStructForDottedNode struct {
+	ID     int                     `form:"id"`
+	Parent *FooStructForDottedNode `form:"parent"`
+}
+
+func TestMappingDotted(t *testing.T) {
+	var obj FooStructForDotted
+	err := mapForm(&obj, map[string][]string{
+		"name":              {"manu"},
+		"user.email":        {"manu@example.com"},
+		"user.address.city": {"Oslo"},
+		"user.address.zip":  {"150"},
+		"shipping":          {`{"city": "Bergen"}`},
+		"parent.id":         {"1"},
+		"parent.parent.id":  {"2"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "manu", obj.Name)
+	assert.Equal(t, "manu@example.com", obj.User.Email)
+	assert.Equal(t, FooStructForAddress{City: "Oslo", Zip: 150}, obj.User.Address)
+	assert.Nil(t, obj.User.Billing)
+	if assert.NotNil(t, obj.Shipping) {
+		assert.Equal(t, "Bergen", obj.Shipping.City)
+	}
+	if assert.NotNil(t, obj.Parent) && assert.NotNil(t, obj.Parent.Parent) {
+		assert.Equal(t, 1, obj.Parent.ID)
+		assert.Equal(t, 2, obj.Parent.Parent.ID)
+		assert.Nil(t, obj.Parent.Parent.Parent)
+	}
+
+	err = mapForm(&obj, map[string][]string{"user.billing.zip": {"x"}})
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "user.billing.zip", fieldErr.Path)
+		assert.Equal(t, "user.billing.zip", fieldErr.Key)
+	}
+}
+
+func TestMappingDottedDepth(t *testing.T) {
+	key := "id"
+	for i := 0; i < defaultMaxDepth; i++ {
+		key = "parent." + key
+	}
+	var obj FooStructForDotted
+	assert.NoError(t, mapForm(&obj, map[string][]string{key: {"1"}}))
+	err := mapForm(&obj, map[string][]string{"parent." + key: {"1"}})
+	assert.EqualError(t, err, "binding: depth limit exceeded: 33 > 32")
+}
+
+func TestMappingDottedWarnings(t *testing.T) {
+	req := requestWithBody("GET", "/?user.email=a&user.address.town=Oslo", "")
+	var obj FooStructForDotted
+	warnings, err := BindWithWarnings(req, &obj, Query)
+	assert.NoError(t, err)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, "user.address.town", warnings[0].Key)
+	}
+}
+
+func testBindDottedMultipart(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("user.address.city", "Oslo")
+	mw.WriteField("user.other", "x")
+	w, _ := mw.CreateFormFile("user.avatar", "me.png")
+	w.Write([]byte("avatar content"))
+	mw.Close()
+	req, _ := http.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var obj FooStructForDotted
+	assert.NoError(t, FormMultipart.Bind(req, &obj))
+	assert.Equal(t, "Oslo", obj.User.Address.City)
+	if assert.NotNil(t, obj.User.Avatar) {
+		assert.Equal(t, "me.png", obj.User.Avatar.Filename)
+	}
+}
+
+func TestBindDottedMultipart(t *testing.T) {
+	testBindDottedMultipart(t)
+}
+
+func TestBindDottedMultipartLazy(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+	testBindDottedMultipart(t)
+}
+
+type FooStructForBrackets struct {
+	User struct {
+		Address FooStructForAddress `form:"address"`
+	} `form:"user"`
+	Items []struct {
+		Name    string               `form:"name"`
+		Price   int                  `form:"price"`
+		Address *FooStructForAddress `form:"address"`
+	} `form:"items"`
+	Ptrs []*FooStructForAddress `form:"ptrs"`
+	Meta map[string]string      `form:"meta"`
+}
+
+func TestMappingBrackets(t *testing.T) {
+	var obj FooStructForBrackets
+	err := mapForm(&obj, map[string][]string{
+		"user[address][city]":    {"Oslo"},
+		"user[address].zip":      {"150"},
+		"items[][name]":          {"a", "b"},
+		"items[][price]":         {"1", "2"},
+		"items[][address][city]": {"Bergen"},
+		"ptrs[][city]":           {"Tromsø"},
+		"meta[env]":              {"prod"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, FooStructForAddress{City: "Oslo", Zip: 150}, obj.User.Address)
+	if assert.Len(t, obj.Items, 2) {
+		assert.Equal(t, "a", obj.Items[0].Name)
+		assert.Equal(t, 1, obj.Items[0].Price)
+		assert.Equal(t, "Bergen", obj.Items[0].Address.City)
+		assert.Equal(t, "b", obj.Items[1].Name)
+		assert.Equal(t, 2, obj.Items[1].Price)
+		assert.Nil(t, obj.Items[1].Address)
+	}
+	if assert.Len(t, obj.Ptrs, 1) {
+		assert.Equal(t, "Tromsø", obj.Ptrs[0].City)
+	}
+	assert.Equal(t, map[string]string{"env": "prod"}, obj.Meta)
+
+	obj = FooStructForBrackets{}
+	err = mapForm(&obj, map[string][]string{"items": {`[{"name": "json"}]`}, "items[][name]": {"x"}})
+	assert.NoError(t, err)
+	if assert.Len(t, obj.Items, 1) {
+		assert.Equal(t, "json", obj.Items[0].Name)
+	}
+
+	err = mapForm(&obj, map[string][]string{"items[][name]": {"a", "b"}, "items[][price]": {"1", "x"}})
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "items[1].price", fieldErr.Path)
+		assert.Equal(t, "items[][price]", fieldErr.Key)
+	}
+}
+
+func TestBindBracketsWarnings(t *testing.T) {
+	req := requestWithBody("GET", "/?user[address][city]=Oslo&items[][name]=a&items[][nope]=b", "")
+	var obj FooStructForBrackets
+	warnings, err := BindWithWarnings(req, &obj, Query)
+	assert.NoError(t, err)
+	assert.Equal(t, "Oslo", obj.User.Address.City)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, "items[][nope]", warnings[0].Key)
+	}
+}
+
+func TestBindBracketsMultipartLazy(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("user[address][city]", "Oslo")
+	mw.WriteField("items[][name]", "a")
+	mw.WriteField("items[][name]", "b")
+	mw.Close()
+	req, _ := http.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var obj FooStructForBrackets
+	assert.NoError(t, FormMultipart.Bind(req, &obj))
+	assert.Equal(t, "Oslo", obj.User.Address.City)
+	assert.Len(t, obj.Items, 2)
+}
+
+func TestSubKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"user.city":           "city",
+		"user[city]":          "city",
+		"user[address][city]": "address[city]",
+		"user[address].city":  "address.city",
+		"user[]":              "",
+		"user[city":           "",
+		"username":            "",
+		"user.":               "",
+		`user[a\]b].c`:        `a\]b.c`,
+		`user\.city`:          "",
+	} {
+		got, ok := subKey(key, "user", ".")
+		assert.Equal(t, want != "", ok, key)
+		assert.Equal(t, want, got, key)
+	}
+	got, ok := subKey("user__address__city", "user", "__")
+	assert.True(t, ok)
+	assert.Equal(t, "address__city", got)
+}
+
+func TestUnescapeKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"env":          "env",
+		`config\.yaml`: "config.yaml",
+		`a\[0\]`:       "a[0]",
+		`back\\slash`:  `back\slash`,
+		`trailing\`:    `trailing\`,
+		`other\n`:      `other\n`,
+		"a[0]":         "",
+		`a\\[0]`:       "",
+	} {
+		got, ok := unescapeKey(key, ".")
+		assert.Equal(t, want != "", ok, key)
+		assert.Equal(t, want, got, key)
+	}
+	got, ok := unescapeKey(`a\__b`, "__")
+	assert.True(t, ok)
+	assert.Equal(t, "a__b", got)
+}
+
+type FooStructForEscapedKeys struct {
+	Files map[string]string       `form:"files"`
+	User  FooStructForEscapedUser `form:"user"`
+}
+
+type FooStructForEscapedUser struct {
+	Name string            `form:"name"`
+	Meta map[string]string `form:"meta"`
+}
+
+func TestMappingEscapedKeys(t *testing.T) {
+	var obj FooStructForEscapedKeys
+	assert.NoError(t, mapForm(&obj, map[string][]string{
+		`files.config\.yaml`:     {"a"},
+		`files[b\]c]`:            {"b"},
+		`files.d\[0\]`:           {"c"},
+		`files.e[0]`:             {"ignored"},
+		`user.meta.config\.yaml`: {"d"},
+		`user[meta][x\]\[y]`:     {"e"},
+		`user.name`:              {"ada"},
+	}))
+	assert.Equal(t, map[string]string{"config.yaml": "a", "b]c": "b", "d[0]": "c"}, obj.Files)
+	assert.Equal(t, map[string]string{"config.yaml": "d", "x][y": "e"}, obj.User.Meta)
+	assert.Equal(t, "ada", obj.User.Name)
+
+	defer withOptions(Options{KeySeparator: "__"})()
+	obj = FooStructForEscapedKeys{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{
+		"files__config.yaml": {"a"},
+		`user__meta__a\__b`:  {"b"},
+		"user__name":         {"ada"},
+		"user.name":          {"ignored"},
+	}))
+	assert.Equal(t, map[string]string{"config.yaml": "a"}, obj.Files)
+	assert.Equal(t, map[string]string{"a__b": "b"}, obj.User.Meta)
+	assert.Equal(t, "ada", obj.User.Name)
+}
+
+type FooStructForIndexed struct {
+	Items []struct {
+		Name string `form:"name"`
+		Qty  int    `form:"qty"`
+	} `form:"items"`
+	Ptrs []*FooStructForAddress `form:"ptrs"`
+}
+
+func TestMappingIndexed(t *testing.T) {
+	var obj FooStructForIndexed
+	err := mapForm(&obj, map[string][]string{
+		"items.0.name":   {"a"},
+		"items.0.qty":    {"2"},
+		"items[2][name]": {"c"},
+		"ptrs.1.city":    {"Oslo"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, obj.Items, 3) {
+		assert.Equal(t, "a", obj.Items[0].Name)
+		assert.Equal(t, 2, obj.Items[0].Qty)
+		assert.Equal(t, "", obj.Items[1].Name)
+		assert.Equal(t, "c", obj.Items[2].Name)
+	}
+	if assert.Len(t, obj.Ptrs, 2) {
+		assert.Nil(t, obj.Ptrs[0])
+		assert.Equal(t, "Oslo", obj.Ptrs[1].City)
+	}
+
+	err = mapForm(&obj, map[string][]string{"items.1.qty": {"5"}})
+	assert.NoError(t, err)
+	if assert.Len(t, obj.Items, 3) {
+		assert.Equal(t, "a", obj.Items[0].Name)
+		assert.Equal(t, 5, obj.Items[1].Qty)
+	}
+
+	err = mapForm(&obj, map[string][]string{"items.1.qty": {"x"}})
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "items[1].qty", fieldErr.Path)
+		assert.Equal(t, "items.1.qty", fieldErr.Key)
+	}
+}
+
+func TestMappingIndexedBound(t *testing.T) {
+	var obj FooStructForIndexed
+	err := mapForm(&obj, map[string][]string{"items.1001.name": {"a"}})
+	var indexErr *IndexError
+	if assert.True(t, errors.As(err, &indexErr)) {
+		assert.Equal(t, "1001", indexErr.Index)
+		assert.Equal(t, 1000, indexErr.Max)
+	}
+	assert.EqualError(t, err, `binding: field "items[1001]" (key "items.1001.name"): index 1001 exceeds the maximum of 1000`)
+	assert.Error(t, mapForm(&obj, map[string][]string{"items.99999999999999999999.name": {"a"}}))
+
+	defer withOptions(Options{MaxSliceIndex: 3})()
+	assert.NoError(t, mapForm(&obj, map[string][]string{"items.3.name": {"a"}}))
+	assert.True(t, errors.As(mapForm(&obj, map[string][]string{"items.4.name": {"a"}}), &indexErr))
+}
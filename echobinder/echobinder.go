@@ -0,0 +1,37 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package echobinder provides an echo.Binder binding requests with the
+// binding package, e.g.
+//
+//	e := echo.New()
+//	e.Binder = echobinder.New(binding.DefaultOptions)
+package echobinder
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/ppltools/binding"
+)
+
+// Binder is an echo.Binder binding requests with a binding.Decoder.
+type Binder struct {
+	Decoder *binding.Decoder
+}
+
+var _ echo.Binder = (*Binder)(nil)
+
+// New returns a Binder using opts.
+func New(opts binding.Options) *Binder {
+	return &Binder{Decoder: binding.NewDecoder(opts)}
+}
+
+// Bind binds the request of c onto i. Binding errors are returned as an
+// *echo.HTTPError wrapping the binding error, with the status given by
+// binding.StatusCode, e.g. 400 Bad Request.
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	if err := b.Decoder.Bind(c.Request(), i); err != nil {
+		return echo.NewHTTPError(binding.StatusCode(err), err.Error()).SetInternal(err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package echobinder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ppltools/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name string `json:"name" form:"name"`
+	Age  int    `json:"age" form:"age"`
+}
+
+func TestBinder(t *testing.T) {
+	e := echo.New()
+	e.Binder = New(binding.DefaultOptions)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "manu", "age": 30}`))
+	req.Header.Set("Content-Type", binding.MIMEJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+	var obj user
+	assert.NoError(t, c.Bind(&obj))
+	assert.Equal(t, user{Name: "manu", Age: 30}, obj)
+
+	req = httptest.NewRequest("GET", "/?age=x", nil)
+	c = e.NewContext(req, httptest.NewRecorder())
+	err := c.Bind(&obj)
+	if httpErr, ok := err.(*echo.HTTPError); assert.True(t, ok) {
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.IsType(t, &binding.FieldError{}, httpErr.Internal)
+	}
+}
+
+func TestBinderStatusCode(t *testing.T) {
+	e := echo.New()
+	e.Binder = New(binding.Options{MaxBodySize: 8})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "manu", "age": 30}`))
+	req.Header.Set("Content-Type", binding.MIMEJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+	err := c.Bind(&user{})
+	if httpErr, ok := err.(*echo.HTTPError); assert.True(t, ok) {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, httpErr.Code)
+		assert.IsType(t, &binding.LimitError{}, httpErr.Internal)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldError is returned when a value can't be bound to a struct field.
+type FieldError struct {
+	// Path is the full path of the field within the bound struct, built from
+	// the keys of the enclosing fields, e.g. "items[2].price". Nested structs
+	// bound from the same form add no segment, but their prefix tag.
+	Path string
+	// Key is the form key the value was read from.
+	Key string
+	// Err is the underlying conversion error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == e.Key {
+		return fmt.Sprintf("binding: field %q: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("binding: field %q (key %q): %v", e.Path, e.Key, e.Err)
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors is returned by the form bindings when values can't be bound to
+// several fields and the CollectFieldErrors option is set. The errors are in
+// struct field order, whatever the order of the input, so that they are
+// stable across runs.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors, so that errors.As finds the first one.
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// SortByPath sorts the errors by the path of their field.
+func (e FieldErrors) SortByPath() {
+	sort.SliceStable(e, func(i, j int) bool { return e[i].Path < e[j].Path })
+}
+
+// SortByKey sorts the errors by the key their value was read from.
+func (e FieldErrors) SortByKey() {
+	sort.SliceStable(e, func(i, j int) bool { return e[i].Key < e[j].Key })
+}
+
+// IndexError is returned when the index of a slice element in a key, e.g.
+// items.5000.name, exceeds Options.MaxSliceIndex.
+type IndexError struct {
+	// Index is the index in the key.
+	Index string
+	Max   int
+}
+
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("index %s exceeds the maximum of %d", e.Index, e.Max)
+}
+
+// RangeError is returned when a numeric value is outside of the range set by
+// the min and max tags of its field.
+type RangeError struct {
+	// Value is the raw input value.
+	Value string
+	// Min and Max are the raw min and max tags, empty when not set.
+	Min, Max string
+}
+
+func (e *RangeError) Error() string {
+	switch {
+	case e.Max == "":
+		return fmt.Sprintf("value %q is less than %s", e.Value, e.Min)
+	case e.Min == "":
+		return fmt.Sprintf("value %q is greater than %s", e.Value, e.Max)
+	default:
+		return fmt.Sprintf("value %q is out of range [%s, %s]", e.Value, e.Min, e.Max)
+	}
+}
+
+// StepError is returned when a time.Duration value isn't a multiple of the
+// step tag of its field.
+type StepError struct {
+	// Value is the raw input value.
+	Value string
+	// Step is the raw step tag.
+	Step string
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("value %q is not a multiple of %s", e.Value, e.Step)
+}
+
+// PatternError is returned when a string value doesn't match the pattern tag
+// of its field.
+type PatternError struct {
+	Value   string
+	Pattern string
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("value %q does not match pattern %q", e.Value, e.Pattern)
+}
+
+// LengthError is returned when a []byte value doesn't decode to the number of
+// bytes set by the len tag of its field.
+type LengthError struct {
+	// Value is the raw input value.
+	Value string
+	// Len is the number of bytes Value decodes to, Want the len tag.
+	Len, Want int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("value %q decodes to %d bytes, want %d", e.Value, e.Len, e.Want)
+}
+
+// CountError is returned when a key has more values than the array field, or
+// the slice field with a max_items tag, it is bound into holds, rather than
+// dropping the extra ones.
+type CountError struct {
+	// Received is the number of values of the key and Accepted the number
+	// of elements of the field.
+	Received, Accepted int
+	// Dropped are the values past Accepted, raw JSON for JSON arrays.
+	Dropped []string
+}
+
+func (e *CountError) Error() string {
+	return fmt.Sprintf("%d values received, %d accepted, dropped %q", e.Received, e.Accepted, e.Dropped)
+}
+
+// PermissionError is returned by BindWithPermissions when a field the caller
+// isn't granted is bound.
+type PermissionError struct {
+	// Path is the full path of the field within the bound struct.
+	Path string
+	// Perm is the perm tag of the field.
+	Perm string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("binding: field %q requires permission %q", e.Path, e.Perm)
+}
+
+// MapEntryError is returned when an entry of a map field can't be bound.
+type MapEntryError struct {
+	// Key is the raw key of the entry.
+	Key string
+	// Err is the underlying conversion error, of the key or of the value.
+	Err error
+}
+
+func (e *MapEntryError) Error() string {
+	return fmt.Sprintf("map key %q: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *MapEntryError) Unwrap() error {
+	return e.Err
+}
+
+// FileRejectedError is returned when Options.InspectFile vetoes a file.
+type FileRejectedError struct {
+	// Key is the form key of the file part.
+	Key string
+	// Filename is the name of the file sent by the client.
+	Filename string
+	// Err is the error returned by the inspector.
+	Err error
+}
+
+func (e *FileRejectedError) Error() string {
+	return fmt.Sprintf("binding: file %q of key %q rejected: %v", e.Filename, e.Key, e.Err)
+}
+
+// Unwrap returns the error returned by the inspector.
+func (e *FileRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// RecordError is yielded by BindEach for a record which can't be bound.
+type RecordError struct {
+	// Record is the number of the record, starting at 1 and not counting
+	// the header row of CSV.
+	Record int
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("binding: record %d: %v", e.Record, e.Err)
+}
+
+// Unwrap returns the error binding the record.
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// LimitError is returned when a request exceeds a limit of the binding. It
+// carries what gateways need to answer with a 413 Payload Too Large or a 429
+// Too Many Requests, see StatusCode.
+type LimitError struct {
+	// Limit is the limit exceeded, e.g. LimitBodySize.
+	Limit string
+	// Max is the value of the limit and Observed the value of the request,
+	// at least as far as it was read.
+	Max, Observed int64
+	// RetryAfter is the delay after which clients may retry, from
+	// Options.LimitRetryAfter, 0 for none.
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("binding: %s limit exceeded: %d > %d", e.Limit, e.Observed, e.Max)
+}
+
+// StatusCode returns the HTTP status of the error: 429 Too Many Requests for
+// count limits such as LimitFormValues when clients are told to retry, 413
+// Payload Too Large otherwise.
+func (e *LimitError) StatusCode() int {
+	if e.RetryAfter > 0 && e.Limit == LimitFormValues {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusRequestEntityTooLarge
+}
+
+// ContentTypeError is returned by Decoder.Bind when a request has a missing or
+// unrecognized content type and the ContentTypeReject policy is used.
+type ContentTypeError struct {
+	// ContentType is the Content-Type header of the request, empty if
+	// missing.
+	ContentType string
+	// Method is the method of the request and Accepted the media types it
+	// could have been sent as, see SetAcceptHeader.
+	Method   string
+	Accepted []string
+}
+
+func (e *ContentTypeError) Error() string {
+	if e.ContentType == "" {
+		return "binding: missing content type"
+	}
+	return fmt.Sprintf("binding: unsupported content type %q", e.ContentType)
+}
+
+// StatusCode returns the HTTP status of the error.
+func (e *ContentTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+// StatusCode returns the HTTP status of err: the status of the first error in
+// its chain with a StatusCode method, such as *LimitError, or 400 Bad Request.
+func StatusCode(err error) int {
+	var coded interface{ StatusCode() int }
+	if errors.As(err, &coded) {
+		return coded.StatusCode()
+	}
+	return http.StatusBadRequest
+}
+
+// joinPath appends a field name to a field path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
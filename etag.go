@@ -0,0 +1,108 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ETag is an entity tag, as sent in the ETag, If-Match and If-None-Match
+// headers, e.g. "xyzzy" or W/"xyzzy". It binds from header and form values
+// and, being an encoding.TextUnmarshaler, from JSON and XML strings. Lists of
+// tags bind into []ETag fields split with `collection_format:"csv"`, e.g.
+//
+//	IfNoneMatch []binding.ETag `header:"If-None-Match" collection_format:"csv"`
+type ETag struct {
+	// Tag is the opaque tag, without its quotes.
+	Tag  string
+	Weak bool
+	// Any is the * wildcard of If-Match and If-None-Match, matching every
+	// tag.
+	Any bool
+}
+
+// ParseETag parses an entity tag, quoted and prefixed with W/ if weak, or the
+// * wildcard.
+func ParseETag(s string) (ETag, error) {
+	var e ETag
+	rest := strings.TrimSpace(s)
+	if rest == "*" {
+		e.Any = true
+		return e, nil
+	}
+	if strings.HasPrefix(rest, "W/") {
+		e.Weak, rest = true, rest[2:]
+	}
+	if len(rest) < 2 || rest[0] != '"' || rest[len(rest)-1] != '"' {
+		return ETag{}, fmt.Errorf("invalid entity tag %q", s)
+	}
+	e.Tag = rest[1 : len(rest)-1]
+	for i := 0; i < len(e.Tag); i++ {
+		// etagc = %x21 / %x23-7E / obs-text
+		if c := e.Tag[i]; c == '"' || c < 0x21 || c == 0x7f {
+			return ETag{}, fmt.Errorf("invalid entity tag %q", s)
+		}
+	}
+	return e, nil
+}
+
+// IsZero reports whether e is the zero ETag, as bound from an absent value.
+func (e ETag) IsZero() bool {
+	return e == ETag{}
+}
+
+// String formats e as it is sent in headers, "" for the zero ETag.
+func (e ETag) String() string {
+	switch {
+	case e.Any:
+		return "*"
+	case e.IsZero():
+		return ""
+	case e.Weak:
+		return `W/"` + e.Tag + `"`
+	}
+	return `"` + e.Tag + `"`
+}
+
+// StrongMatch reports whether e and other match with the strong comparison
+// of If-Match: both are strong and have the same tag. The wildcard matches
+// every tag.
+func (e ETag) StrongMatch(other ETag) bool {
+	if e.Any || other.Any {
+		return !e.IsZero() && !other.IsZero()
+	}
+	return !e.Weak && !other.Weak && e.Tag == other.Tag && !e.IsZero()
+}
+
+// WeakMatch reports whether e and other match with the weak comparison of
+// If-None-Match: they have the same tag, weak or not. The wildcard matches
+// every tag.
+func (e ETag) WeakMatch(other ETag) bool {
+	if e.Any || other.Any {
+		return !e.IsZero() && !other.IsZero()
+	}
+	return e.Tag == other.Tag && !e.IsZero() && !other.IsZero()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (e ETag) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. An empty text is the zero
+// ETag.
+func (e *ETag) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*e = ETag{}
+		return nil
+	}
+	parsed, err := ParseETag(string(text))
+	if err != nil {
+		return err
+	}
+	*e = parsed
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseETag(t *testing.T) {
+	for input, expected := range map[string]ETag{
+		`"xyzzy"`:   {Tag: "xyzzy"},
+		`W/"xyzzy"`: {Tag: "xyzzy", Weak: true},
+		` "a-1" `:   {Tag: "a-1"},
+		`*`:         {Any: true},
+	} {
+		e, err := ParseETag(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, e, input)
+	}
+	assert.Equal(t, `W/"xyzzy"`, ETag{Tag: "xyzzy", Weak: true}.String())
+	assert.Equal(t, "", ETag{}.String())
+
+	for _, input := range []string{"", "xyzzy", `"xyzzy`, `w/"xyzzy"`, `W/xyzzy`, `"a"b"`, `"a b"`} {
+		_, err := ParseETag(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestETagMatch(t *testing.T) {
+	strong, weak, other := ETag{Tag: "1"}, ETag{Tag: "1", Weak: true}, ETag{Tag: "2"}
+	assert.True(t, strong.StrongMatch(strong))
+	assert.False(t, strong.StrongMatch(weak))
+	assert.False(t, weak.StrongMatch(weak))
+	assert.True(t, strong.WeakMatch(weak))
+	assert.True(t, weak.WeakMatch(weak))
+	assert.False(t, strong.WeakMatch(other))
+
+	anyTag := ETag{Any: true}
+	assert.True(t, anyTag.StrongMatch(weak))
+	assert.True(t, other.WeakMatch(anyTag))
+	assert.False(t, anyTag.WeakMatch(ETag{}))
+	assert.False(t, ETag{}.StrongMatch(ETag{}))
+}
+
+type FooStructForETag struct {
+	IfMatch     ETag   `header:"If-Match"`
+	IfNoneMatch []ETag `header:"If-None-Match" collection_format:"csv"`
+	Version     ETag   `form:"version" json:"version"`
+}
+
+func TestBindETag(t *testing.T) {
+	req := requestWithBody("PUT", "/?version=W/%22v2%22", "")
+	req.Header.Set("If-Match", `"v1"`)
+	req.Header.Add("If-None-Match", `W/"a", "b"`)
+	req.Header.Add("If-None-Match", `"c"`)
+
+	var obj FooStructForETag
+	assert.NoError(t, Header.Bind(req, &obj))
+	assert.Equal(t, ETag{Tag: "v1"}, obj.IfMatch)
+	assert.Equal(t, []ETag{{Tag: "a", Weak: true}, {Tag: "b"}, {Tag: "c"}}, obj.IfNoneMatch)
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, ETag{Tag: "v2", Weak: true}, obj.Version)
+
+	out, err := json.Marshal(obj)
+	assert.NoError(t, err)
+	var decoded FooStructForETag
+	assert.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, obj.Version, decoded.Version)
+
+	req.Header.Set("If-Match", "v1")
+	assert.EqualError(t, Header.Bind(req, &obj), `binding: field "If-Match": invalid entity tag "v1"`)
+}
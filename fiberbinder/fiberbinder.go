@@ -0,0 +1,61 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package fiberbinder binds fiber requests with the binding package, e.g.
+//
+//	b := fiberbinder.New(binding.DefaultOptions)
+//	app.Post("/users", func(c *fiber.Ctx) error {
+//		var user User
+//		if err := b.Bind(c, &user); err != nil {
+//			return err
+//		}
+//		...
+//	})
+package fiberbinder
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/ppltools/binding"
+)
+
+// Binder binds fiber requests with a binding.Decoder.
+type Binder struct {
+	Decoder *binding.Decoder
+}
+
+// New returns a Binder using opts.
+func New(opts binding.Options) *Binder {
+	return &Binder{Decoder: binding.NewDecoder(opts)}
+}
+
+// Error is the error returned by Binder.Bind when a bind fails. It unwraps to
+// both the *fiber.Error answering the request, which fiber error handlers
+// find with errors.As, and the binding error.
+type Error struct {
+	Fiber *fiber.Error
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the *fiber.Error and the binding error.
+func (e *Error) Unwrap() []error {
+	return []error{e.Fiber, e.Err}
+}
+
+// Bind binds the request of c onto obj. Binding errors are returned as an
+// *Error, with the status given by binding.StatusCode, e.g. 400 Bad Request.
+func (b *Binder) Bind(c *fiber.Ctx, obj interface{}) error {
+	req, err := adaptor.ConvertRequest(c, false)
+	if err != nil {
+		return err
+	}
+	if err := b.Decoder.Bind(req, obj); err != nil {
+		return &Error{Fiber: fiber.NewError(binding.StatusCode(err), err.Error()), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fiberbinder
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppltools/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name string `json:"name" form:"name"`
+	Age  int    `json:"age" form:"age"`
+}
+
+func TestBinder(t *testing.T) {
+	b := New(binding.DefaultOptions)
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		var obj user
+		if err := b.Bind(c, &obj); err != nil {
+			return err
+		}
+		return c.SendString(obj.Name)
+	})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "manu", "age": 30}`))
+	req.Header.Set("Content-Type", binding.MIMEJSON)
+	resp, err := app.Test(req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"age": "x"}`))
+	req.Header.Set("Content-Type", binding.MIMEJSON)
+	resp, err = app.Test(req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestBinderError(t *testing.T) {
+	b := New(binding.Options{MaxBodySize: 8})
+	app := fiber.New()
+	var bindErr error
+	app.Post("/", func(c *fiber.Ctx) error {
+		bindErr = b.Bind(c, &user{})
+		return bindErr
+	})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "manu", "age": 30}`))
+	req.Header.Set("Content-Type", binding.MIMEJSON)
+	resp, err := app.Test(req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+	}
+	var limitErr *binding.LimitError
+	assert.True(t, errors.As(bindErr, &limitErr))
+}
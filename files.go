@@ -0,0 +1,417 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// UploadedFile is a file part of a multipart form, bound by the multipart
+// binding into fields of type UploadedFile or *UploadedFile, or slices of
+// them holding every file of their key, e.g.
+//
+//	Attachments []*binding.UploadedFile `form:"attachment"`
+type UploadedFile struct {
+	// Filename is the name of the file on the client.
+	Filename    string
+	Size        int64
+	ContentType string
+	Header      textproto.MIMEHeader
+	// ObjectKey is the key the file was stored under by Options.StoreFile.
+	ObjectKey string
+
+	// at most one of fh, data and path holds the content, none if the file
+	// was stored while streaming it
+	fh   *multipart.FileHeader
+	data []byte
+	path string
+	// sums are the checksums computed while the file was read, by
+	// algorithm.
+	sums map[string]string
+	// inspected and stored are set once the file was passed to
+	// Options.InspectFile and Options.StoreFile.
+	inspected, stored bool
+}
+
+var uploadedFileType = reflect.TypeOf(UploadedFile{})
+
+// isFileType reports whether fields of type typ are bound from the files of
+// their key: UploadedFile and *UploadedFile, or slices of them for all the
+// files of the key.
+func isFileType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return nestedType(typ) == uploadedFileType
+}
+
+// ErrFileStored is returned by UploadedFile.Open for a file streamed to
+// Options.StoreFile, its content having never been kept locally.
+var ErrFileStored = errors.New("binding: file content was stored remotely")
+
+// Open opens the content of the file.
+func (f *UploadedFile) Open() (io.ReadCloser, error) {
+	switch {
+	case f.fh != nil:
+		return f.fh.Open()
+	case f.path != "":
+		return os.Open(f.path)
+	case f.stored && f.data == nil:
+		return nil, ErrFileStored
+	default:
+		return ioutil.NopCloser(bytes.NewReader(f.data)), nil
+	}
+}
+
+// Remove removes the temporary file holding the content of a large file
+// read by the lazy multipart binding. Such files belong to the caller once the
+// bind succeeds, the binding removing them if it fails. Files parsed by
+// ParseMultipartForm are removed by http.Server instead.
+func (f *UploadedFile) Remove() error {
+	if f.path == "" {
+		return nil
+	}
+	return os.Remove(f.path)
+}
+
+// removeFiles removes the temporary files of files, see Remove.
+func removeFiles(files map[string][]*UploadedFile) {
+	for _, fs := range files {
+		for _, f := range fs {
+			f.Remove()
+		}
+	}
+}
+
+func newUploadedFile(fh *multipart.FileHeader) *UploadedFile {
+	return &UploadedFile{
+		Filename:    fh.Filename,
+		Size:        fh.Size,
+		ContentType: fh.Header.Get("Content-Type"),
+		Header:      fh.Header,
+		fh:          fh,
+	}
+}
+
+// uploadedFiles wraps the files parsed by ParseMultipartForm.
+func uploadedFiles(form *multipart.Form) map[string][]*UploadedFile {
+	files := make(map[string][]*UploadedFile, len(form.File))
+	for key, fhs := range form.File {
+		for _, fh := range fhs {
+			files[key] = append(files[key], newUploadedFile(fh))
+		}
+	}
+	return files
+}
+
+// spoolFile reads a file part, keeping up to maxMemory bytes in memory and
+// the rest in a temporary file, or passing it to opts.StoreFile if set, and
+// computes the given checksums along the way. The content is also passed to
+// opts.InspectFile, unless nil.
+func spoolFile(part *multipart.Part, maxMemory int64, algorithms []string, opts *Options) (f *UploadedFile, err error) {
+	f = &UploadedFile{
+		Filename:    part.FileName(),
+		ContentType: part.Header.Get("Content-Type"),
+		Header:      part.Header,
+	}
+	hashes := make(map[string]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, 0, len(algorithms)+1)
+	for _, algorithm := range algorithms {
+		h := checksumAlgorithms[algorithm]()
+		hashes[algorithm] = h
+		writers = append(writers, h)
+	}
+	if opts.InspectFile != nil {
+		f.inspected = true
+		in := startInspection(part.FormName(), f, opts.InspectFile)
+		defer func() {
+			if ierr := in.finish(err); err == nil && ierr != nil {
+				f.Remove()
+				f, err = nil, &FileRejectedError{Key: part.FormName(), Filename: f.Filename, Err: ierr}
+			}
+		}()
+		writers = append(writers, in)
+	}
+	r := io.TeeReader(part, io.MultiWriter(writers...))
+
+	if opts.StoreFile != nil {
+		f.stored = true
+		cr := &countingReader{r: r}
+		if f.ObjectKey, err = opts.StoreFile(part.FormName(), f, cr); err != nil {
+			return nil, err
+		}
+		// drain what the store left unread so that checksums and
+		// inspection see the whole file
+		if _, err := io.Copy(ioutil.Discard, cr); err != nil {
+			return nil, err
+		}
+		f.Size = cr.n
+	} else if err := f.spool(r, maxMemory); err != nil {
+		return nil, err
+	}
+
+	f.sums = make(map[string]string, len(hashes))
+	for algorithm, h := range hashes {
+		f.sums[algorithm] = hex.EncodeToString(h.Sum(nil))
+	}
+	return f, nil
+}
+
+// spool reads the content of f from r, keeping up to maxMemory bytes in
+// memory and the rest in a temporary file.
+func (f *UploadedFile) spool(r io.Reader, maxMemory int64) error {
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, r, maxMemory+1)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if n <= maxMemory {
+		f.data, f.Size = buf.Bytes(), n
+		return nil
+	}
+	tmp, err := ioutil.TempFile("", "binding-upload-")
+	if err != nil {
+		return err
+	}
+	size, err := io.Copy(tmp, io.MultiReader(&buf, r))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	f.path, f.Size = tmp.Name(), size
+	return nil
+}
+
+// FileStore stores the content of a file part of key, e.g. by uploading it
+// to object storage, and returns the key of the stored object. f holds the
+// metadata of the file, its Size being only known once r is drained.
+type FileStore func(key string, f *UploadedFile, r io.Reader) (string, error)
+
+// storeFile passes the file of key to store, unless already stored while
+// spooling it.
+func storeFile(key string, f *UploadedFile, store FileStore) error {
+	if store == nil || f.stored {
+		return nil
+	}
+	f.stored = true
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f.ObjectKey, err = store(key, f, r)
+	return err
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// FileInspector inspects the content of a file part of key while the
+// multipart binding reads it, e.g. to scan it for viruses. f holds the
+// metadata of the file, its Size being only known once r is drained.
+// Returning an error vetoes the bind with a *FileRejectedError; returning
+// before reading r to its end leaves the rest uninspected.
+type FileInspector func(key string, f *UploadedFile, r io.Reader) error
+
+// inspection runs a FileInspector on the data written to it.
+type inspection struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func startInspection(key string, f *UploadedFile, inspect FileInspector) *inspection {
+	pr, pw := io.Pipe()
+	in := &inspection{pw: pw, done: make(chan error, 1)}
+	go func() {
+		err := inspect(key, f, pr)
+		// unblock and discard the writes of the rest of the file
+		pr.CloseWithError(io.ErrClosedPipe)
+		in.done <- err
+	}()
+	return in
+}
+
+// Write passes p to the inspector, ignoring it once the inspector returned.
+func (in *inspection) Write(p []byte) (int, error) {
+	in.pw.Write(p)
+	return len(p), nil
+}
+
+// finish signals the end of the file, or the read error err, and returns the
+// error of the inspector.
+func (in *inspection) finish(err error) error {
+	in.pw.CloseWithError(err)
+	return <-in.done
+}
+
+// inspectFile runs inspect on the file of key, unless already inspected while
+// spooling it.
+func inspectFile(key string, f *UploadedFile, inspect FileInspector) error {
+	if inspect == nil || f.inspected {
+		return nil
+	}
+	f.inspected = true
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := inspect(key, f, r); err != nil {
+		return &FileRejectedError{Key: key, Filename: f.Filename, Err: err}
+	}
+	return nil
+}
+
+// checksumAlgorithms are the algorithms of the checksum tag.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+	"md5":    md5.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// checksumSpec is the compiled checksum tag of a field, e.g.
+//
+//	AvatarSHA string `checksum:"avatar,sha256"`
+//
+// which receives the hex encoded SHA-256 of the file bound from the avatar
+// key.
+type checksumSpec struct {
+	file      string
+	algorithm string
+}
+
+func compileChecksum(typeField reflect.StructField) (*checksumSpec, error) {
+	tag := typeField.Tag.Get("checksum")
+	if tag == "" {
+		return nil, nil
+	}
+	parts := strings.Split(tag, ",")
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid checksum %q", tag)
+	}
+	if _, ok := checksumAlgorithms[parts[1]]; !ok {
+		return nil, fmt.Errorf("unknown checksum algorithm %q", parts[1])
+	}
+	if typeField.Type.Kind() != reflect.String {
+		return nil, errors.New("checksum tag needs a string field")
+	}
+	return &checksumSpec{file: parts[0], algorithm: parts[1]}, nil
+}
+
+// setFile binds the UploadedFile field from the files of the request, or
+// slice field from all of them.
+func (m *formMapper) setFile(field *fieldInfo, structField reflect.Value) error {
+	files := m.files(field.key)
+	if len(files) == 0 {
+		return nil
+	}
+	m.markUsed(field.key)
+	typ := structField.Type()
+	if typ.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(typ, len(files), len(files))
+		for i, f := range files {
+			if err := m.prepareFile(field, f); err != nil {
+				return err
+			}
+			setUploadedFile(slice.Index(i), f)
+		}
+		structField.Set(slice)
+		return nil
+	}
+	if err := m.prepareFile(field, files[0]); err != nil {
+		return err
+	}
+	setUploadedFile(structField, files[0])
+	return nil
+}
+
+// prepareFile passes f, a file of the key of field, to the inspection and
+// store hooks of the options.
+func (m *formMapper) prepareFile(field *fieldInfo, f *UploadedFile) error {
+	if err := inspectFile(m.inputKey(field.key), f, m.state.opts.InspectFile); err != nil {
+		return err
+	}
+	return storeFile(m.inputKey(field.key), f, m.state.opts.StoreFile)
+}
+
+// setUploadedFile sets v, an UploadedFile or *UploadedFile, to f.
+func setUploadedFile(v reflect.Value, f *UploadedFile) {
+	if v.Kind() == reflect.Ptr {
+		v.Set(reflect.ValueOf(f))
+	} else {
+		v.Set(reflect.ValueOf(*f))
+	}
+}
+
+// setObjectKey sets the field to the key the file it names was stored under
+// by Options.StoreFile.
+func (m *formMapper) setObjectKey(field *fieldInfo, structField reflect.Value) error {
+	files := m.files(field.objectKey)
+	if len(files) == 0 {
+		return nil
+	}
+	f := files[0]
+	if err := inspectFile(m.inputKey(field.objectKey), f, m.state.opts.InspectFile); err != nil {
+		return err
+	}
+	if err := storeFile(m.inputKey(field.objectKey), f, m.state.opts.StoreFile); err != nil {
+		return err
+	}
+	structField.SetString(f.ObjectKey)
+	return nil
+}
+
+// setChecksum sets the checksum field from the file it names, reading the
+// file if the checksum wasn't computed while streaming it.
+func (m *formMapper) setChecksum(field *fieldInfo, structField reflect.Value) error {
+	files := m.files(field.checksum.file)
+	if len(files) == 0 {
+		return nil
+	}
+	f := files[0]
+	sum, ok := f.sums[field.checksum.algorithm]
+	if !ok {
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		h := checksumAlgorithms[field.checksum.algorithm]()
+		if _, err := io.Copy(h, r); err != nil {
+			return err
+		}
+		sum = hex.EncodeToString(h.Sum(nil))
+	}
+	structField.SetString(sum)
+	return nil
+}
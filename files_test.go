@@ -0,0 +1,269 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForFiles struct {
+	Title     string        `form:"title"`
+	Avatar    *UploadedFile `form:"avatar"`
+	Doc       UploadedFile  `form:"doc"`
+	AvatarSHA string        `checksum:"avatar,sha256"`
+	DocCRC    string        `checksum:"doc,crc32"`
+}
+
+type FooStructForStoredFiles struct {
+	Avatar    *UploadedFile `form:"avatar"`
+	DocKey    string        `object_key:"doc"`
+	AvatarSHA string        `checksum:"avatar,sha256"`
+}
+
+type FooStructForBadObjectKey struct {
+	Key int `object_key:"avatar"`
+}
+
+type FooStructForBadChecksum struct {
+	Sum string `checksum:"avatar,sha1"`
+}
+
+type FooStructForBadChecksumType struct {
+	Sum int `checksum:"avatar,sha256"`
+}
+
+func createFilesRequest() *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("title", "hello")
+	w, _ := mw.CreateFormFile("avatar", "me.png")
+	w.Write([]byte("avatar content"))
+	w, _ = mw.CreateFormFile("other", "other.bin")
+	w.Write([]byte(strings.Repeat("x", 1024)))
+	w, _ = mw.CreateFormFile("doc", "doc.txt")
+	w.Write([]byte("doc content"))
+	mw.Close()
+	req, _ := http.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func testBindFiles(t *testing.T) {
+	var obj FooStructForFiles
+	assert.NoError(t, FormMultipart.Bind(createFilesRequest(), &obj))
+	assert.Equal(t, "hello", obj.Title)
+	if assert.NotNil(t, obj.Avatar) {
+		assert.Equal(t, "me.png", obj.Avatar.Filename)
+		assert.Equal(t, int64(14), obj.Avatar.Size)
+		assert.Equal(t, "application/octet-stream", obj.Avatar.ContentType)
+		r, err := obj.Avatar.Open()
+		if assert.NoError(t, err) {
+			data, _ := ioutil.ReadAll(r)
+			r.Close()
+			assert.Equal(t, "avatar content", string(data))
+		}
+	}
+	assert.Equal(t, "doc.txt", obj.Doc.Filename)
+	assert.Equal(t, "c73e4347231a701a80c3fed23542393e5eaa0983b63b102ea732a2a8fdd76bed", obj.AvatarSHA)
+	assert.Equal(t, "54573b4b", obj.DocCRC)
+}
+
+func TestBindFiles(t *testing.T) {
+	testBindFiles(t)
+}
+
+func TestBindFilesLazy(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+	testBindFiles(t)
+}
+
+type FooStructForMultipleFiles struct {
+	Attachments []*UploadedFile `form:"attachment"`
+	Copies      []UploadedFile  `form:"attachment"`
+	Missing     []*UploadedFile `form:"missing"`
+}
+
+func testBindMultipleFiles(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		w, _ := mw.CreateFormFile("attachment", name)
+		w.Write([]byte("content of " + name))
+	}
+	mw.Close()
+	req, _ := http.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var obj FooStructForMultipleFiles
+	assert.NoError(t, FormMultipart.Bind(req, &obj))
+	if assert.Len(t, obj.Attachments, 2) {
+		for i, name := range []string{"a.txt", "b.txt"} {
+			assert.Equal(t, name, obj.Attachments[i].Filename)
+			assert.Equal(t, name, obj.Copies[i].Filename)
+			r, err := obj.Attachments[i].Open()
+			if assert.NoError(t, err) {
+				data, _ := ioutil.ReadAll(r)
+				r.Close()
+				assert.Equal(t, "content of "+name, string(data))
+			}
+		}
+	}
+	assert.Nil(t, obj.Missing)
+}
+
+func TestBindMultipleFiles(t *testing.T) {
+	testBindMultipleFiles(t)
+}
+
+func TestBindMultipleFilesLazy(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+	testBindMultipleFiles(t)
+}
+
+func testInspectFile(t *testing.T) {
+	var seen []string
+	opts := DefaultOptions
+	opts.InspectFile = func(key string, f *UploadedFile, r io.Reader) error {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		seen = append(seen, key+"="+string(data))
+		if bytes.Contains(data, []byte("doc")) {
+			return errors.New("infected")
+		}
+		return nil
+	}
+	defer withOptions(opts)()
+
+	var obj FooStructForFiles
+	err := FormMultipart.Bind(createFilesRequest(), &obj)
+	var rejected *FileRejectedError
+	if assert.True(t, errors.As(err, &rejected)) {
+		assert.Equal(t, "doc", rejected.Key)
+		assert.Equal(t, "doc.txt", rejected.Filename)
+		assert.EqualError(t, rejected.Err, "infected")
+	}
+	assert.Equal(t, []string{"avatar=avatar content", "doc=doc content"}, seen)
+}
+
+func TestInspectFile(t *testing.T) {
+	testInspectFile(t)
+}
+
+func TestInspectFileLazy(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+	testInspectFile(t)
+}
+
+func TestInspectFilePartially(t *testing.T) {
+	defer withOptions(Options{
+		LazyMultipart: true,
+		InspectFile: func(key string, f *UploadedFile, r io.Reader) error {
+			_, err := r.Read(make([]byte, 2))
+			return err
+		},
+	})()
+	var obj FooStructForFiles
+	assert.NoError(t, FormMultipart.Bind(createFilesRequest(), &obj))
+	assert.Equal(t, int64(14), obj.Avatar.Size)
+	assert.Equal(t, "c73e4347231a701a80c3fed23542393e5eaa0983b63b102ea732a2a8fdd76bed", obj.AvatarSHA)
+}
+
+func testStoreFile(t *testing.T, lazy bool) {
+	stored := make(map[string]string)
+	defer withOptions(Options{
+		LazyMultipart: lazy,
+		StoreFile: func(key string, f *UploadedFile, r io.Reader) (string, error) {
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				return "", err
+			}
+			stored["uploads/"+f.Filename] = string(data)
+			return "uploads/" + f.Filename, nil
+		},
+	})()
+
+	var obj FooStructForStoredFiles
+	assert.NoError(t, FormMultipart.Bind(createFilesRequest(), &obj))
+	assert.Equal(t, map[string]string{
+		"uploads/me.png":  "avatar content",
+		"uploads/doc.txt": "doc content",
+	}, stored)
+	assert.Equal(t, "uploads/doc.txt", obj.DocKey)
+	if assert.NotNil(t, obj.Avatar) {
+		assert.Equal(t, "uploads/me.png", obj.Avatar.ObjectKey)
+		assert.Equal(t, int64(14), obj.Avatar.Size)
+		_, err := obj.Avatar.Open()
+		if lazy {
+			assert.Equal(t, ErrFileStored, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	assert.Equal(t, "c73e4347231a701a80c3fed23542393e5eaa0983b63b102ea732a2a8fdd76bed", obj.AvatarSHA)
+}
+
+func TestStoreFile(t *testing.T) {
+	testStoreFile(t, false)
+}
+
+func TestStoreFileLazy(t *testing.T) {
+	testStoreFile(t, true)
+}
+
+func TestStoreFileFail(t *testing.T) {
+	defer withOptions(Options{
+		LazyMultipart: true,
+		StoreFile: func(key string, f *UploadedFile, r io.Reader) (string, error) {
+			return "", errors.New("bucket unavailable")
+		},
+	})()
+	var obj FooStructForStoredFiles
+	assert.EqualError(t, FormMultipart.Bind(createFilesRequest(), &obj), "bucket unavailable")
+}
+
+func TestSpoolFile(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	w, _ := mw.CreateFormFile("avatar", "me.png")
+	w.Write([]byte("avatar content"))
+	mw.Close()
+
+	mr := multipart.NewReader(body, mw.Boundary())
+	part, err := mr.NextPart()
+	assert.NoError(t, err)
+	f, err := spoolFile(part, 4, []string{"sha256"}, &Options{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, f.path)
+	assert.Equal(t, int64(14), f.Size)
+	assert.Equal(t, "c73e4347231a701a80c3fed23542393e5eaa0983b63b102ea732a2a8fdd76bed", f.sums["sha256"])
+
+	r, err := f.Open()
+	if assert.NoError(t, err) {
+		data, _ := ioutil.ReadAll(r)
+		r.Close()
+		assert.Equal(t, "avatar content", string(data))
+	}
+	assert.NoError(t, f.Remove())
+	_, err = os.Stat(f.path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestBadChecksumTag(t *testing.T) {
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadChecksum]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadChecksumType]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadObjectKey]() })
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MapFlags binds the flags set on the parsed fs onto obj, which must be a
+// pointer to a struct, using the same tags and conversions as the form
+// bindings, then validates obj. Flags not set on the command line are left to
+// the default tags of their fields, so that request structs can be shared
+// between CLI tools and HTTP handlers.
+func MapFlags(obj interface{}, fs *flag.FlagSet) error {
+	form := make(map[string][]string)
+	fs.Visit(func(f *flag.Flag) {
+		form[f.Name] = append(form[f.Name], f.Value.String())
+	})
+	s := newBindState()
+	if err := mapFormState(obj, form, s); err != nil {
+		return err
+	}
+	return s.validate(obj)
+}
+
+// MapArgs parses command line arguments such as os.Args[1:] and binds them
+// onto obj like MapFlags does. Flags are written -name=value, -name value or,
+// for bool fields, -name alone, with one or two leading dashes. Parsing stops
+// at the first non-flag argument or after "--"; the remaining arguments are
+// returned.
+func MapArgs(obj interface{}, args []string) (rest []string, err error) {
+	// the flags are named by the keys the fields are bound from
+	s := newBindState()
+	tag := s.keyTag()
+	info, err := cachedStructInfo(reflect.TypeOf(obj).Elem(), tag)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]reflect.Type)
+	collectFlagTypes(reflect.TypeOf(obj).Elem(), info, tag, fields, "")
+
+	form := make(map[string][]string)
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			args = args[1:]
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		args = args[1:]
+
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		value, hasValue := "", false
+		if idx := strings.Index(name, "="); idx != -1 {
+			name, value, hasValue = name[:idx], name[idx+1:], true
+		}
+		typ, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("binding: unknown flag %q", arg)
+		}
+		if !hasValue {
+			switch {
+			case typ.Kind() == reflect.Bool:
+				value = "true"
+			case len(args) > 0:
+				value, args = args[0], args[1:]
+			default:
+				return nil, fmt.Errorf("binding: flag %q needs a value", arg)
+			}
+		}
+		form[name] = append(form[name], value)
+	}
+
+	if err := mapFormState(obj, form, s); err != nil {
+		return nil, err
+	}
+	return args, s.validate(obj)
+}
+
+// collectFlagTypes records the type of every field of typ, whose struct
+// metadata for tag is info, by key after prefix, looking through pointers.
+func collectFlagTypes(typ reflect.Type, info *structInfo, tag string, fields map[string]reflect.Type, prefix string) {
+	for _, field := range info.fields {
+		// recursive structs would have flags without end
+		if field.sourceOnly || field.recursive {
+			continue
+		}
+		fieldType := typ.Field(field.index).Type
+		if field.nested {
+			fieldType = nestedType(fieldType)
+			nested, _ := cachedStructInfo(fieldType, tag)
+			collectFlagTypes(fieldType, nested, tag, fields, prefix+field.prefix)
+			continue
+		}
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		fields[prefix+field.key] = fieldType
+	}
+}
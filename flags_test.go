@@ -0,0 +1,115 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForFlags struct {
+	Name    string `form:"name" binding:"required"`
+	Count   int    `form:"count" default:"3"`
+	Verbose bool   `form:"verbose"`
+	Nested  struct {
+		Level *uint `form:"level"`
+	}
+}
+
+func TestMapFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	fs.Int("count", 0, "")
+	fs.Bool("verbose", false, "")
+	fs.Uint("level", 0, "")
+	assert.NoError(t, fs.Parse([]string{"-name", "manu", "-verbose", "-level=2"}))
+
+	obj := FooStructForFlags{}
+	assert.NoError(t, MapFlags(&obj, fs))
+	assert.Equal(t, "manu", obj.Name)
+	assert.Equal(t, 3, obj.Count)
+	assert.True(t, obj.Verbose)
+	assert.Equal(t, uint(2), *obj.Nested.Level)
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	assert.NoError(t, fs.Parse(nil))
+	assert.Error(t, MapFlags(&FooStructForFlags{}, fs))
+}
+
+func TestMapArgs(t *testing.T) {
+	obj := FooStructForFlags{}
+	rest, err := MapArgs(&obj, []string{"--name=manu", "-count", "5", "--verbose", "-level", "7", "file.txt", "-x"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"file.txt", "-x"}, rest)
+	assert.Equal(t, "manu", obj.Name)
+	assert.Equal(t, 5, obj.Count)
+	assert.True(t, obj.Verbose)
+	assert.Equal(t, uint(7), *obj.Nested.Level)
+
+	obj = FooStructForFlags{}
+	rest, err = MapArgs(&obj, []string{"--name", "manu", "--verbose=false", "--", "--count"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--count"}, rest)
+	assert.False(t, obj.Verbose)
+	assert.Equal(t, 3, obj.Count)
+
+	_, err = MapArgs(&FooStructForFlags{}, []string{"--nmae=manu"})
+	assert.EqualError(t, err, `binding: unknown flag "--nmae=manu"`)
+	_, err = MapArgs(&FooStructForFlags{}, []string{"--name"})
+	assert.EqualError(t, err, `binding: flag "--name" needs a value`)
+	_, err = MapArgs(&FooStructForFlags{}, []string{"--name=manu", "--count=x"})
+	assert.Error(t, err)
+}
+
+func TestMapArgsDerive(t *testing.T) {
+	var obj FooStructForDerive
+	_, err := MapArgs(&obj, []string{"--email=Ada@Example.com", "--first=Ada"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@example.com", obj.NormalizedEmail)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("email", "", "")
+	assert.NoError(t, fs.Parse([]string{"-email=a@b.c"}))
+	assert.EqualError(t, MapFlags(&FooStructForDerive{}, fs), `binding: field "FullName": no name`)
+}
+
+type FooStructForFlagsNaming struct {
+	UserID int
+	Nested struct {
+		TeamName string
+	}
+}
+
+func TestMapArgsFieldNaming(t *testing.T) {
+	defer withOptions(Options{FieldNaming: NamingSnakeCase})()
+
+	var obj FooStructForFlagsNaming
+	_, err := MapArgs(&obj, []string{"-user_id=5", "--team_name", "core"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, obj.UserID)
+	assert.Equal(t, "core", obj.Nested.TeamName)
+
+	_, err = MapArgs(&FooStructForFlagsNaming{}, []string{"-UserID=5"})
+	assert.EqualError(t, err, `binding: unknown flag "-UserID=5"`)
+}
+
+type FooStructForFlagsFormFirst struct {
+	Name string `json:"name" form:"nm"`
+}
+
+func TestMapArgsFormTagFirst(t *testing.T) {
+	defer withOptions(Options{FormTagFirst: true})()
+
+	var obj FooStructForFlagsFormFirst
+	_, err := MapArgs(&obj, []string{"-nm=x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "x", obj.Name)
+
+	_, err = MapArgs(&FooStructForFlagsFormFirst{}, []string{"-name=x"})
+	assert.EqualError(t, err, `binding: unknown flag "-name=x"`)
+}
@@ -16,41 +16,96 @@ func (formBinding) Name() string {
 	return "form"
 }
 
-func (formBinding) Bind(req *http.Request, obj interface{}) error {
+func (b formBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (formBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	if err := s.watchDigest(req); err != nil {
+		return err
+	}
+	if err := s.trackKeyOrder(req, obj, true); err != nil {
+		return err
+	}
 	if err := req.ParseForm(); err != nil {
 		return err
 	}
 	req.ParseMultipartForm(defaultMemory)
-	if err := mapForm(obj, req.Form); err != nil {
+	if err := mapFormState(obj, req.Form, s); err != nil {
+		return err
+	}
+	if err := s.finishBody(req, req.Body, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return s.validate(obj)
 }
 
 func (formPostBinding) Name() string {
 	return "form-urlencoded"
 }
 
-func (formPostBinding) Bind(req *http.Request, obj interface{}) error {
+func (b formPostBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (formPostBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	if err := s.watchDigest(req); err != nil {
+		return err
+	}
+	if err := s.trackKeyOrder(req, obj, true); err != nil {
+		return err
+	}
 	if err := req.ParseForm(); err != nil {
 		return err
 	}
-	if err := mapForm(obj, req.PostForm); err != nil {
+	if err := mapFormState(obj, req.PostForm, s); err != nil {
 		return err
 	}
-	return validate(obj)
+	if err := s.finishBody(req, req.Body, obj); err != nil {
+		return err
+	}
+	return s.validate(obj)
 }
 
 func (formMultipartBinding) Name() string {
 	return "multipart/form-data"
 }
 
-func (formMultipartBinding) Bind(req *http.Request, obj interface{}) error {
-	if err := req.ParseMultipartForm(defaultMemory); err != nil {
+func (b formMultipartBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (formMultipartBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	if err := s.watchDigest(req); err != nil {
+		return err
+	}
+	if !s.opts.LazyMultipart {
+		if err := req.ParseMultipartForm(defaultMemory); err != nil {
+			return err
+		}
+		return s.bindMultipart(req, obj, req.MultipartForm.Value, uploadedFiles(req.MultipartForm))
+	}
+	values, files, err := parseMultipartLazily(req, obj, s)
+	if err != nil {
+		return err
+	}
+	// the files spooled by the lazy binding are only left to the caller
+	// once bound
+	if err := s.bindMultipart(req, obj, values, files); err != nil {
+		removeFiles(files)
+		return err
+	}
+	return nil
+}
+
+// bindMultipart binds the values and files of a multipart form onto obj.
+func (s *bindState) bindMultipart(req *http.Request, obj interface{}, values map[string][]string, files map[string][]*UploadedFile) error {
+	s.files = files
+	if err := mapFormState(obj, values, s); err != nil {
 		return err
 	}
-	if err := mapForm(obj, req.MultipartForm.Value); err != nil {
+	if err := s.finishBody(req, req.Body, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return s.validate(obj)
 }
@@ -5,119 +5,575 @@
 package binding
 
 import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/textproto"
+	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// MapForm binds values, e.g. router parameters merged with the query, onto
+// obj, which must be a pointer to a struct, then validates obj. Keys are
+// matched using the tag named tag, e.g. "uri", the same tags as the form
+// bindings if tag is empty or "form", or as the Query binding if it is
+// "query". Header tags such as "header" match keys case-insensitively.
+func MapForm(obj interface{}, values url.Values, tag string) error {
+	if tag == "" || tag == "form" || tag == "query" {
+		s := newBindState()
+		s.fromQuery = tag == "query"
+		if err := mapFormState(obj, values, s); err != nil {
+			return err
+		}
+		return s.validate(obj)
+	}
+	if headerTags[tag] {
+		canonical := make(map[string][]string, len(values))
+		for key, vals := range values {
+			key = textproto.CanonicalMIMEHeaderKey(key)
+			canonical[key] = append(canonical[key], vals...)
+		}
+		values = canonical
+	}
+	s := newBindState()
+	m := &formMapper{form: values, tag: tag, state: s}
+	if err := m.mapStruct(reflect.ValueOf(obj).Elem(), ""); err != nil {
+		return err
+	}
+	return s.validate(obj)
+}
+
 func mapForm(ptr interface{}, form map[string][]string) error {
-	typ := reflect.TypeOf(ptr).Elem()
-	val := reflect.ValueOf(ptr).Elem()
-	for i := 0; i < typ.NumField(); i++ {
-		typeField := typ.Field(i)
-		structField := val.Field(i)
-		if !structField.CanSet() {
+	return mapFormState(ptr, form, newBindState())
+}
+
+// mapFormState is mapForm threading the state of the enclosing binding.
+func mapFormState(ptr interface{}, form map[string][]string, s *bindState) error {
+	if err := s.checkFormValues(form); err != nil {
+		return err
+	}
+	m := &formMapper{form: form, tag: s.keyTag(), state: s}
+	if s.checking() {
+		m.used = make(map[string]bool, len(form))
+	}
+	if err := m.mapStruct(reflect.ValueOf(ptr).Elem(), ""); err != nil {
+		return err
+	}
+	for i, err := range runJobs(m.jobs, s.opts.MultipartWorkers) {
+		if err == nil {
 			continue
 		}
+		if !s.opts.CollectFieldErrors {
+			return err
+		}
+		m.errs = append(m.errs, seqFieldError{m.jobs[i].seq, err.(*FieldError)})
+	}
+	if m.used != nil {
+		s.warnUnknownKeys(form, m.used)
+	}
+	if len(m.errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(m.errs, func(i, j int) bool { return m.errs[i].seq < m.errs[j].seq })
+	errs := make(FieldErrors, len(m.errs))
+	for i, e := range m.errs {
+		errs[i] = e.err
+	}
+	return errs
+}
 
-		// get default value
-		inputFieldDefault := typeField.Tag.Get("default")
+// formMapper maps a form onto a struct.
+type formMapper struct {
+	form map[string][]string
+	// tag selects the struct metadata used, see structKey.
+	tag   string
+	state *bindState
+	// used records the keys fields were bound from, when warnings are
+	// collected.
+	used map[string]bool
+	// fieldPath and fieldKey identify the field being bound, and jobs are
+	// the decodes queued to run in parallel, see decode.
+	fieldPath, fieldKey string
+	jobs                []decodeJob
+	// seq numbers the fields in the order they are visited, that is in
+	// struct field order, and errs are the errors of the fields collected
+	// with the CollectFieldErrors option, see fail.
+	seq  int
+	errs []seqFieldError
+	// deferred are the fields bound with a conditional default once their
+	// struct is bound, see setDeferredDefaults.
+	deferred []deferredDefault
+	// parent is the mapper of the form the keys of form and fileForm are
+	// turned from, for structs bound in dot or bracket notation or with a
+	// key prefix, see
+	// subMapper. keys maps them back to the keys of the parent form, in
+	// which the other keys have the prefix prefix.
+	parent   *formMapper
+	keys     map[string]string
+	fileForm map[string][]*UploadedFile
+	prefix   string
+	// pathPrefix is the prefix tag of the nested structs being bound, which
+	// the keys of their fields have in the path of FieldError, see keyPath.
+	pathPrefix string
+	// folded maps the lowercased keys of form to them, see findKey.
+	folded map[string]string
+}
 
-		structFieldKind := structField.Kind()
-		inputFieldName := typeField.Tag.Get("json")
-		if inputFieldName == "" {
-			inputFieldName = typeField.Tag.Get("form")
+func (m *formMapper) mapStruct(val reflect.Value, path string) error {
+	info, err := cachedStructInfo(val.Type(), m.tag)
+	if err != nil {
+		return err
+	}
+	deferred := len(m.deferred)
+	for _, field := range info.fields {
+		typeField := val.Type().Field(field.index)
+		structField := val.Field(field.index)
+
+		if field.nested {
+			var sub *formMapper
+			if field.prefix != "" {
+				sub = m.prefixed(field.prefix)
+				sub.pathPrefix = m.pathPrefix + field.prefix
+			}
+			// recursive structs are only bound as deep as there are keys
+			if field.recursive && sub.form == nil && sub.fileForm == nil {
+				continue
+			}
+			err = bindNested(structField, func(nested reflect.Value) error {
+				if sub != nil {
+					return m.mapSub(sub, nested, path)
+				}
+				return m.mapStruct(nested, path)
+			})
+			if err != nil {
+				return err
+			}
+			continue
 		}
-		if inputFieldName == "" {
-			inputFieldName = typeField.Name
 
-			// if "form" tag is nil, we inspect if the field is a struct.
-			// this would not make sense for JSON parsing but it does for a form
-			// since data is flatten
-			if structFieldKind == reflect.Struct {
-				err := mapForm(structField.Addr().Interface(), form)
+		m.seq++
+		m.fieldPath, m.fieldKey = m.keyPath(path, field.key), m.inputKey(field.key)
+		switch {
+		case field.sourceOnly:
+		case field.timeRange != nil:
+			err = m.setTimeRange(field, structField)
+		case field.timeout != nil:
+			err = m.setTimeout(field, structField)
+		case field.file:
+			err = m.setFile(field, structField)
+		case field.objectKey != "":
+			err = m.setObjectKey(field, structField)
+		case field.checksum != nil:
+			err = m.setChecksum(field, structField)
+		case implementationsOf(typeField.Type) != nil:
+			err = m.setImplementation(field, implementationsOf(typeField.Type), structField, path)
+		default:
+			if ok, err := m.mapNested(field, typeField.Type, structField, path); ok {
 				if err != nil {
 					return err
 				}
 				continue
 			}
+			err = m.setField(field, typeField.Type, structField)
 		}
-		// omit field
-		if strings.HasPrefix(inputFieldName, "-") {
-			continue
+		if err != nil {
+			if err := m.fail(&FieldError{Path: m.fieldPath, Key: m.fieldKey, Err: err}); err != nil {
+				return err
+			}
 		}
-		if idx := strings.Index(inputFieldName, ","); idx != -1 {
-			inputFieldName = inputFieldName[:idx]
+	}
+	return m.setDeferredDefaults(val, deferred)
+}
+
+// seqFieldError is the error of the field numbered seq.
+type seqFieldError struct {
+	seq int
+	err *FieldError
+}
+
+// fail returns err, the error of the field being bound, unless the
+// CollectFieldErrors option is set, in which case it is collected.
+func (m *formMapper) fail(err *FieldError) error {
+	return m.failSeq(m.seq, err)
+}
+
+// failSeq is fail for the field numbered seq.
+func (m *formMapper) failSeq(seq int, err *FieldError) error {
+	if !m.state.opts.CollectFieldErrors {
+		return err
+	}
+	m.errs = append(m.errs, seqFieldError{seq, err})
+	return nil
+}
+
+// lookup returns the values of the field's key, falling back to its
+// deprecated alias, then to both matched case-insensitively with the
+// CaseInsensitiveKeys option.
+func (m *formMapper) lookup(field *fieldInfo) ([]string, bool) {
+	key, ok := m.findKey(field.key, false)
+	alias := false
+	if !ok && field.alias != "" {
+		key, ok = m.findKey(field.alias, false)
+		alias = ok
+	}
+	if !ok && m.state.opts.CaseInsensitiveKeys {
+		if key, ok = m.findKey(field.key, true); !ok && field.alias != "" {
+			key, ok = m.findKey(field.alias, true)
+			alias = ok
 		}
-		inputValue, exists := form[inputFieldName]
-		if !exists {
-			if inputFieldDefault == "" {
-				continue
+	}
+	if !ok {
+		return nil, false
+	}
+	m.markUsed(key)
+	if alias {
+		m.state.warn(Warning{
+			Kind:    WarningDeprecatedAlias,
+			Key:     key,
+			Message: fmt.Sprintf("deprecated key %q used instead of %q", key, field.key),
+		})
+	}
+	return m.form[key], true
+}
+
+// findKey returns the key of the form matching key, exactly or, if fold is
+// set, case-insensitively, and reports whether it has values.
+func (m *formMapper) findKey(key string, fold bool) (string, bool) {
+	if fold {
+		if m.folded == nil {
+			m.folded = make(map[string]string, len(m.form))
+			for _, k := range sortedKeys(m.form) {
+				lower := strings.ToLower(k)
+				if _, ok := m.folded[lower]; !ok && len(m.form[k]) > 0 {
+					m.folded[lower] = k
+				}
 			}
-			if err := setWithProperType(typeField.Type, inputFieldDefault, structField); err != nil {
+		}
+		key = m.folded[strings.ToLower(key)]
+	}
+	return key, len(m.form[key]) > 0
+}
+
+// noJSON reports whether the JSON values of field are disabled, see
+// ErrJSONValue.
+func (m *formMapper) noJSON(field *fieldInfo) bool {
+	return field.noJSON || m.state.opts.NoJSONValues
+}
+
+// files returns the files of key.
+func (m *formMapper) files(key string) []*UploadedFile {
+	if m.parent == nil {
+		return m.state.files[key]
+	}
+	return m.fileForm[key]
+}
+
+// inputKey returns the key of the request key, a key of the form, was read
+// from.
+func (m *formMapper) inputKey(key string) string {
+	for ; m.parent != nil; m = m.parent {
+		if k, ok := m.keys[key]; ok {
+			key = k
+		} else {
+			key = m.prefix + key
+		}
+	}
+	return key
+}
+
+// keyPath returns the path of the field of key within path, the fields of
+// nested structs having no segment of their own, as they are bound from the
+// same form, e.g. "items[2].price".
+func (m *formMapper) keyPath(path, key string) string {
+	return joinPath(path, m.pathPrefix+key)
+}
+
+func (m *formMapper) markUsed(key string) {
+	if m.used != nil {
+		m.used[key] = true
+	}
+}
+
+func (m *formMapper) setField(field *fieldInfo, typ reflect.Type, structField reflect.Value) error {
+	inputValue, exists := m.lookup(field)
+	if !exists && typ.Kind() == reflect.Map {
+		if ok, err := m.setMapEntries(field, typ, structField); ok {
+			return err
+		}
+	}
+	if !exists && isOrderedMap(typ) {
+		if ok, err := m.setOrderedEntries(field, structField); ok {
+			return err
+		}
+	}
+	if !exists {
+		if ok, err := m.setPartField(field, typ, structField); ok {
+			return err
+		}
+	}
+	if !exists && field.defaultIf != nil {
+		m.deferred = append(m.deferred, deferredDefault{field, typ, structField, m.seq, m.fieldPath, m.fieldKey})
+		return nil
+	}
+	if !exists {
+		if field.defaultValue == "" {
+			return nil
+		}
+		return m.state.setDefault(field.defaultValue, typ, structField, field)
+	}
+
+	// Unmarshalers get all the values of their key
+	if isUnmarshaler(typ) {
+		values := make([]string, len(inputValue))
+		for i, val := range inputValue {
+			var err error
+			if values[i], err = m.state.transform(val, field, StageLookup); err != nil {
 				return err
 			}
-			continue
 		}
+		if err := unmarshalForm(values, structField); err != nil {
+			return err
+		}
+		return field.check(structField)
+	}
 
-		// handle ptr field of struct
-		if structFieldKind == reflect.Ptr {
-			if structField.IsNil() {
-				structField.Set(reflect.New(typeField.Type.Elem()))
+	htmlBools := m.state.opts.HTMLBools && boolValued(typ)
+	noJSON := m.noJSON(field)
+	if field.separator != "" {
+		inputValue = splitValues(inputValue, field.separator)
+	}
+	if (typ.Kind() == reflect.Slice && !isBytes(typ) || typ.Kind() == reflect.Array) && repeatable(typ.Elem()) && (field.separator != "" || noJSON || !jsonArray(inputValue, typ)) {
+		values := make([]string, len(inputValue))
+		for i, val := range inputValue {
+			var err error
+			if values[i], err = m.state.transform(val, field, StageLookup); err != nil {
+				return err
+			}
+			if htmlBools {
+				if values[i], err = htmlBool(values[i]); err != nil {
+					return err
+				}
 			}
-			structField = structField.Elem()
-			structFieldKind = structField.Kind()
-			typeField.Type = typeField.Type.Elem()
 		}
+		if times, ok := timeArray(values, typ); ok && !noJSON {
+			values = times
+		}
+		setElems := setSliceField
+		if typ.Kind() == reflect.Array {
+			setElems = setArrayField
+		}
+		if err := setElems(values, typ, structField, field); err != nil {
+			return err
+		}
+		return field.check(structField)
+	}
+
+	if noJSON && jsonValued(typ) {
+		return ErrJSONValue
+	}
 
-		if _, isTime := structField.Interface().(time.Time); isTime {
-			if err := setTimeField(inputValue[0], typeField, structField); err != nil {
+	// the values of weighted headers add up, e.g. Accept: a, Accept: b
+	if isWeighted(typ) && len(inputValue) > 1 {
+		inputValue = []string{strings.Join(inputValue, ",")}
+	}
+	val, err := m.state.transform(inputValue[0], field, StageLookup)
+	if err != nil {
+		return err
+	}
+	if htmlBools {
+		if val, err = htmlBool(val); err != nil {
+			return err
+		}
+	}
+	if decodedAsJSON(typ) {
+		return m.decode(func() error {
+			if err := setValue(val, typ, structField, field); err != nil {
 				return err
 			}
+			return field.check(structField)
+		})
+	}
+	if err := setValue(val, typ, structField, field); err != nil {
+		return err
+	}
+	return field.check(structField)
+}
+
+// setMapEntries sets the map field from the keys naming its entries in
+// brackets or in dot notation, e.g. meta[env]=prod or meta.env=prod,
+// reporting whether there are any. Entry keys are converted to the key type
+// of the map like values are.
+func (m *formMapper) setMapEntries(field *fieldInfo, typ reflect.Type, structField reflect.Value) (bool, error) {
+	var result reflect.Value
+	for _, key := range sortedKeys(m.form) {
+		values := m.form[key]
+		entry, ok := mapEntryKey(key, field.key, m.state.opts.keySeparator())
+		if len(values) == 0 || !ok {
 			continue
 		}
+		m.markUsed(key)
+		if !result.IsValid() {
+			result = reflect.MakeMap(typ)
+		}
+		if err := setMapEntry(result, entry, values[0], field, m.state); err != nil {
+			return true, err
+		}
+	}
+	if !result.IsValid() {
+		return false, nil
+	}
+	structField.Set(result)
+	return true, nil
+}
 
-		if err := setWithProperType(typeField.Type, inputValue[0], structField); err != nil {
-			return err
+// mapEntryKey returns the key of the entry of the map bound from prefix key
+// names, e.g. env for meta[env] or meta.env with the prefix meta and the
+// separator ".", unescaped, see unescapeKey.
+func mapEntryKey(key, prefix, sep string) (string, bool) {
+	if !strings.HasPrefix(key, prefix) {
+		return "", false
+	}
+	var entry string
+	switch rest := key[len(prefix):]; {
+	case strings.HasPrefix(rest, "[") && closingBracket(rest) == len(rest)-1:
+		entry = rest[1 : len(rest)-1]
+	case strings.HasPrefix(rest, sep) && len(rest) > len(sep):
+		entry = rest[len(sep):]
+	default:
+		return "", false
+	}
+	return unescapeKey(entry, sep)
+}
+
+// sortedKeys returns the keys of form in order, so that the first error
+// found among them is always the same.
+func sortedKeys[V any](form map[string]V) []string {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setValue converts val to typ, the type of structField, allocating it if it
+// is a nil pointer.
+func setValue(val string, typ reflect.Type, structField reflect.Value, field *fieldInfo) error {
+	if typ == locationType {
+		return setLocationField(val, structField)
+	}
+	// handle ptr field of struct
+	if structField.Kind() == reflect.Ptr {
+		if structField.IsNil() {
+			structField.Set(reflect.New(typ.Elem()))
 		}
+		structField = structField.Elem()
+		typ = typ.Elem()
 	}
-	return nil
+
+	return setWithProperType(typ, val, structField, field)
 }
 
-func setWithProperType(valueType reflect.Type, val string, structField reflect.Value) error {
+func setWithProperType(valueType reflect.Type, val string, structField reflect.Value, field *fieldInfo) error {
+	switch valueType {
+	case timeType:
+		return setTimeField(val, field, structField)
+	case rawMessageType:
+		return setRawMessageField(val, structField)
+	}
+	if isSQLNull(valueType) {
+		return setSQLNullField(val, field, structField)
+	}
+	if ok, err := convertValue(val, structField, field); ok {
+		return err
+	}
 	switch valueType.Kind() {
 	case reflect.Int:
-		return setIntField(val, 0, structField)
+		return setIntField(val, 0, structField, field)
 	case reflect.Int8:
-		return setIntField(val, 8, structField)
+		return setIntField(val, 8, structField, field)
 	case reflect.Int16:
-		return setIntField(val, 16, structField)
+		return setIntField(val, 16, structField, field)
 	case reflect.Int32:
-		return setIntField(val, 32, structField)
+		return setIntField(val, 32, structField, field)
 	case reflect.Int64:
-		return setIntField(val, 64, structField)
+		if valueType == durationType {
+			return setDurationField(val, structField, field)
+		}
+		return setIntField(val, 64, structField, field)
 	case reflect.Uint:
-		return setUintField(val, 0, structField)
+		return setUintField(val, 0, structField, field)
 	case reflect.Uint8:
-		return setUintField(val, 8, structField)
+		return setUintField(val, 8, structField, field)
 	case reflect.Uint16:
-		return setUintField(val, 16, structField)
+		return setUintField(val, 16, structField, field)
 	case reflect.Uint32:
-		return setUintField(val, 32, structField)
+		return setUintField(val, 32, structField, field)
 	case reflect.Uint64:
-		return setUintField(val, 64, structField)
+		return setUintField(val, 64, structField, field)
 	case reflect.Bool:
-		return setBoolField(val, structField)
+		return setBoolField(val, structField, field)
 	case reflect.Float32:
-		return setFloatField(val, 32, structField)
+		return setFloatField(val, 32, structField, field)
 	case reflect.Float64:
-		return setFloatField(val, 64, structField)
+		return setFloatField(val, 64, structField, field)
 	case reflect.String:
+		if err := field.checkPattern(val); err != nil {
+			return err
+		}
 		structField.SetString(val)
-	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	case reflect.Slice:
+		if isBytes(valueType) {
+			return setBytesField(val, structField, field)
+		}
+		if isWeighted(valueType) {
+			return setWeightedField(val, structField)
+		}
+		if field.separator != "" {
+			return setSliceField(splitValues([]string{val}, field.separator), valueType, structField, field)
+		}
+		if times, ok := timeArray([]string{val}, valueType); ok {
+			return setSliceField(times, valueType, structField, field)
+		}
+		if repeatable(valueType.Elem()) && !jsonArray([]string{val}, valueType) {
+			return setSliceField([]string{val}, valueType, structField, field)
+		}
+		if field.maxItems > 0 {
+			if err := checkJSONCount(val, field.maxItems); err != nil {
+				return err
+			}
+		}
+		return setJSONField(val, valueType, structField)
+	case reflect.Array:
+		if repeatable(valueType.Elem()) {
+			if field.separator != "" {
+				return setArrayField(splitValues([]string{val}, field.separator), valueType, structField, field)
+			}
+			if times, ok := timeArray([]string{val}, valueType); ok {
+				return setArrayField(times, valueType, structField, field)
+			}
+			if !jsonArray([]string{val}, valueType) {
+				return setArrayField([]string{val}, valueType, structField, field)
+			}
+		}
+		if err := checkJSONCount(val, valueType.Len()); err != nil {
+			return err
+		}
+		return setJSONField(val, valueType, structField)
+	case reflect.Map:
+		if convertible(valueType.Key()) || convertible(valueType.Elem()) {
+			return setConvertedMapField(val, valueType, structField, field)
+		}
+		return setJSONField(val, valueType, structField)
+	case reflect.Struct:
 		return setJSONField(val, valueType, structField)
 	default:
 		return errors.New("Unknown type")
@@ -125,29 +581,74 @@ func setWithProperType(valueType reflect.Type, val string, structField reflect.V
 	return nil
 }
 
-func setIntField(val string, bitSize int, field reflect.Value) error {
+func setIntField(val string, bitSize int, value reflect.Value, field *fieldInfo) error {
 	if val == "" {
 		val = "0"
 	}
 	intVal, err := strconv.ParseInt(val, 10, bitSize)
-	if err == nil {
-		field.SetInt(intVal)
+	if err != nil {
+		return err
+	}
+	if err := field.checkIntRange(val, intVal); err != nil {
+		return err
 	}
-	return err
+	value.SetInt(intVal)
+	return nil
 }
 
-func setUintField(val string, bitSize int, field reflect.Value) error {
+// setDurationField sets the time.Duration value from val, e.g. 1h30m, or a
+// number of nanoseconds.
+func setDurationField(val string, value reflect.Value, field *fieldInfo) error {
+	if val == "" {
+		val = "0"
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		n, nerr := strconv.ParseInt(val, 10, 64)
+		if nerr != nil {
+			return err
+		}
+		d = time.Duration(n)
+	}
+	if err := field.checkIntRange(val, int64(d)); err != nil {
+		return err
+	}
+	if err := field.checkStep(val, d); err != nil {
+		return err
+	}
+	value.SetInt(int64(d))
+	return nil
+}
+
+func setUintField(val string, bitSize int, value reflect.Value, field *fieldInfo) error {
 	if val == "" {
 		val = "0"
 	}
 	uintVal, err := strconv.ParseUint(val, 10, bitSize)
-	if err == nil {
-		field.SetUint(uintVal)
+	if err != nil {
+		return err
+	}
+	if err := field.checkUintRange(val, uintVal); err != nil {
+		return err
+	}
+	value.SetUint(uintVal)
+	return nil
+}
+
+func (f *fieldInfo) checkPattern(val string) error {
+	if f == nil || f.pattern == nil || f.pattern.MatchString(val) {
+		return nil
 	}
-	return err
+	return &PatternError{Value: val, Pattern: f.pattern.String()}
 }
 
-func setBoolField(val string, field reflect.Value) error {
+func setBoolField(val string, field reflect.Value, info *fieldInfo) error {
+	if info.htmlBool {
+		var err error
+		if val, err = htmlBool(val); err != nil {
+			return err
+		}
+	}
 	if val == "" {
 		val = "false"
 	}
@@ -158,42 +659,136 @@ func setBoolField(val string, field reflect.Value) error {
 	return nil
 }
 
-func setFloatField(val string, bitSize int, field reflect.Value) error {
+// htmlBool returns the boolean val, as sent for HTML checkboxes and by many
+// clients, as true or false: on, yes, y, 1, t and true, in any case, are
+// true, as is the empty value of a key sent without one, e.g. ?remember,
+// while off, no, n, 0, f and false are false.
+func htmlBool(val string) (string, error) {
+	switch strings.ToLower(val) {
+	case "", "on", "yes", "y", "1", "t", "true":
+		return "true", nil
+	case "off", "no", "n", "0", "f", "false":
+		return "false", nil
+	}
+	return "", fmt.Errorf("invalid boolean %q", val)
+}
+
+// boolValued reports whether the values of fields of type typ are booleans,
+// looking through pointers and slices.
+func boolValued(typ reflect.Type) bool {
+	typ = nestedType(typ)
+	if typ.Kind() == reflect.Slice {
+		typ = nestedType(typ.Elem())
+	}
+	if isSQLNull(typ) {
+		typ = typ.Field(0).Type
+	}
+	return typ.Kind() == reflect.Bool
+}
+
+// byteEncodings decode the values of []byte fields by encoding tag, e.g.
+// `encoding:"base64"`. Values are taken as is without one.
+var byteEncodings = map[string]func(string) ([]byte, error){
+	"base64":    base64.StdEncoding.DecodeString,
+	"base64url": base64.URLEncoding.DecodeString,
+	"base32":    base32.StdEncoding.DecodeString,
+	"base58":    decodeBase58,
+	"hex":       hex.DecodeString,
+}
+
+// isBytes reports whether typ is a []byte type, bound from a single value
+// rather than one element per value.
+func isBytes(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 && !convertible(typ.Elem())
+}
+
+// encodeBytes encodes data with encoding, see byteEncodings.
+func encodeBytes(data []byte, encoding string) string {
+	switch encoding {
+	case "base64":
+		return base64.StdEncoding.EncodeToString(data)
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(data)
+	case "base32":
+		return base32.StdEncoding.EncodeToString(data)
+	case "base58":
+		return encodeBase58(data)
+	case "hex":
+		return hex.EncodeToString(data)
+	}
+	return string(data)
+}
+
+func setBytesField(val string, value reflect.Value, field *fieldInfo) error {
+	data := []byte(val)
+	if decode := byteEncodings[field.encoding]; decode != nil {
+		var err error
+		if data, err = decode(val); err != nil {
+			return err
+		}
+	}
+	if field.length > 0 && len(data) != field.length {
+		return &LengthError{Value: val, Len: len(data), Want: field.length}
+	}
+	value.SetBytes(data)
+	return nil
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// setRawMessageField sets the json.RawMessage value to val verbatim, for
+// handlers to decode later, once val is checked to be valid JSON. An empty val
+// leaves it nil.
+func setRawMessageField(val string, value reflect.Value) error {
+	if val == "" {
+		value.SetBytes(nil)
+		return nil
+	}
+	if !json.Valid([]byte(val)) {
+		return fmt.Errorf("invalid JSON %q", val)
+	}
+	value.SetBytes([]byte(val))
+	return nil
+}
+
+func setFloatField(val string, bitSize int, value reflect.Value, field *fieldInfo) error {
 	if val == "" {
 		val = "0.0"
 	}
 	floatVal, err := strconv.ParseFloat(val, bitSize)
-	if err == nil {
-		field.SetFloat(floatVal)
+	if err != nil {
+		return err
+	}
+	if err := field.checkFloatRange(val, floatVal); err != nil {
+		return err
 	}
-	return err
+	value.SetFloat(floatVal)
+	return nil
 }
 
-func setTimeField(val string, structField reflect.StructField, value reflect.Value) error {
-	timeFormat := structField.Tag.Get("time_format")
-	if timeFormat == "" {
-		return errors.New("Blank time format")
-	}
+// defaultTimeFormats are the formats times are parsed with, in order, when
+// their field has no time_format tag.
+var defaultTimeFormats = []string{time.RFC3339, time.RFC3339Nano, "2006-01-02"}
 
+func setTimeField(val string, field *fieldInfo, value reflect.Value) error {
 	if val == "" {
 		value.Set(reflect.ValueOf(time.Time{}))
 		return nil
 	}
 
-	l := time.Local
-	if isUTC, _ := strconv.ParseBool(structField.Tag.Get("time_utc")); isUTC {
-		l = time.UTC
+	formats := defaultTimeFormats
+	if field.timeFormat != "" {
+		formats = []string{field.timeFormat}
 	}
-
-	if locTag := structField.Tag.Get("time_location"); locTag != "" {
-		loc, err := time.LoadLocation(locTag)
-		if err != nil {
-			return err
+	t, err := parseTime(val, formats[0], field.timeLocation)
+	for _, format := range formats[1:] {
+		if err == nil {
+			break
+		}
+		if other, otherErr := parseTime(val, format, field.timeLocation); otherErr == nil {
+			t, err = other, nil
 		}
-		l = loc
 	}
-
-	t, err := time.ParseInLocation(timeFormat, val, l)
 	if err != nil {
 		return err
 	}
@@ -202,6 +797,102 @@ func setTimeField(val string, structField reflect.StructField, value reflect.Val
 	return nil
 }
 
+var locationType = reflect.TypeOf((*time.Location)(nil))
+
+// locations caches the time zones loaded by setLocationField.
+var locations sync.Map // map[string]*time.Location
+
+// setLocationField sets the *time.Location value from val, the IANA name of
+// a time zone such as Europe/Madrid, an empty val leaving it nil.
+func setLocationField(val string, value reflect.Value) error {
+	if val == "" {
+		value.Set(reflect.Zero(locationType))
+		return nil
+	}
+	loc, ok := locations.Load(val)
+	if !ok {
+		l, err := time.LoadLocation(val)
+		if err != nil {
+			return fmt.Errorf("unknown time zone %q", val)
+		}
+		loc, _ = locations.LoadOrStore(val, l)
+	}
+	value.Set(reflect.ValueOf(loc))
+	return nil
+}
+
+// timeFormatAliases are the names time_format tags may use instead of the
+// layouts of the time package.
+var timeFormatAliases = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"rfc822":      time.RFC822,
+	"kitchen":     time.Kitchen,
+	"datetime":    time.DateTime,
+	"dateonly":    time.DateOnly,
+	"timeonly":    time.TimeOnly,
+}
+
+// unixTimeUnits are the units of the time formats of epoch values.
+var unixTimeUnits = map[string]time.Duration{
+	"unix":      time.Second,
+	"unixmilli": time.Millisecond,
+	"unixmicro": time.Microsecond,
+	"unixnano":  time.Nanosecond,
+}
+
+// parseTime parses val with format, a layout or one of unix, unixmilli,
+// unixmicro and unixnano for epoch values, in loc.
+func parseTime(val, format string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	unit, ok := unixTimeUnits[format]
+	if !ok {
+		return time.ParseInLocation(format, val, loc)
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	perSecond := int64(time.Second / unit)
+	return time.Unix(n/perSecond, n%perSecond*int64(unit)).In(loc), nil
+}
+
+// formatTime formats t with format, see parseTime.
+func formatTime(t time.Time, format string) string {
+	switch format {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "unixmicro":
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case "unixnano":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+	return t.Format(format)
+}
+
+// ErrJSONValue is returned for the JSON values of fields when the
+// NoJSONValues option or the nojson tag option is set.
+var ErrJSONValue = errors.New("JSON values are disabled, use the keys of the fields")
+
+// jsonValued reports whether the value of a field of type typ is decoded as
+// JSON, see ErrJSONValue. json.RawMessage fields hold JSON rather than decode
+// it.
+func jsonValued(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == rawMessageType || convertible(typ) {
+		return false
+	}
+	return decodedAsJSON(typ) || typ.Kind() == reflect.Map
+}
+
 // support nested struct/map/slice for GET method, as well as for Content-Type of
 // application/x-www-form-urlencoded, multipart/form-data
 func setJSONField(val string, valueType reflect.Type, field reflect.Value) error {
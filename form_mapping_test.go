@@ -0,0 +1,1088 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForNestedFieldError struct {
+	Nested struct {
+		IntFoo int `form:"int_foo"`
+	}
+}
+
+func TestMapFormFieldError(t *testing.T) {
+	var obj FooBarStructForIntType
+	err := mapForm(&obj, map[string][]string{"int_bar": {"abc"}})
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "int_bar", fieldErr.Path)
+	assert.Equal(t, "int_bar", fieldErr.Key)
+
+	var numErr *strconv.NumError
+	assert.True(t, errors.As(err, &numErr))
+	assert.Equal(t, `binding: field "int_bar": `+numErr.Error(), err.Error())
+}
+
+func TestMapFormNestedFieldError(t *testing.T) {
+	var obj FooStructForNestedFieldError
+	err := mapForm(&obj, map[string][]string{"int_foo": {"abc"}})
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "int_foo", fieldErr.Path)
+	assert.Equal(t, "int_foo", fieldErr.Key)
+	assert.NotContains(t, err.Error(), "(key")
+}
+
+type FooStructForRangeType struct {
+	IntFoo   int     `form:"int_foo" min:"-10" max:"10"`
+	UintFoo  *uint8  `form:"uint_foo" min:"1"`
+	FloatFoo float64 `form:"float_foo" max:"0.5"`
+	IntBar   int     `form:"int_bar" min:"1" default:"5"`
+}
+
+type FooStructForRangeOnString struct {
+	StringFoo string `form:"string_foo" min:"1"`
+}
+
+type FooStructForRangeBadDefault struct {
+	IntFoo int `form:"int_foo" max:"3" default:"5"`
+}
+
+func TestMapFormRange(t *testing.T) {
+	var obj FooStructForRangeType
+	err := mapForm(&obj, map[string][]string{
+		"int_foo":   {"-10"},
+		"uint_foo":  {"255"},
+		"float_foo": {"0.25"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, -10, obj.IntFoo)
+	assert.Equal(t, uint8(255), *obj.UintFoo)
+	assert.Equal(t, 0.25, obj.FloatFoo)
+	assert.Equal(t, 5, obj.IntBar)
+
+	for key, value := range map[string]string{
+		"int_foo":   "11",
+		"uint_foo":  "0",
+		"float_foo": "0.75",
+	} {
+		obj = FooStructForRangeType{IntFoo: 3}
+		err = mapForm(&obj, map[string][]string{key: {value}})
+		var rangeErr *RangeError
+		if assert.True(t, errors.As(err, &rangeErr), key) {
+			assert.Equal(t, value, rangeErr.Value)
+		}
+		assert.Equal(t, 3, obj.IntFoo)
+	}
+
+	err = mapForm(&obj, map[string][]string{"int_foo": {"-11"}})
+	assert.EqualError(t, err, `binding: field "int_foo": value "-11" is out of range [-10, 10]`)
+	err = mapForm(&obj, map[string][]string{"uint_foo": {"0"}})
+	assert.EqualError(t, err, `binding: field "uint_foo": value "0" is less than 1`)
+	err = mapForm(&obj, map[string][]string{"float_foo": {"1"}})
+	assert.EqualError(t, err, `binding: field "float_foo": value "1" is greater than 0.5`)
+}
+
+type FooStructForFloatRange struct {
+	Price float64  `form:"price" min:"0" max:"10"`
+	Ratio *float32 `form:"ratio" min:"0"`
+	Any   float64  `form:"any"`
+}
+
+func TestMapFormRangeNaN(t *testing.T) {
+	for _, form := range []map[string][]string{
+		{"price": {"NaN"}},
+		{"price": {"+Inf"}},
+		{"price": {"-Inf"}},
+		{"ratio": {"NaN"}},
+		{"ratio": {"Inf"}},
+	} {
+		var obj FooStructForFloatRange
+		err := mapForm(&obj, form)
+		var rangeErr *RangeError
+		assert.True(t, errors.As(err, &rangeErr), form)
+	}
+	var obj FooStructForFloatRange
+	assert.EqualError(t, mapForm(&obj, map[string][]string{"price": {"NaN"}}), `binding: field "price": value "NaN" is out of range [0, 10]`)
+	assert.NoError(t, mapForm(&obj, map[string][]string{"any": {"NaN"}}))
+	assert.True(t, math.IsNaN(obj.Any))
+}
+
+func TestMapFormRangeBadTag(t *testing.T) {
+	assert.Panics(t, func() { MustValidateStruct[FooStructForRangeOnString]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForRangeBadDefault]() })
+}
+
+type FooStructForPatternType struct {
+	Slug   string  `form:"slug" pattern:"^[a-z0-9-]{3,32}$"`
+	Handle *string `form:"handle" pattern:"^@[a-z]+$"`
+}
+
+type FooStructForPatternOnInt struct {
+	IntFoo int `form:"int_foo" pattern:"^[0-9]+$"`
+}
+
+type FooStructForBadPattern struct {
+	Slug string `form:"slug" pattern:"^[a-z"`
+}
+
+func TestMapFormPattern(t *testing.T) {
+	var obj FooStructForPatternType
+	err := mapForm(&obj, map[string][]string{"slug": {"my-post-1"}, "handle": {"@manu"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "my-post-1", obj.Slug)
+	assert.Equal(t, "@manu", *obj.Handle)
+
+	obj = FooStructForPatternType{}
+	err = mapForm(&obj, map[string][]string{"slug": {"My Post"}})
+	var patternErr *PatternError
+	assert.True(t, errors.As(err, &patternErr))
+	assert.Equal(t, "My Post", patternErr.Value)
+	assert.EqualError(t, err, `binding: field "slug": value "My Post" does not match pattern "^[a-z0-9-]{3,32}$"`)
+	assert.Equal(t, "", obj.Slug)
+
+	err = mapForm(&obj, map[string][]string{"handle": {"manu"}})
+	assert.Error(t, err)
+
+	info, err := cachedStructInfo(reflect.TypeOf(obj), "")
+	assert.NoError(t, err)
+	assert.NotNil(t, info.fields[0].pattern)
+}
+
+func TestMapFormBadPattern(t *testing.T) {
+	assert.Panics(t, func() { MustValidateStruct[FooStructForPatternOnInt]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadPattern]() })
+}
+
+type FooStructForRepeatedKeys struct {
+	Tags    []string  `form:"tags"`
+	IDs     []int64   `form:"ids"`
+	Ratios  []float64 `form:"ratios"`
+	Flags   []bool    `form:"flags"`
+	Ptrs    []*uint8  `form:"ptrs"`
+	Default []int     `form:"default" default:"7"`
+}
+
+func TestMappingRepeatedKeys(t *testing.T) {
+	var obj FooStructForRepeatedKeys
+	err := mapForm(&obj, map[string][]string{
+		"tags":   {"a", "b"},
+		"ids":    {"1", "-2"},
+		"ratios": {"0.5"},
+		"flags":  {"true", "false", "1"},
+		"ptrs":   {"3"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, obj.Tags)
+	assert.Equal(t, []int64{1, -2}, obj.IDs)
+	assert.Equal(t, []float64{0.5}, obj.Ratios)
+	assert.Equal(t, []bool{true, false, true}, obj.Flags)
+	if assert.Len(t, obj.Ptrs, 1) {
+		assert.Equal(t, uint8(3), *obj.Ptrs[0])
+	}
+	assert.Equal(t, []int{7}, obj.Default)
+
+	obj = FooStructForRepeatedKeys{}
+	err = mapForm(&obj, map[string][]string{"tags": {`["a", "b"]`}, "ids": {"[1, 2]"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, obj.Tags)
+	assert.Equal(t, []int64{1, 2}, obj.IDs)
+
+	obj = FooStructForRepeatedKeys{}
+	err = mapForm(&obj, map[string][]string{"tags": {"[draft]"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"[draft]"}, obj.Tags)
+
+	err = mapForm(&obj, map[string][]string{"ids": {"1", "x"}})
+	assert.Error(t, err)
+	err = mapForm(&obj, map[string][]string{"ptrs": {"256"}})
+	assert.Error(t, err)
+}
+
+type FooStructForFieldErrors struct {
+	A    int `form:"a"`
+	Meta struct {
+		B int `form:"b"`
+	}
+	Address FooStructForAddress `form:"address"`
+	C       int                 `form:"c"`
+}
+
+func TestMappingCollectFieldErrors(t *testing.T) {
+	form := map[string][]string{"c": {"x"}, "b": {"y"}, "a": {"z"}, "address.zip": {"w"}}
+
+	var obj FooStructForFieldErrors
+	err := mapForm(&obj, form)
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "a", fieldErr.Path)
+	}
+
+	defer withOptions(Options{CollectFieldErrors: true})()
+	for i := 0; i < 10; i++ {
+		err = mapForm(&obj, form)
+		var errs FieldErrors
+		if assert.True(t, errors.As(err, &errs)) && assert.Len(t, errs, 4) {
+			assert.Equal(t, "a", errs[0].Path)
+			assert.Equal(t, "b", errs[1].Path)
+			assert.Equal(t, "address.zip", errs[2].Path)
+			assert.Equal(t, "c", errs[3].Path)
+			errs.SortByKey()
+			assert.Equal(t, []string{"a", "address.zip", "b", "c"}, []string{errs[0].Key, errs[1].Key, errs[2].Key, errs[3].Key})
+			errs.SortByPath()
+			assert.Equal(t, []string{"a", "address.zip", "b", "c"}, []string{errs[0].Path, errs[1].Path, errs[2].Path, errs[3].Path})
+		}
+		assert.True(t, errors.As(err, &fieldErr))
+	}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"a": {"1"}}))
+}
+
+type FooStructForMapForm struct {
+	ID        int    `uri:"id" form:"post_id" binding:"required"`
+	Page      int    `form:"page" uri:"-"`
+	RequestID string `header:"X-Request-Id"`
+}
+
+func TestMapForm(t *testing.T) {
+	var obj FooStructForMapForm
+	values := url.Values{"id": {"5"}, "post_id": {"6"}, "page": {"2"}}
+	assert.NoError(t, MapForm(&obj, values, "uri"))
+	assert.Equal(t, 5, obj.ID)
+	assert.Equal(t, 0, obj.Page)
+
+	obj = FooStructForMapForm{}
+	assert.NoError(t, MapForm(&obj, values, "form"))
+	assert.Equal(t, 6, obj.ID)
+	assert.Equal(t, 2, obj.Page)
+
+	obj = FooStructForMapForm{ID: 1}
+	assert.NoError(t, MapForm(&obj, url.Values{"x-request-id": {"abc"}}, "header"))
+	assert.Equal(t, "abc", obj.RequestID)
+
+	assert.Error(t, MapForm(&FooStructForMapForm{}, url.Values{"page": {"2"}}, ""))
+	assert.Error(t, MapForm(&FooStructForMapForm{}, url.Values{"id": {"x"}}, "uri"))
+}
+
+func TestMapFormDerive(t *testing.T) {
+	var obj FooStructForDerive
+	values := url.Values{"email": {"Ada@Example.com"}, "first": {"Ada"}}
+	assert.NoError(t, MapForm(&obj, values, "form"))
+	assert.Equal(t, "ada@example.com", obj.NormalizedEmail)
+	assert.Equal(t, "Ada", obj.FullName)
+
+	err := MapForm(&FooStructForDerive{}, values, "uri")
+	assert.EqualError(t, err, `binding: field "FullName": no name`)
+}
+
+type FooStructForPrefixedMaps struct {
+	Meta   map[string]string `form:"meta"`
+	Limits map[string]int    `form:"limits"`
+}
+
+func TestMappingPrefixedMaps(t *testing.T) {
+	var obj FooStructForPrefixedMaps
+	err := mapForm(&obj, map[string][]string{
+		"meta[env]":        {"prod"},
+		"meta.region":      {"eu"},
+		"meta.app.version": {"1.2"},
+		"limits.rps":       {"100"},
+		"limits[burst]":    {"20"},
+		"meta.":            {"ignored"},
+		"metadata.x":       {"ignored"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "region": "eu", "app.version": "1.2"}, obj.Meta)
+	assert.Equal(t, map[string]int{"rps": 100, "burst": 20}, obj.Limits)
+
+	err = mapForm(&obj, map[string][]string{"limits.rps": {"x"}})
+	assert.Error(t, err)
+}
+
+type FooStructForPagination struct {
+	Page    int `form:"page"`
+	PerPage int `form:"per_page" default:"20"`
+}
+
+type FooStructForAudit struct {
+	By string `form:"by"`
+	At string `form:"at"`
+}
+
+type FooStructForEmbedded struct {
+	FooStructForPagination
+	FooStructForAudit `prefix:"audit_"`
+	Query             string `form:"q"`
+}
+
+type FooStructForPrefixedPagination struct {
+	Paging FooStructForPagination `prefix:"p_"`
+}
+
+func TestMappingPrefixedFieldError(t *testing.T) {
+	var obj FooStructForPrefixedPagination
+	err := mapForm(&obj, map[string][]string{"p_page": {"x"}})
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "p_page", fieldErr.Path)
+		assert.Equal(t, "p_page", fieldErr.Key)
+	}
+
+	err = MapAny(&obj, map[string]interface{}{"p_page": "x"})
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "p_page", fieldErr.Path)
+	}
+}
+
+type FooStructForBadPrefix struct {
+	Query string `form:"q" prefix:"x_"`
+}
+
+func TestMappingEmbedded(t *testing.T) {
+	var obj FooStructForEmbedded
+	err := mapForm(&obj, map[string][]string{
+		"page":     {"2"},
+		"q":        {"go"},
+		"audit_by": {"manu"},
+		"by":       {"ignored"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, obj.Page)
+	assert.Equal(t, 20, obj.PerPage)
+	assert.Equal(t, "go", obj.Query)
+	assert.Equal(t, "manu", obj.By)
+	assert.Equal(t, "", obj.At)
+
+	err = mapForm(&obj, map[string][]string{"audit_at": {"x"}, "page": {"y"}})
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "page", fieldErr.Path)
+	}
+
+	obj = FooStructForEmbedded{}
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"audit_by": "manu", "page": 3}))
+	assert.Equal(t, "manu", obj.By)
+	assert.Equal(t, 3, obj.Page)
+
+	var dst FooStructForEmbedded
+	assert.NoError(t, Copy(&dst, obj))
+	assert.Equal(t, "manu", dst.By)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadPrefix]() })
+}
+
+type FooStructForDuration struct {
+	Timeout  time.Duration   `form:"timeout"`
+	Interval time.Duration   `form:"interval" default:"5m"`
+	Backoff  []time.Duration `form:"backoff"`
+	Delay    *time.Duration  `form:"delay"`
+}
+
+func TestMappingDuration(t *testing.T) {
+	var obj FooStructForDuration
+	err := mapForm(&obj, map[string][]string{
+		"timeout": {"1h30m"},
+		"backoff": {"1s", "500ms", "2000"},
+		"delay":   {"30s"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, obj.Timeout)
+	assert.Equal(t, 5*time.Minute, obj.Interval)
+	assert.Equal(t, []time.Duration{time.Second, 500 * time.Millisecond, 2000}, obj.Backoff)
+	if assert.NotNil(t, obj.Delay) {
+		assert.Equal(t, 30*time.Second, *obj.Delay)
+	}
+
+	err = mapForm(&obj, map[string][]string{"timeout": {"1 hour"}})
+	assert.EqualError(t, err, `binding: field "timeout": time: unknown unit " hour" in duration "1 hour"`)
+}
+
+type FooStructForDurationBounds struct {
+	Retention time.Duration  `form:"retention" min:"1h" max:"720h" step:"1h"`
+	Timeout   *time.Duration `form:"timeout" max:"1000"`
+}
+
+type FooStructForStepOnInt struct {
+	Count int `form:"count" step:"5"`
+}
+
+func TestMappingDurationBounds(t *testing.T) {
+	var obj FooStructForDurationBounds
+	err := mapForm(&obj, map[string][]string{
+		"retention": {"48h"},
+		"timeout":   {"1us"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, obj.Retention)
+	assert.Equal(t, time.Microsecond, *obj.Timeout)
+
+	err = mapForm(&obj, map[string][]string{"retention": {"30m"}})
+	assert.EqualError(t, err, `binding: field "retention": value "30m" is out of range [1h, 720h]`)
+	err = mapForm(&obj, map[string][]string{"retention": {"90m"}})
+	assert.EqualError(t, err, `binding: field "retention": value "90m" is not a multiple of 1h`)
+	var stepErr *StepError
+	assert.True(t, errors.As(err, &stepErr))
+	err = mapForm(&obj, map[string][]string{"timeout": {"2us"}})
+	assert.EqualError(t, err, `binding: field "timeout": value "2us" is greater than 1000`)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForStepOnInt]() })
+}
+
+type FooStructForLocation struct {
+	Zone    *time.Location   `form:"zone"`
+	Zones   []*time.Location `form:"zones"`
+	Default *time.Location   `form:"default" default:"UTC"`
+}
+
+func TestMappingLocation(t *testing.T) {
+	var obj FooStructForLocation
+	err := mapForm(&obj, map[string][]string{"zone": {"Europe/Madrid"}, "zones": {"UTC", "Asia/Tokyo"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Madrid", obj.Zone.String())
+	if assert.Len(t, obj.Zones, 2) {
+		assert.Equal(t, time.UTC, obj.Zones[0])
+		assert.Equal(t, "Asia/Tokyo", obj.Zones[1].String())
+	}
+	assert.Equal(t, time.UTC, obj.Default)
+
+	var again FooStructForLocation
+	assert.NoError(t, mapForm(&again, map[string][]string{"zone": {"Europe/Madrid"}}))
+	assert.Same(t, obj.Zone, again.Zone)
+
+	err = mapForm(&obj, map[string][]string{"zone": {"Mars/Olympus"}})
+	assert.EqualError(t, err, `binding: field "zone": unknown time zone "Mars/Olympus"`)
+
+	var dst struct {
+		Zone string `form:"zone"`
+	}
+	assert.NoError(t, Copy(&dst, again))
+	assert.Equal(t, "Europe/Madrid", dst.Zone)
+	var back FooStructForLocation
+	assert.NoError(t, Copy(&back, dst))
+	assert.Same(t, again.Zone, back.Zone)
+}
+
+type FooStructForUnixTime struct {
+	Seconds time.Time  `form:"s" time_format:"unix" time_utc:"1"`
+	Millis  time.Time  `form:"ms" time_format:"unixmilli" time_utc:"1"`
+	Micros  *time.Time `form:"us" time_format:"unixmicro" time_utc:"1"`
+	Nanos   time.Time  `form:"ns" time_format:"unixnano" time_utc:"1"`
+}
+
+func TestMappingUnixTime(t *testing.T) {
+	var obj FooStructForUnixTime
+	err := mapForm(&obj, map[string][]string{
+		"s":  {"1700000000"},
+		"ms": {"1700000000123"},
+		"us": {"-1500000"},
+		"ns": {"1700000000000000001"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), obj.Seconds)
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 123e6, time.UTC), obj.Millis)
+	assert.Equal(t, time.Date(1969, 12, 31, 23, 59, 58, 500e6, time.UTC), *obj.Micros)
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 1, time.UTC), obj.Nanos)
+
+	err = mapForm(&obj, map[string][]string{"s": {"2023-11-14"}})
+	assert.Error(t, err)
+
+	var dst struct {
+		Millis string `form:"ms" time_format:"unixmilli"`
+	}
+	assert.NoError(t, Copy(&dst, obj))
+	assert.Equal(t, "1700000000123", dst.Millis)
+}
+
+func TestMappingDefaultTimeFormats(t *testing.T) {
+	var obj struct {
+		At      time.Time   `form:"at" time_utc:"1"`
+		Times   []time.Time `form:"times" time_utc:"1"`
+		Default time.Time   `form:"default" default:"2020-02-01" time_utc:"1"`
+	}
+	err := mapForm(&obj, map[string][]string{
+		"at":    {"2017-11-15T10:00:00+01:00"},
+		"times": {"2017-11-15T10:00:00.123456789Z", "2017-11-15"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2017, 11, 15, 9, 0, 0, 0, time.UTC).Equal(obj.At))
+	assert.Equal(t, []time.Time{
+		time.Date(2017, 11, 15, 10, 0, 0, 123456789, time.UTC),
+		time.Date(2017, 11, 15, 0, 0, 0, 0, time.UTC),
+	}, obj.Times)
+	assert.Equal(t, time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), obj.Default)
+
+	err = mapForm(&obj, map[string][]string{"at": {"15/11/2017"}})
+	assert.EqualError(t, err, `binding: field "at": parsing time "15/11/2017" as "2006-01-02T15:04:05Z07:00": cannot parse "15/11/2017" as "2006"`)
+
+	v, err := ConvertString("2017-11-15", reflect.TypeOf(time.Time{}))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 11, 15, 0, 0, 0, 0, time.Local), v.Interface())
+}
+
+func TestMappingTimeFormatAliases(t *testing.T) {
+	var obj struct {
+		Day     time.Time `form:"day" time_format:"dateonly" time_utc:"1"`
+		At      time.Time `form:"at" time_format:"kitchen" time_utc:"1"`
+		Expires time.Time `form:"expires" time_format:"rfc1123"`
+	}
+	err := mapForm(&obj, map[string][]string{
+		"day":     {"2017-11-15"},
+		"at":      {"3:04PM"},
+		"expires": {"Wed, 15 Nov 2017 10:00:00 UTC"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 11, 15, 0, 0, 0, 0, time.UTC), obj.Day)
+	assert.Equal(t, time.Date(0, 1, 1, 15, 4, 0, 0, time.UTC), obj.At)
+	assert.True(t, time.Date(2017, 11, 15, 10, 0, 0, 0, time.UTC).Equal(obj.Expires))
+
+	err = mapForm(&obj, map[string][]string{"day": {"15/11/2017"}})
+	assert.EqualError(t, err, `binding: field "day": parsing time "15/11/2017" as "2006-01-02": cannot parse "15/11/2017" as "2006"`)
+}
+
+func TestMappingTimeSlice(t *testing.T) {
+	var obj struct {
+		Dates []time.Time  `form:"date" time_format:"02/01/2006" time_location:"Asia/Tokyo"`
+		Epoch []*time.Time `form:"epoch" time_format:"unix" time_utc:"1"`
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	want := []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, tokyo), time.Date(2024, 2, 1, 0, 0, 0, 0, tokyo)}
+	err := mapForm(&obj, map[string][]string{"date": {"01/01/2024", "01/02/2024"}, "epoch": {"0"}})
+	assert.NoError(t, err)
+	assert.Equal(t, want, obj.Dates)
+	assert.Equal(t, time.Unix(0, 0).UTC(), *obj.Epoch[0])
+
+	obj.Dates = nil
+	assert.NoError(t, mapForm(&obj, map[string][]string{"date": {`["01/01/2024", "01/02/2024"]`}}))
+	assert.Equal(t, want, obj.Dates)
+
+	obj.Dates = nil
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"date": []interface{}{"01/01/2024", "01/02/2024"}}))
+	assert.Equal(t, want, obj.Dates)
+
+	err = mapForm(&obj, map[string][]string{"date": {"01/01/2024", "2024-02-01"}})
+	assert.EqualError(t, err, `binding: field "date": parsing time "2024-02-01" as "02/01/2006": cannot parse "24-02-01" as "/"`)
+}
+
+type FooStructForHTMLBool struct {
+	Remember bool   `form:"remember" bool_format:"html"`
+	Flags    []bool `form:"flag" bool_format:"html"`
+	Plain    *bool  `form:"plain"`
+}
+
+type FooStructForBadBoolFormat struct {
+	Remember bool `form:"remember" bool_format:"checkbox"`
+}
+
+func TestMappingHTMLBool(t *testing.T) {
+	var obj FooStructForHTMLBool
+	err := mapForm(&obj, map[string][]string{"remember": {""}, "flag": {"on", "OFF", "Yes", "no"}, "plain": {"on"}})
+	assert.NoError(t, err)
+	assert.True(t, obj.Remember)
+	assert.Equal(t, []bool{true, false, true, false}, obj.Flags)
+	assert.False(t, *obj.Plain)
+
+	err = mapForm(&obj, map[string][]string{"remember": {"maybe"}})
+	assert.EqualError(t, err, `binding: field "remember": invalid boolean "maybe"`)
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadBoolFormat]() })
+
+	defer withOptions(Options{HTMLBools: true})()
+	obj = FooStructForHTMLBool{}
+	req := requestWithBody("GET", "/?plain", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.True(t, *obj.Plain)
+	req = requestWithBody("GET", "/?plain=nope", "")
+	assert.EqualError(t, Query.Bind(req, &obj), `binding: field "plain": invalid boolean "nope"`)
+}
+
+type FooStructForCollectionFormat struct {
+	IDs     []int     `form:"ids" collection_format:"csv"`
+	Words   []string  `form:"words" collection_format:"ssv"`
+	Flags   []*bool   `form:"flags" collection_format:"pipes"`
+	Cells   []float64 `form:"cells" collection_format:"tsv"`
+	Default []int     `form:"default" collection_format:"csv" default:"1,2"`
+	Repeat  []int     `form:"repeat" collection_format:"multi"`
+}
+
+type FooStructForBadCollectionFormat struct {
+	IDs []int `form:"ids" collection_format:"semicolons"`
+}
+
+type FooStructForCollectionFormatOnInt struct {
+	ID int `form:"id" collection_format:"csv"`
+}
+
+func TestMappingCollectionFormat(t *testing.T) {
+	var obj FooStructForCollectionFormat
+	err := mapForm(&obj, map[string][]string{
+		"ids":    {"1,2", "3"},
+		"words":  {"hello big world"},
+		"flags":  {"true|false"},
+		"cells":  {"0.5\t1"},
+		"repeat": {"4", "5"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, obj.IDs)
+	assert.Equal(t, []string{"hello", "big", "world"}, obj.Words)
+	if assert.Len(t, obj.Flags, 2) {
+		assert.True(t, *obj.Flags[0])
+		assert.False(t, *obj.Flags[1])
+	}
+	assert.Equal(t, []float64{0.5, 1}, obj.Cells)
+	assert.Equal(t, []int{1, 2}, obj.Default)
+	assert.Equal(t, []int{4, 5}, obj.Repeat)
+
+	obj = FooStructForCollectionFormat{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"ids": {""}}))
+	assert.Empty(t, obj.IDs)
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"ids": "7,8"}))
+	assert.Equal(t, []int{7, 8}, obj.IDs)
+
+	err = mapForm(&obj, map[string][]string{"ids": {"[1,2]"}})
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadCollectionFormat]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForCollectionFormatOnInt]() })
+}
+
+type FooStructForBytes struct {
+	Raw     []byte  `form:"raw"`
+	Base64  []byte  `form:"b64" encoding:"base64"`
+	URL     []byte  `form:"url" encoding:"base64url"`
+	Hex     *[]byte `form:"hex" encoding:"hex"`
+	Default []byte  `form:"default" encoding:"hex" default:"cafe"`
+	Base32  []byte  `form:"b32" encoding:"base32"`
+	Base58  []byte  `form:"b58" encoding:"base58" len:"4"`
+	Key     []byte  `form:"key" encoding:"hex" len:"2"`
+}
+
+type FooStructForLenOnString struct {
+	Key string `form:"key" len:"32"`
+}
+
+type FooStructForBadEncoding struct {
+	Data []byte `form:"data" encoding:"base85"`
+}
+
+type FooStructForEncodingOnInt struct {
+	Data int `form:"data" encoding:"hex"`
+}
+
+func TestMappingBytes(t *testing.T) {
+	var obj FooStructForBytes
+	err := mapForm(&obj, map[string][]string{
+		"raw": {"hello"},
+		"b64": {"aGk/Pz8="},
+		"url": {"aGk_Pz8="},
+		"hex": {"00ff"},
+		"b32": {"NBUT6PZ7"},
+		"b58": {"11A5y"},
+		"key": {"beef"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), obj.Raw)
+	assert.Equal(t, []byte("hi???"), obj.Base64)
+	assert.Equal(t, []byte("hi???"), obj.URL)
+	assert.Equal(t, []byte{0, 0xff}, *obj.Hex)
+	assert.Equal(t, []byte{0xca, 0xfe}, obj.Default)
+	assert.Equal(t, []byte("hi???"), obj.Base32)
+	assert.Equal(t, []byte{0, 0, 0x77, 0x64}, obj.Base58)
+	assert.Equal(t, []byte{0xbe, 0xef}, obj.Key)
+
+	err = mapForm(&obj, map[string][]string{"hex": {"xyz"}})
+	assert.EqualError(t, err, `binding: field "hex": encoding/hex: invalid byte: U+0078 'x'`)
+	err = mapForm(&obj, map[string][]string{"key": {"beef00"}})
+	assert.EqualError(t, err, `binding: field "key": value "beef00" decodes to 3 bytes, want 2`)
+	var lengthErr *LengthError
+	assert.True(t, errors.As(err, &lengthErr))
+	err = mapForm(&obj, map[string][]string{"b58": {"0OIl"}})
+	assert.EqualError(t, err, `binding: field "b58": illegal base58 data at input byte 0`)
+
+	var dst struct {
+		Base64 string `form:"b64" encoding:"base64"`
+		Base58 string `form:"b58" encoding:"base58"`
+		Hex    string `form:"hex"`
+	}
+	assert.NoError(t, Copy(&dst, obj))
+	assert.Equal(t, "aGk/Pz8=", dst.Base64)
+	assert.Equal(t, "11A5y", dst.Base58)
+	assert.Equal(t, "\x00\xff", dst.Hex)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadEncoding]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForEncodingOnInt]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForLenOnString]() })
+}
+
+type FooStructForRawMessage struct {
+	Kind    string          `form:"kind"`
+	Payload json.RawMessage `form:"payload"`
+}
+
+func TestMappingRawMessage(t *testing.T) {
+	var obj FooStructForRawMessage
+	err := mapForm(&obj, map[string][]string{"kind": {"card"}, "payload": {`{"last4": "4242", "exp": [12, 2030]}`}})
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`{"last4": "4242", "exp": [12, 2030]}`), obj.Payload)
+
+	obj = FooStructForRawMessage{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"payload": {""}}))
+	assert.Nil(t, obj.Payload)
+
+	err = mapForm(&obj, map[string][]string{"payload": {`{"last4": `}})
+	assert.EqualError(t, err, `binding: field "payload": invalid JSON "{\"last4\": "`)
+
+	obj = FooStructForRawMessage{}
+	err = MapAny(&obj, map[string]interface{}{"payload": map[string]interface{}{"exp": []interface{}{12.0, 2030.0}}})
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`{"exp":[12,2030]}`), obj.Payload)
+
+	err = MapAny(&obj, map[string]interface{}{"payload": []interface{}{"a", true}})
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`["a",true]`), obj.Payload)
+}
+
+type FooStructForSQLNull struct {
+	Name    sql.NullString   `form:"name"`
+	Age     sql.NullInt64    `form:"age" max:"150"`
+	Score   sql.NullFloat64  `form:"score"`
+	Active  *sql.NullBool    `form:"active"`
+	Born    sql.NullTime     `form:"born" time_format:"dateonly" time_utc:"1"`
+	Level   sql.Null[int16]  `form:"level"`
+	Missing sql.NullString   `form:"missing"`
+	Tags    []sql.NullString `form:"tags"`
+}
+
+func TestMappingSQLNull(t *testing.T) {
+	var obj FooStructForSQLNull
+	err := mapForm(&obj, map[string][]string{
+		"name":   {"manu"},
+		"age":    {""},
+		"score":  {"1.5"},
+		"active": {"true"},
+		"born":   {"2024-02-01"},
+		"level":  {"3"},
+		"tags":   {"a", ""},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullString{String: "manu", Valid: true}, obj.Name)
+	assert.Equal(t, sql.NullInt64{}, obj.Age)
+	assert.Equal(t, sql.NullFloat64{Float64: 1.5, Valid: true}, obj.Score)
+	assert.Equal(t, &sql.NullBool{Bool: true, Valid: true}, obj.Active)
+	assert.Equal(t, sql.NullTime{Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Valid: true}, obj.Born)
+	assert.Equal(t, sql.Null[int16]{V: 3, Valid: true}, obj.Level)
+	assert.Equal(t, sql.NullString{}, obj.Missing)
+	assert.Equal(t, []sql.NullString{{String: "a", Valid: true}, {}}, obj.Tags)
+
+	err = mapForm(&obj, map[string][]string{"age": {"200"}})
+	assert.EqualError(t, err, `binding: field "age": value "200" is greater than 150`)
+
+	var dst struct {
+		Name  string  `form:"name"`
+		Age   *int64  `form:"age"`
+		Score float32 `form:"score"`
+	}
+	assert.NoError(t, Copy(&dst, obj))
+	assert.Equal(t, "manu", dst.Name)
+	assert.Nil(t, dst.Age)
+	assert.Equal(t, float32(1.5), dst.Score)
+
+	var back FooStructForSQLNull
+	assert.NoError(t, Copy(&back, dst))
+	assert.Equal(t, sql.NullString{String: "manu", Valid: true}, back.Name)
+	assert.Equal(t, sql.NullFloat64{Float64: 1.5, Valid: true}, back.Score)
+}
+
+type FooStructForNoJSON struct {
+	Filter FooStructForAddress            `form:"filter,nojson"`
+	IDs    []int                          `form:"ids,nojson"`
+	Meta   map[string]string              `form:"meta,nojson"`
+	Places map[string]FooStructForAddress `form:"places,nojson"`
+	Extra  json.RawMessage                `form:"extra,nojson"`
+}
+
+func TestMappingNoJSON(t *testing.T) {
+	var obj FooStructForNoJSON
+	err := mapForm(&obj, map[string][]string{
+		"filter.city":  {"Oslo"},
+		"ids":          {"1", "2"},
+		"meta[env]":    {"prod"},
+		"places[home]": {"Bergen"},
+		"extra":        {`{"a": 1}`},
+	})
+	assert.EqualError(t, err, `binding: field "places": map key "home": JSON values are disabled, use the keys of the fields`)
+	err = mapForm(&obj, map[string][]string{
+		"filter.city": {"Oslo"},
+		"ids":         {"1", "2"},
+		"meta[env]":   {"prod"},
+		"extra":       {`{"a": 1}`},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Oslo", obj.Filter.City)
+	assert.Equal(t, []int{1, 2}, obj.IDs)
+	assert.Equal(t, map[string]string{"env": "prod"}, obj.Meta)
+	assert.Equal(t, json.RawMessage(`{"a": 1}`), obj.Extra)
+
+	for _, form := range []map[string][]string{
+		{"filter": {`{"city": "Oslo"}`}},
+		{"meta": {`{"env": "prod"}`}},
+	} {
+		err = mapForm(&obj, form)
+		assert.True(t, errors.Is(err, ErrJSONValue), err)
+	}
+	err = mapForm(&obj, map[string][]string{"ids": {"[1, 2]"}})
+	assert.EqualError(t, err, `binding: field "ids": strconv.ParseInt: parsing "[1, 2]": invalid syntax`)
+
+	var dotted FooStructForDotted
+	assert.NoError(t, mapForm(&dotted, map[string][]string{"shipping": {`{"city": "Bergen"}`}}))
+	defer withOptions(Options{NoJSONValues: true})()
+	err = mapForm(&dotted, map[string][]string{"shipping": {`{"city": "Bergen"}`}})
+	assert.True(t, errors.Is(err, ErrJSONValue), err)
+}
+
+type pagingMixin struct {
+	Page int    `form:"page"`
+	Sort string `form:"sort" default:"id"`
+}
+
+type auditMixin struct {
+	By string `form:"by"`
+}
+
+type FooStructForUnexportedEmbedded struct {
+	pagingMixin
+	auditMixin `prefix:"audit_"`
+	*FooStructForAudit
+	Query string `form:"q"`
+}
+
+func TestMappingUnexportedEmbedded(t *testing.T) {
+	var obj FooStructForUnexportedEmbedded
+	err := mapForm(&obj, map[string][]string{
+		"page":     {"2"},
+		"q":        {"go"},
+		"audit_by": {"manu"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, obj.Page)
+	assert.Equal(t, "id", obj.Sort)
+	assert.Equal(t, "manu", obj.auditMixin.By)
+	assert.Equal(t, "go", obj.Query)
+
+	err = mapForm(&obj, map[string][]string{"page": {"x"}})
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "page", fieldErr.Path)
+	}
+
+	req := requestWithBody("GET", "/?page=3&sort=name", "")
+	obj = FooStructForUnexportedEmbedded{}
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, 3, obj.Page)
+	assert.Equal(t, "name", obj.Sort)
+
+	var dst FooStructForUnexportedEmbedded
+	assert.NoError(t, Copy(&dst, obj))
+	assert.Equal(t, 3, dst.Page)
+
+	obj = FooStructForUnexportedEmbedded{}
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"page": 4, "audit_by": "ana"}))
+	assert.Equal(t, 4, obj.Page)
+	assert.Equal(t, "ana", obj.auditMixin.By)
+}
+
+type FooStructForCategory struct {
+	Name   string                `form:"name"`
+	Parent *FooStructForCategory `prefix:"parent_"`
+}
+
+func TestMappingRecursive(t *testing.T) {
+	assert.NotPanics(t, func() { MustValidateStruct[FooStructForCategory]() })
+
+	var obj FooStructForCategory
+	err := mapForm(&obj, map[string][]string{
+		"name":               {"go"},
+		"parent_name":        {"languages"},
+		"parent_parent_name": {"software"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "go", obj.Name)
+	if assert.NotNil(t, obj.Parent) && assert.NotNil(t, obj.Parent.Parent) {
+		assert.Equal(t, "languages", obj.Parent.Name)
+		assert.Equal(t, "software", obj.Parent.Parent.Name)
+		assert.Nil(t, obj.Parent.Parent.Parent)
+	}
+
+	obj = FooStructForCategory{}
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"parent_name": "languages"}))
+	if assert.NotNil(t, obj.Parent) {
+		assert.Equal(t, "languages", obj.Parent.Name)
+		assert.Nil(t, obj.Parent.Parent)
+	}
+
+	var dst FooStructForCategory
+	assert.NoError(t, Copy(&dst, obj))
+	if assert.NotNil(t, dst.Parent) {
+		assert.Equal(t, "languages", dst.Parent.Name)
+		assert.Nil(t, dst.Parent.Parent)
+	}
+
+	defer withOptions(Options{MaxDepth: 2})()
+	obj = FooStructForCategory{}
+	err = mapForm(&obj, map[string][]string{"parent_parent_parent_name": {"x"}})
+	var limitErr *LimitError
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.Equal(t, LimitDepth, limitErr.Limit)
+		assert.Equal(t, int64(2), limitErr.Max)
+	}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"parent_parent_name": {"x"}}))
+}
+
+type FooStructForPointerNested struct {
+	Name     string             `form:"name"`
+	Billing  *FooStructForAudit `prefix:"billing_"`
+	Shipping *FooStructForAudit `prefix:"shipping_"`
+	Next     *FooStructForPointerNested
+}
+
+func TestMappingPointerNested(t *testing.T) {
+	var obj FooStructForPointerNested
+	err := mapForm(&obj, map[string][]string{
+		"name":       {"manu"},
+		"billing_by": {"ana"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "manu", obj.Name)
+	if assert.NotNil(t, obj.Billing) {
+		assert.Equal(t, "ana", obj.Billing.By)
+	}
+	assert.Nil(t, obj.Shipping)
+	assert.Nil(t, obj.Next)
+
+	obj.Billing.At = "now"
+	assert.NoError(t, mapForm(&obj, map[string][]string{"billing_by": {"leo"}}))
+	assert.Equal(t, FooStructForAudit{By: "leo", At: "now"}, *obj.Billing)
+
+	obj = FooStructForPointerNested{}
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"shipping_by": "manu"}))
+	if assert.NotNil(t, obj.Shipping) {
+		assert.Equal(t, "manu", obj.Shipping.By)
+	}
+	assert.Nil(t, obj.Billing)
+
+	var dst FooStructForPointerNested
+	assert.NoError(t, Copy(&dst, obj))
+	if assert.NotNil(t, dst.Shipping) {
+		assert.Equal(t, "manu", dst.Shipping.By)
+	}
+	assert.Nil(t, dst.Billing)
+}
+
+type FooStructForFormTagFirst struct {
+	UserID  int                 `json:"userId" form:"user_id"`
+	Name    string              `json:"name,omitempty"`
+	Address FooStructForAddress `json:"address" form:"addr"`
+}
+
+func TestMappingFormTagFirst(t *testing.T) {
+	form := map[string][]string{
+		"userId":       {"1"},
+		"user_id":      {"2"},
+		"name":         {"Ada"},
+		"address.city": {"Oslo"},
+		"addr.city":    {"Bergen"},
+	}
+	var obj FooStructForFormTagFirst
+	assert.NoError(t, mapForm(&obj, form))
+	assert.Equal(t, 1, obj.UserID)
+	assert.Equal(t, "Ada", obj.Name)
+	assert.Equal(t, "Oslo", obj.Address.City)
+
+	defer withOptions(Options{FormTagFirst: true})()
+	obj = FooStructForFormTagFirst{}
+	assert.NoError(t, mapForm(&obj, form))
+	assert.Equal(t, 2, obj.UserID)
+	assert.Equal(t, "Ada", obj.Name)
+	assert.Equal(t, "Bergen", obj.Address.City)
+
+	obj = FooStructForFormTagFirst{}
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"user_id": 3, "userId": 4}))
+	assert.Equal(t, 3, obj.UserID)
+}
+
+type FooStructForCounts struct {
+	RGB    [3]uint8  `form:"rgb"`
+	Point  [2]int    `form:"point" collection_format:"csv"`
+	Tags   []string  `form:"tags" max_items:"2"`
+	Scores []float64 `form:"scores" max_items:"1"`
+}
+
+func TestMappingCounts(t *testing.T) {
+	var obj FooStructForCounts
+	assert.NoError(t, mapForm(&obj, map[string][]string{
+		"rgb":    {"255", "128"},
+		"point":  {"1,2"},
+		"tags":   {"a", "b"},
+		"scores": {"[0.5]"},
+	}))
+	assert.Equal(t, [3]uint8{255, 128, 0}, obj.RGB)
+	assert.Equal(t, [2]int{1, 2}, obj.Point)
+	assert.Equal(t, []string{"a", "b"}, obj.Tags)
+	assert.Equal(t, []float64{0.5}, obj.Scores)
+
+	for form, expected := range map[string]string{
+		"rgb=1&rgb=2&rgb=3&rgb=4": `binding: field "rgb": 4 values received, 3 accepted, dropped ["4"]`,
+		"rgb=[1,2,3,4,5]":         `binding: field "rgb": 5 values received, 3 accepted, dropped ["4" "5"]`,
+		"point=1,2,3":             `binding: field "point": 3 values received, 2 accepted, dropped ["3"]`,
+		"tags=a&tags=b&tags=c":    `binding: field "tags": 3 values received, 2 accepted, dropped ["c"]`,
+		"scores=[1,2.5]":          `binding: field "scores": 2 values received, 1 accepted, dropped ["2.5"]`,
+	} {
+		values, err := url.ParseQuery(form)
+		assert.NoError(t, err)
+		err = mapForm(&obj, values)
+		assert.EqualError(t, err, expected, form)
+		var countErr *CountError
+		assert.True(t, errors.As(err, &countErr), form)
+	}
+}
+
+type FooStructForCaseInsensitive struct {
+	UserID int    `form:"user_id"`
+	Name   string `form:"name" alias:"login"`
+}
+
+func TestMappingCaseInsensitiveKeys(t *testing.T) {
+	var obj FooStructForCaseInsensitive
+	assert.NoError(t, mapForm(&obj, map[string][]string{"USER_ID": {"1"}, "Name": {"ada"}}))
+	assert.Equal(t, FooStructForCaseInsensitive{}, obj)
+
+	defer withOptions(Options{CaseInsensitiveKeys: true})()
+	assert.NoError(t, mapForm(&obj, map[string][]string{"USER_ID": {"1"}, "Name": {"ada"}}))
+	assert.Equal(t, FooStructForCaseInsensitive{UserID: 1, Name: "ada"}, obj)
+
+	obj = FooStructForCaseInsensitive{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{
+		"User_Id": {"1"},
+		"user_id": {"2"},
+		"USER_ID": {"3"},
+		"LOGIN":   {"grace"},
+	}))
+	assert.Equal(t, FooStructForCaseInsensitive{UserID: 2, Name: "grace"}, obj)
+
+	obj = FooStructForCaseInsensitive{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"User_Id": {"1"}, "USER_ID": {"3"}, "login": {"grace"}, "NAME": {"ada"}}))
+	assert.Equal(t, FooStructForCaseInsensitive{UserID: 3, Name: "grace"}, obj)
+}
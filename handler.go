@@ -0,0 +1,94 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Handler returns an http.HandlerFunc binding each request onto a new T, with
+// the binding handling its method and content type, and passing it to fn. If
+// binding or validation fails, fn isn't called and a 400 Bad Request is
+// written with a JSON body such as
+//
+//	{"error": "binding: field \"age\": ...", "field": "age"}
+//
+// where field is only set for errors about a single field.
+func Handler[T any](fn func(http.ResponseWriter, *http.Request, T)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var obj T
+		d := Decoder{Options: DefaultOptions}
+		if err := d.Bind(r, &obj); err != nil {
+			WriteError(w, err)
+			return
+		}
+		fn(w, r, obj)
+	}
+}
+
+// bindErrorBody is the body written by WriteError.
+type bindErrorBody struct {
+	Error string `json:"error,omitempty"`
+	Field string `json:"field,omitempty"`
+	// Type, Title, Status and Detail are the members of RFC 7807 problem
+	// details, set instead of Error for MIMEProblemJSON.
+	Type   string `json:"type,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	// Limit, Max and Observed describe a *LimitError.
+	Limit    string `json:"limit,omitempty"`
+	Max      int64  `json:"max,omitempty"`
+	Observed int64  `json:"observed,omitempty"`
+}
+
+// WriteError writes err as the response of a failed bind, as Handler does, with
+// the status given by StatusCode, e.g. 400 Bad Request. A *LimitError is
+// written with a Retry-After header if it suggests one, and a
+// *ContentTypeError with the header set by SetAcceptHeader. Errors are written
+// as JSON, or as the media type carried by a *NegotiatedError: plain text, or
+// problem details such as
+//
+//	{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "binding: ...", "field": "age"}
+func WriteError(w http.ResponseWriter, err error) {
+	body := bindErrorBody{Error: err.Error()}
+	status := StatusCode(err)
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		body.Field = fieldErr.Path
+	}
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		body.Limit, body.Max, body.Observed = limitErr.Limit, limitErr.Max, limitErr.Observed
+		if limitErr.RetryAfter > 0 {
+			seconds := (limitErr.RetryAfter + time.Second - 1) / time.Second
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+		}
+	}
+	var typeErr *ContentTypeError
+	if errors.As(err, &typeErr) {
+		SetAcceptHeader(w.Header(), typeErr.Method, typeErr.Accepted...)
+	}
+	switch mediaType := ErrorMediaType(err); mediaType {
+	case MIMEPlain:
+		w.Header().Set("Content-Type", MIMEPlain+"; charset=utf-8")
+		w.WriteHeader(status)
+		io.WriteString(w, body.Error+"\n")
+		return
+	case MIMEProblemJSON:
+		body.Type, body.Title, body.Status = "about:blank", http.StatusText(status), status
+		body.Detail, body.Error = body.Error, ""
+		w.Header().Set("Content-Type", mediaType)
+	default:
+		w.Header().Set("Content-Type", MIMEJSON+"; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
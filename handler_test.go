@@ -0,0 +1,59 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	h := Handler(func(w http.ResponseWriter, r *http.Request, obj FooBarStructForIntType) {
+		w.WriteHeader(http.StatusNoContent)
+		assert.Equal(t, 1, obj.IntFoo)
+		assert.Equal(t, 2, obj.IntBar)
+	})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/?int_foo=1&int_bar=2", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Content-Type", MIMEJSON)
+	h(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error": "binding: empty request body"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/?int_foo=x", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"int_foo"`)
+}
+
+func TestWriteErrorContentType(t *testing.T) {
+	req := httptest.NewRequest("PATCH", "/", nil)
+	req.Header.Set("Content-Type", MIMEPlain)
+	err := NewDecoder(Options{UnknownContentType: ContentTypeReject}).Bind(req, &FooStruct{})
+
+	w := httptest.NewRecorder()
+	WriteError(w, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, strings.Join(SupportedMediaTypes(), ", "), w.Header().Get("Accept-Patch"))
+	assert.JSONEq(t, `{"error": "binding: unsupported content type \"text/plain\""}`, w.Body.String())
+}
+
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, StatusCode(errors.New("binding: bad")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, StatusCode(fmt.Errorf("wrapped: %w", &LimitError{Limit: LimitBodySize})))
+	assert.Equal(t, http.StatusUnsupportedMediaType, StatusCode(&ContentTypeError{}))
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strings"
+)
+
+// Header binds the fields tagged with header from the request headers,
+// matched case-insensitively, e.g.
+//
+//	RequestID string `header:"X-Request-Id"`
+var Header = headerBinding{}
+
+type headerBinding struct{}
+
+func (headerBinding) Name() string {
+	return "header"
+}
+
+func (b headerBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (headerBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	m := &formMapper{form: req.Header, tag: "header", state: s}
+	if s.opts.CheckHeaders && s.checking() {
+		m.used = make(map[string]bool)
+	}
+	if err := m.mapStruct(reflect.ValueOf(obj).Elem(), ""); err != nil {
+		return err
+	}
+	if m.used != nil {
+		s.warnUnexpectedHeaders(req.Header, m.used)
+	}
+	return s.validate(obj)
+}
+
+// warnUnexpectedHeaders reports every custom header of h which wasn't used
+// nor is allowed by Options.AllowedHeaders, in key order.
+func (s *bindState) warnUnexpectedHeaders(h http.Header, used map[string]bool) {
+	allowed := make(map[string]bool, len(s.opts.AllowedHeaders))
+	for _, key := range s.opts.AllowedHeaders {
+		allowed[textproto.CanonicalMIMEHeaderKey(key)] = true
+	}
+	for _, key := range sortedKeys(h) {
+		if !strings.HasPrefix(key, "X-") || used[key] || allowed[key] {
+			continue
+		}
+		s.warn(Warning{
+			Kind:    WarningUnexpectedHeader,
+			Key:     key,
+			Message: fmt.Sprintf("unexpected header %q", key),
+		})
+	}
+}
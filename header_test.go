@@ -0,0 +1,54 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForHeaders struct {
+	RequestID string `header:"X-Request-Id"`
+	Accept    string `header:"accept"`
+}
+
+func createHeadersRequest() *http.Request {
+	req := requestWithBody("GET", "/", "")
+	req.Header.Set("X-Request-Id", "42")
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	req.Header.Set("X-Debug", "1")
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "test")
+	return req
+}
+
+func TestBindHeaderUnexpected(t *testing.T) {
+	var obj FooStructForHeaders
+	warnings, err := BindWithWarnings(createHeadersRequest(), &obj, Header)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	defer withOptions(Options{CheckHeaders: true, AllowedHeaders: []string{"x-forwarded-for"}})()
+	warnings, err = BindWithWarnings(createHeadersRequest(), &obj, Header)
+	assert.NoError(t, err)
+	assert.Equal(t, "42", obj.RequestID)
+	assert.Equal(t, Warnings{{Kind: WarningUnexpectedHeader, Key: "X-Debug", Message: `unexpected header "X-Debug"`}}, warnings)
+	assert.NoError(t, Header.Bind(createHeadersRequest(), &obj))
+}
+
+func TestBindHeaderUnexpectedStrict(t *testing.T) {
+	defer withOptions(Options{CheckHeaders: true, Strict: StrictEnforce})()
+	var obj FooStructForHeaders
+	err := Header.Bind(createHeadersRequest(), &obj)
+	var strictErr *StrictError
+	if assert.True(t, errors.As(err, &strictErr)) {
+		assert.Len(t, strictErr.Violations, 2)
+		assert.Equal(t, "X-Debug", strictErr.Violations[0].Key)
+		assert.Equal(t, "X-Forwarded-For", strictErr.Violations[1].Key)
+	}
+}
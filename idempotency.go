@@ -0,0 +1,70 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+)
+
+// Fingerprint identifies a bound request for idempotency checks, see
+// BindWithFingerprint.
+type Fingerprint struct {
+	// Body is the hex SHA-256 of the body bytes consumed by the binding.
+	Body string
+	// Struct is the deterministic serialization of the bound struct: its
+	// JSON encoding, with map keys sorted.
+	Struct []byte
+}
+
+// Sum returns the hex SHA-256 of the fingerprint, to be stored along with the
+// idempotency key of a request.
+func (f *Fingerprint) Sum() string {
+	h := sha256.New()
+	io.WriteString(h, f.Body)
+	h.Write([]byte{0})
+	h.Write(f.Struct)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Equal reports whether f and other fingerprint identical requests.
+func (f *Fingerprint) Equal(other *Fingerprint) bool {
+	return f.Sum() == other.Sum()
+}
+
+// BindWithFingerprint binds the request with b like b.Bind does, additionally
+// returning its fingerprint, so that idempotency middleware can check that a
+// retried request is identical to the original one without buffering the
+// body itself: the body is hashed while the binding reads it.
+func BindWithFingerprint(req *http.Request, obj interface{}, b Binding) (*Fingerprint, error) {
+	h := sha256.New()
+	if req.Body != nil {
+		req.Body = hashedBody{req.Body, h}
+	}
+	if err := b.Bind(req, obj); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &Fingerprint{Body: hex.EncodeToString(h.Sum(nil)), Struct: data}, nil
+}
+
+// hashedBody hashes the bytes read from a body.
+type hashedBody struct {
+	io.ReadCloser
+	h hash.Hash
+}
+
+func (b hashedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.h.Write(p[:n])
+	return n, err
+}
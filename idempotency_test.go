@@ -0,0 +1,49 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForFingerprint struct {
+	Amount int               `json:"amount" form:"amount"`
+	Meta   map[string]string `json:"meta" form:"meta"`
+}
+
+func TestBindWithFingerprint(t *testing.T) {
+	bind := func(body string) *Fingerprint {
+		req := requestWithBody("POST", "/", body)
+		var obj FooStructForFingerprint
+		f, err := BindWithFingerprint(req, &obj, JSON)
+		assert.NoError(t, err)
+		return f
+	}
+	f := bind(`{"amount": 10, "meta": {"b": "2", "a": "1"}}`)
+	assert.Equal(t, `{"amount":10,"meta":{"a":"1","b":"2"}}`, string(f.Struct))
+	assert.Equal(t, "ca35bd3fddf31718191dd9b9b25779ec7b62fe140677a057717b0b8abbcff7f3", f.Body)
+	assert.Len(t, f.Sum(), 64)
+
+	assert.True(t, f.Equal(bind(`{"amount": 10, "meta": {"b": "2", "a": "1"}}`)))
+	// same struct, different bytes
+	assert.False(t, f.Equal(bind(`{"meta": {"a": "1", "b": "2"}, "amount": 10}`)))
+	assert.False(t, f.Equal(bind(`{"amount": 11, "meta": {"b": "2", "a": "1"}}`)))
+}
+
+func TestBindWithFingerprintNoBody(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?amount=5", nil)
+	var obj FooStructForFingerprint
+	f, err := BindWithFingerprint(req, &obj, Form)
+	assert.NoError(t, err)
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", f.Body)
+	assert.Equal(t, `{"amount":5,"meta":null}`, string(f.Struct))
+
+	req = requestWithBody("POST", "/", `{"amount": "x"}`)
+	_, err = BindWithFingerprint(req, &obj, JSON)
+	assert.Error(t, err)
+}
@@ -0,0 +1,266 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// implementations holds the registered implementations of interface types,
+// see RegisterImplementations.
+var implementations sync.Map // map[reflect.Type]*implSet
+
+// implSet is the set of implementations registered for an interface type.
+type implSet struct {
+	types []reflect.Type
+	// key is the discriminator key choosing the types by name, empty if
+	// they are tried in order.
+	key   string
+	names map[string]reflect.Type
+}
+
+// RegisterImplementations registers the types of impls as the concrete types
+// form bindings bind fields of the interface type I into, tried in order,
+// e.g.
+//
+//	binding.RegisterImplementations[Shape](Circle{}, &Square{})
+//
+// A field is bound from the value of its key like a field of the first type
+// it converts to would be, JSON objects having to match the fields of the
+// type, or from the keys naming the fields of the first struct type which
+// knows them all in dot or bracket notation, e.g. shape.radius=2.
+// Implementations are meant to be registered at init time.
+func RegisterImplementations[I any](impls ...I) {
+	set := &implSet{}
+	for _, impl := range impls {
+		set.types = append(set.types, implementationType[I](impl))
+	}
+	implementations.Store(interfaceType[I](), set)
+}
+
+// RegisterDiscriminated registers the types of impls as the concrete types
+// form bindings bind fields of the interface type I into, chosen by their
+// name in impls, read from the discriminator key after the key of the field
+// or from the member key of its JSON object, e.g. shape.kind=circle or
+// shape={"kind": "circle", "radius": 2} for
+//
+//	binding.RegisterDiscriminated[Shape]("kind", map[string]Shape{
+//		"circle": Circle{},
+//		"square": &Square{},
+//	})
+//
+// Fields with a disc tag read the name from a sibling key instead, e.g.
+// kind=circle&shape.radius=2 for
+//
+//	Kind  string `form:"kind"`
+//	Shape Shape  `form:"shape" disc:"kind"`
+//
+// in which case the implementation is bound even without any key of its own.
+func RegisterDiscriminated[I any](key string, impls map[string]I) {
+	set := &implSet{key: key, names: make(map[string]reflect.Type, len(impls))}
+	for name, impl := range impls {
+		set.names[name] = implementationType[I](impl)
+	}
+	for _, name := range sortedKeys(set.names) {
+		set.types = append(set.types, set.names[name])
+	}
+	implementations.Store(interfaceType[I](), set)
+}
+
+func interfaceType[I any]() reflect.Type {
+	typ := reflect.TypeOf((*I)(nil)).Elem()
+	if typ.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("binding: %s is not an interface", typ))
+	}
+	return typ
+}
+
+func implementationType[I any](impl I) reflect.Type {
+	typ := reflect.TypeOf(impl)
+	if typ == nil {
+		panic(fmt.Sprintf("binding: nil implementation of %s", interfaceType[I]()))
+	}
+	return typ
+}
+
+// implementationsOf returns the implementations registered for typ, or nil.
+func implementationsOf(typ reflect.Type) *implSet {
+	if typ.Kind() != reflect.Interface {
+		return nil
+	}
+	if set, ok := implementations.Load(typ); ok {
+		return set.(*implSet)
+	}
+	return nil
+}
+
+// setImplementation binds the interface field to one of the implementations
+// registered for its type.
+func (m *formMapper) setImplementation(field *fieldInfo, impls *implSet, structField reflect.Value, path string) error {
+	values, exists := m.lookup(field)
+	var val string
+	if exists {
+		var err error
+		if val, err = m.state.transform(values[0], field, StageLookup); err != nil {
+			return err
+		}
+	}
+	sub := m.sub(field.key)
+	if field.disc != "" {
+		return m.setDiscriminated(field, impls, val, exists, sub, structField, path)
+	}
+	if !exists && sub.form == nil {
+		return nil
+	}
+
+	types := impls.types
+	if impls.key != "" {
+		name, ok := m.discriminator(impls.key, val, exists, sub)
+		if !ok {
+			return fmt.Errorf("missing %s", impls.key)
+		}
+		typ, ok := impls.names[name]
+		if !ok {
+			return fmt.Errorf("unknown %s %q", impls.key, name)
+		}
+		types = []reflect.Type{typ}
+	}
+
+	if exists {
+		var firstErr error
+		for _, typ := range types {
+			if m.noJSON(field) && jsonValued(typ) {
+				if firstErr == nil {
+					firstErr = ErrJSONValue
+				}
+				continue
+			}
+			v := reflect.New(typ).Elem()
+			err := decodeImplementation(val, typ, v, field, impls.key == "")
+			if err == nil {
+				structField.Set(v)
+				return nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	for _, typ := range types {
+		if !dotted(typ) || impls.key == "" && !knowsKeys(typ, sub.form, m.tag, m.state.opts.keySeparator()) {
+			continue
+		}
+		v := reflect.New(typ).Elem()
+		target := v
+		if typ.Kind() == reflect.Ptr {
+			v.Set(reflect.New(typ.Elem()))
+			target = v.Elem()
+		}
+		err := m.mapSub(sub, target, m.keyPath(path, field.key))
+		structField.Set(v)
+		return err
+	}
+	return fmt.Errorf("no implementation of %s binds its keys", structField.Type())
+}
+
+// setDiscriminated binds the interface field, whose disc tag is set, to the
+// implementation named by the value of its disc key, from val if exists is
+// set or from sub.
+func (m *formMapper) setDiscriminated(field *fieldInfo, impls *implSet, val string, exists bool, sub *formMapper, structField reflect.Value, path string) error {
+	names := m.form[field.disc]
+	if len(names) == 0 {
+		if !exists && sub.form == nil {
+			return nil
+		}
+		return fmt.Errorf("missing %s", field.disc)
+	}
+	m.markUsed(field.disc)
+	if impls.names == nil {
+		return fmt.Errorf("%s has no named implementations", structField.Type())
+	}
+	typ, ok := impls.names[names[0]]
+	if !ok {
+		return fmt.Errorf("unknown %s %q", field.disc, names[0])
+	}
+
+	v := reflect.New(typ).Elem()
+	if exists {
+		if m.noJSON(field) && jsonValued(typ) {
+			return ErrJSONValue
+		}
+		if err := decodeImplementation(val, typ, v, field, false); err != nil {
+			return err
+		}
+		structField.Set(v)
+		return nil
+	}
+	if !dotted(typ) {
+		return fmt.Errorf("%s %q binds no keys", field.disc, names[0])
+	}
+	target := v
+	if typ.Kind() == reflect.Ptr {
+		v.Set(reflect.New(typ.Elem()))
+		target = v.Elem()
+	}
+	err := m.mapSub(sub, target, m.keyPath(path, field.key))
+	structField.Set(v)
+	return err
+}
+
+// discriminator returns the name of the implementation bound, from the
+// discriminator key of the JSON object val if exists is set, or from the
+// discriminator key of sub.
+func (m *formMapper) discriminator(key, val string, exists bool, sub *formMapper) (string, bool) {
+	if exists {
+		var object map[string]json.RawMessage
+		var name string
+		if json.Unmarshal([]byte(val), &object) != nil || json.Unmarshal(object[key], &name) != nil {
+			return "", false
+		}
+		return name, true
+	}
+	values := sub.form[key]
+	if len(values) == 0 {
+		return "", false
+	}
+	m.markUsed(sub.keys[key])
+	return values[0], true
+}
+
+// knowsKeys reports whether the struct typ binds every key of form, with the
+// struct metadata of tag and the key separator sep.
+func knowsKeys(typ reflect.Type, form map[string][]string, tag, sep string) bool {
+	keys := newFormKeys(nestedType(typ), tag, sep)
+	for key := range form {
+		if !keys.wants(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeImplementation sets v, of type typ, from val. JSON objects have to
+// match the fields of typ if strict is set.
+func decodeImplementation(val string, typ reflect.Type, v reflect.Value, field *fieldInfo, strict bool) error {
+	if !decodedAsJSON(typ) {
+		return setValue(val, typ, v, field)
+	}
+	dec := json.NewDecoder(strings.NewReader(val))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	ptr := reflect.New(typ)
+	if err := dec.Decode(ptr.Interface()); err != nil {
+		return err
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
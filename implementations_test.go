@@ -0,0 +1,160 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testShape interface {
+	Area() float64
+}
+
+type testCircle struct {
+	Radius float64 `json:"radius" form:"radius"`
+}
+
+func (c testCircle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type testSquare struct {
+	Side float64 `json:"side" form:"side"`
+}
+
+func (s *testSquare) Area() float64 { return s.Side * s.Side }
+
+// testTiles is a shape bound from a plain number.
+type testTiles int
+
+func (t testTiles) Area() float64 { return float64(t) }
+
+type testPayment interface {
+	isPayment()
+}
+
+type testCard struct {
+	Kind   string `json:"kind" form:"kind"`
+	Number string `json:"number" form:"number"`
+}
+
+func (testCard) isPayment() {}
+
+type testTransfer struct {
+	IBAN string `json:"iban" form:"iban"`
+}
+
+func (*testTransfer) isPayment() {}
+
+func init() {
+	RegisterImplementations[testShape](testCircle{}, &testSquare{}, testTiles(0))
+	RegisterDiscriminated[testPayment]("kind", map[string]testPayment{
+		"card":     testCard{},
+		"transfer": &testTransfer{},
+	})
+}
+
+type FooStructForImplementations struct {
+	Shape   testShape   `form:"shape"`
+	Payment testPayment `form:"payment"`
+}
+
+func TestMappingImplementations(t *testing.T) {
+	for _, tt := range []struct {
+		form map[string][]string
+		want testShape
+	}{
+		{map[string][]string{"shape": {`{"radius": 2}`}}, testCircle{2}},
+		{map[string][]string{"shape": {`{"side": 2}`}}, &testSquare{2}},
+		{map[string][]string{"shape": {"4"}}, testTiles(4)},
+		{map[string][]string{"shape.radius": {"3"}}, testCircle{3}},
+		{map[string][]string{"shape[side]": {"5"}}, &testSquare{5}},
+	} {
+		var obj FooStructForImplementations
+		assert.NoError(t, mapForm(&obj, tt.form))
+		assert.Equal(t, tt.want, obj.Shape)
+	}
+
+	var obj FooStructForImplementations
+	assert.NoError(t, mapForm(&obj, map[string][]string{}))
+	assert.Nil(t, obj.Shape)
+	err := mapForm(&obj, map[string][]string{"shape.color": {"red"}})
+	assert.EqualError(t, err, `binding: field "shape": no implementation of binding.testShape binds its keys`)
+}
+
+func TestMappingDiscriminated(t *testing.T) {
+	var obj FooStructForImplementations
+	err := mapForm(&obj, map[string][]string{"payment.kind": {"card"}, "payment.number": {"42"}})
+	assert.NoError(t, err)
+	assert.Equal(t, testCard{Kind: "card", Number: "42"}, obj.Payment)
+
+	err = mapForm(&obj, map[string][]string{"payment": {`{"kind": "transfer", "iban": "NO93"}`}})
+	assert.NoError(t, err)
+	assert.Equal(t, &testTransfer{IBAN: "NO93"}, obj.Payment)
+
+	err = mapForm(&obj, map[string][]string{"payment[kind]": {"cash"}})
+	assert.EqualError(t, err, `binding: field "payment": unknown kind "cash"`)
+	err = mapForm(&obj, map[string][]string{"payment[iban]": {"NO93"}})
+	assert.EqualError(t, err, `binding: field "payment": missing kind`)
+}
+
+func TestBindImplementationsStrict(t *testing.T) {
+	defer withOptions(Options{Strict: StrictEnforce})()
+	var obj FooStructForImplementations
+	req := requestWithBody("GET", "/?payment.kind=transfer&payment.iban=NO93", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, &testTransfer{IBAN: "NO93"}, obj.Payment)
+}
+
+type FooStructForDisc struct {
+	Method  string      `form:"method"`
+	Payment testPayment `form:"payment" disc:"method"`
+}
+
+type FooStructForDiscUnnamed struct {
+	Kind  string    `form:"kind"`
+	Shape testShape `form:"shape" disc:"kind"`
+}
+
+type FooStructForDiscOnString struct {
+	Kind string `form:"kind" disc:"type"`
+}
+
+func TestMappingDisc(t *testing.T) {
+	for _, tt := range []struct {
+		form map[string][]string
+		want testPayment
+	}{
+		{map[string][]string{"method": {"card"}, "payment.number": {"42"}}, testCard{Number: "42"}},
+		{map[string][]string{"method": {"transfer"}, "payment[iban]": {"NO93"}}, &testTransfer{IBAN: "NO93"}},
+		{map[string][]string{"method": {"card"}, "payment": {`{"number": "7", "cvc": "123"}`}}, testCard{Number: "7"}},
+		{map[string][]string{"method": {"transfer"}}, &testTransfer{}},
+		{map[string][]string{}, nil},
+	} {
+		var obj FooStructForDisc
+		assert.NoError(t, mapForm(&obj, tt.form))
+		assert.Equal(t, tt.want, obj.Payment)
+	}
+
+	var obj FooStructForDisc
+	err := mapForm(&obj, map[string][]string{"payment.number": {"42"}})
+	assert.EqualError(t, err, `binding: field "payment": missing method`)
+	err = mapForm(&obj, map[string][]string{"method": {"cash"}})
+	assert.EqualError(t, err, `binding: field "payment": unknown method "cash"`)
+
+	var unnamed FooStructForDiscUnnamed
+	err = mapForm(&unnamed, map[string][]string{"kind": {"circle"}, "shape.radius": {"2"}})
+	assert.EqualError(t, err, `binding: field "shape": binding.testShape has no named implementations`)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForDiscOnString]() })
+}
+
+func TestBindDiscStrict(t *testing.T) {
+	defer withOptions(Options{Strict: StrictEnforce})()
+	var obj FooStructForDisc
+	req := requestWithBody("GET", "/?method=transfer&payment.iban=NO93", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, &testTransfer{IBAN: "NO93"}, obj.Payment)
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strings"
+)
+
+// fieldSource is a source of the value of a field, see compileSources.
+type fieldSource struct {
+	// kind is one of header, query, cookie and default.
+	kind string
+	// key is the key looked up, or the value itself for default.
+	key string
+}
+
+// compileSources parses the in tag of a field, listing the sources its value
+// is resolved from by BindRequest, the first one present winning, e.g.
+//
+//	Tenant string `in:"header=X-Tenant-ID,query=tenant,default=public"`
+func compileSources(tag string) ([]fieldSource, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	var sources []fieldSource
+	for _, part := range strings.Split(tag, ",") {
+		i := strings.IndexByte(part, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid source %q", part)
+		}
+		src := fieldSource{kind: strings.TrimSpace(part[:i]), key: strings.TrimSpace(part[i+1:])}
+		switch src.kind {
+		case "header":
+			src.key = textproto.CanonicalMIMEHeaderKey(src.key)
+		case "query", "cookie", "default":
+		default:
+			return nil, fmt.Errorf("unknown source %q", src.kind)
+		}
+		if src.key == "" && src.kind != "default" {
+			return nil, fmt.Errorf("invalid source %q", part)
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// BindRequest binds req onto obj with the binding handling its method and
+// content type, like Decoder.Bind, then resolves the fields tagged with in
+// from their sources before validating obj.
+func BindRequest(req *http.Request, obj interface{}) error {
+	return NewDecoder(DefaultOptions).BindRequest(req, obj)
+}
+
+// BindRequest is the package-level BindRequest using the Options of d.
+func (d *Decoder) BindRequest(req *http.Request, obj interface{}) error {
+	opts, err := d.options(req)
+	if err != nil {
+		return d.Options.negotiate(req, err)
+	}
+	r, b, err := bindingFor(req, opts)
+	if err != nil {
+		return opts.negotiate(req, err)
+	}
+	req = r
+	s := &bindState{opts: opts, req: req}
+	sb, ok := b.(stateBinding)
+	if !ok {
+		if err := b.Bind(req, obj); err != nil {
+			return err
+		}
+		return s.validate(obj)
+	}
+	return s.run(sb, req, obj)
+}
+
+// resolveSources sets the fields of obj tagged with in from the first of
+// their sources present in s.req.
+func (s *bindState) resolveSources(obj interface{}) error {
+	if s.req == nil {
+		return nil
+	}
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return s.resolveStructSources(val.Elem(), "", "")
+}
+
+// resolveStructSources resolves the fields of the struct val. prefix is the
+// prefix tag of the nested structs val is in, see formMapper.keyPath.
+func (s *bindState) resolveStructSources(val reflect.Value, path, prefix string) error {
+	info, err := cachedStageInfo(val.Type(), s.keyTag())
+	if err != nil {
+		return err
+	}
+	for _, field := range info.fields {
+		structField := val.Field(field.index)
+		if field.nested {
+			// recursive structs aren't allocated for sources, which would
+			// never end
+			if field.recursive && structField.IsNil() {
+				continue
+			}
+			err := bindNested(structField, func(nested reflect.Value) error {
+				return s.resolveStructSources(nested, path, prefix+field.prefix)
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if len(field.sources) == 0 {
+			continue
+		}
+		value, ok := s.lookupSources(field.sources)
+		if !ok {
+			continue
+		}
+		if value, err = s.transform(value, field, StageLookup); err == nil {
+			err = setValue(value, structField.Type(), structField, field)
+		}
+		if err != nil {
+			return &FieldError{Path: joinPath(path, prefix+field.key), Key: prefix + field.key, Err: err}
+		}
+	}
+	return nil
+}
+
+// lookupSources returns the value of the first of sources present.
+func (s *bindState) lookupSources(sources []fieldSource) (string, bool) {
+	for _, src := range sources {
+		switch src.kind {
+		case "header":
+			if values := s.req.Header[src.key]; len(values) > 0 {
+				return values[0], true
+			}
+		case "query":
+			if s.query == nil {
+				s.query = parseQuery(s.req.URL.RawQuery)
+			}
+			if values := s.query[src.key]; len(values) > 0 {
+				return values[0], true
+			}
+		case "cookie":
+			if c, err := s.req.Cookie(src.key); err == nil {
+				return c.Value, true
+			}
+		case "default":
+			return src.key, true
+		}
+	}
+	return "", false
+}
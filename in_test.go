@@ -0,0 +1,67 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForSources struct {
+	Tenant string `json:"-" in:"header=x-tenant-id,query=tenant,default=public" binding:"required"`
+	Locale string `json:"-" in:"cookie=locale,header=Accept-Language"`
+	Page   int    `json:"page" form:"page" in:"query=p"`
+	Foo    string `json:"foo" form:"foo"`
+}
+
+type FooStructForBadSources struct {
+	Tenant string `in:"path=tenant"`
+}
+
+func TestBindRequestSources(t *testing.T) {
+	req := requestWithBody("POST", "/?tenant=acme&p=3", `{"foo": "bar", "page": 1}`)
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("Accept-Language", "fr")
+	var obj FooStructForSources
+	assert.NoError(t, BindRequest(req, &obj))
+	assert.Equal(t, FooStructForSources{Tenant: "acme", Locale: "fr", Page: 3, Foo: "bar"}, obj)
+
+	req = requestWithBody("POST", "/?tenant=acme", "foo=baz&page=2")
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	req.Header.Set("X-Tenant-Id", "globex")
+	req.AddCookie(&http.Cookie{Name: "locale", Value: "de"})
+	obj = FooStructForSources{}
+	assert.NoError(t, BindRequest(req, &obj))
+	assert.Equal(t, FooStructForSources{Tenant: "globex", Locale: "de", Page: 2, Foo: "baz"}, obj)
+
+	req, _ = http.NewRequest("GET", "/?foo=qux", nil)
+	obj = FooStructForSources{}
+	assert.NoError(t, BindRequest(req, &obj))
+	assert.Equal(t, FooStructForSources{Tenant: "public", Foo: "qux"}, obj)
+}
+
+func TestBindRequestSourcesFail(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?p=abc", nil)
+	assert.Error(t, BindRequest(req, &FooStructForSources{}))
+
+	req, _ = http.NewRequest("POST", "/", bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "text/csv")
+	d := NewDecoder(Options{UnknownContentType: ContentTypeReject})
+	assert.Error(t, d.BindRequest(req, &FooStructForSources{}))
+}
+
+func TestSourcesIgnoredByBindings(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?tenant=acme&Tenant=x", nil)
+	var obj FooStructForSources
+	assert.Error(t, Form.Bind(req, &obj))
+	assert.Equal(t, "", obj.Tenant)
+}
+
+func TestBadSourcesTag(t *testing.T) {
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadSources]() })
+}
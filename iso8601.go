@@ -0,0 +1,303 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISODuration is an ISO 8601 duration such as "P3DT2H" or "P1Y2M". Years,
+// months, weeks and days are kept apart from the time part, as their length
+// depends on the date they are added to. It binds from form values and, being
+// an encoding.TextUnmarshaler, from JSON and XML strings.
+type ISODuration struct {
+	Years, Months, Weeks, Days int
+	// Time is the sum of the hours, minutes and seconds.
+	Time time.Duration
+}
+
+// ParseISODuration parses an ISO 8601 duration. Only the last component may
+// have a fraction, written with a dot or a comma, and only if it is part of
+// the time. Durations whose time part, or weeks and days, overflow are
+// rejected.
+func ParseISODuration(s string) (ISODuration, error) {
+	var d ISODuration
+	rest := s
+	if !strings.HasPrefix(rest, "P") || len(rest) == 1 || strings.HasSuffix(rest, "T") {
+		return d, fmt.Errorf("invalid ISO 8601 duration %q", s)
+	}
+	rest = rest[1:]
+	inTime := false
+	// units are in decreasing order, each at most once
+	const dateUnits, timeUnits = "YMWD", "HMS"
+	units := dateUnits
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q", s)
+			}
+			inTime, units, rest = true, timeUnits, rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+		if i <= 0 {
+			return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q", s)
+		}
+		num, unit := strings.Replace(rest[:i], ",", ".", 1), rest[i]
+		rest = rest[i+1:]
+		pos := strings.IndexByte(units, unit)
+		if pos == -1 {
+			return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q", s)
+		}
+		units = units[pos+1:]
+
+		if strings.Contains(num, ".") {
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil || !inTime || rest != "" {
+				return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q", s)
+			}
+			v := f * float64(isoTimeUnit(unit))
+			if v >= math.MaxInt64-float64(d.Time) {
+				return ISODuration{}, fmt.Errorf("ISO 8601 duration %q overflows", s)
+			}
+			d.Time += time.Duration(v)
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if errors.Is(err, strconv.ErrRange) {
+			return ISODuration{}, fmt.Errorf("ISO 8601 duration %q overflows", s)
+		}
+		if err != nil {
+			return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q", s)
+		}
+		if inTime {
+			unit := isoTimeUnit(unit)
+			if int64(n) > (math.MaxInt64-int64(d.Time))/int64(unit) {
+				return ISODuration{}, fmt.Errorf("ISO 8601 duration %q overflows", s)
+			}
+			d.Time += time.Duration(n) * unit
+			continue
+		}
+		switch unit {
+		case 'Y':
+			d.Years = n
+		case 'M':
+			d.Months = n
+		case 'W':
+			d.Weeks = n
+		case 'D':
+			d.Days = n
+		}
+	}
+	// AddTo adds the weeks as days
+	if d.Weeks > (math.MaxInt-d.Days)/7 {
+		return ISODuration{}, fmt.Errorf("ISO 8601 duration %q overflows", s)
+	}
+	return d, nil
+}
+
+func isoTimeUnit(unit byte) time.Duration {
+	switch unit {
+	case 'H':
+		return time.Hour
+	case 'M':
+		return time.Minute
+	default:
+		return time.Second
+	}
+}
+
+// AddTo returns t plus d.
+func (d ISODuration) AddTo(t time.Time) time.Time {
+	return t.AddDate(d.Years, d.Months, d.Weeks*7+d.Days).Add(d.Time)
+}
+
+// SubFrom returns t minus d.
+func (d ISODuration) SubFrom(t time.Time) time.Time {
+	return t.AddDate(-d.Years, -d.Months, -d.Weeks*7-d.Days).Add(-d.Time)
+}
+
+// String formats d in ISO 8601, "PT0S" for the zero duration.
+func (d ISODuration) String() string {
+	var b strings.Builder
+	b.WriteByte('P')
+	for _, c := range []struct {
+		n    int
+		unit byte
+	}{{d.Years, 'Y'}, {d.Months, 'M'}, {d.Weeks, 'W'}, {d.Days, 'D'}} {
+		if c.n != 0 {
+			b.WriteString(strconv.Itoa(c.n))
+			b.WriteByte(c.unit)
+		}
+	}
+	if d.Time != 0 || b.Len() == 1 {
+		b.WriteByte('T')
+		rest := d.Time
+		if h := rest / time.Hour; h != 0 {
+			fmt.Fprintf(&b, "%dH", h)
+			rest -= h * time.Hour
+		}
+		if m := rest / time.Minute; m != 0 {
+			fmt.Fprintf(&b, "%dM", m)
+			rest -= m * time.Minute
+		}
+		if rest != 0 || d.Time == 0 {
+			b.WriteString(strconv.FormatFloat(rest.Seconds(), 'f', -1, 64))
+			b.WriteByte('S')
+		}
+	}
+	return b.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d ISODuration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. An empty text is the zero
+// duration.
+func (d *ISODuration) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = ISODuration{}
+		return nil
+	}
+	parsed, err := ParseISODuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// RepeatingInterval is an ISO 8601 repeating interval such as
+// "R5/2018-01-01T00:00:00Z/P1D". Whichever of Start, End and Period the input
+// leaves out is computed from the other two; when given as start and end, the
+// period is their difference.
+type RepeatingInterval struct {
+	// Repeat is the number of repetitions, -1 if unbounded ("R/...").
+	Repeat int
+	Start  time.Time
+	End    time.Time
+	Period ISODuration
+}
+
+// ParseRepeatingInterval parses an ISO 8601 repeating interval written
+// R[n]/start/period, R[n]/period/end or R[n]/start/end. Times are written in
+// RFC 3339 or as dates in UTC. An end before the start, making the period
+// negative, is rejected.
+func ParseRepeatingInterval(s string) (RepeatingInterval, error) {
+	var r RepeatingInterval
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "R") {
+		return r, fmt.Errorf("invalid ISO 8601 repeating interval %q", s)
+	}
+	r.Repeat = -1
+	if n := parts[0][1:]; n != "" {
+		var err error
+		if r.Repeat, err = strconv.Atoi(n); err != nil || r.Repeat < 0 {
+			return RepeatingInterval{}, fmt.Errorf("invalid ISO 8601 repeating interval %q", s)
+		}
+	}
+
+	var err error
+	switch {
+	case strings.HasPrefix(parts[1], "P"):
+		if r.Period, err = ParseISODuration(parts[1]); err != nil {
+			return RepeatingInterval{}, err
+		}
+		if r.End, err = parseISOTime(parts[2]); err != nil {
+			return RepeatingInterval{}, err
+		}
+		r.Start = r.Period.SubFrom(r.End)
+	case strings.HasPrefix(parts[2], "P"):
+		if r.Start, err = parseISOTime(parts[1]); err != nil {
+			return RepeatingInterval{}, err
+		}
+		if r.Period, err = ParseISODuration(parts[2]); err != nil {
+			return RepeatingInterval{}, err
+		}
+		r.End = r.Period.AddTo(r.Start)
+	default:
+		if r.Start, err = parseISOTime(parts[1]); err != nil {
+			return RepeatingInterval{}, err
+		}
+		if r.End, err = parseISOTime(parts[2]); err != nil {
+			return RepeatingInterval{}, err
+		}
+		if r.End.Before(r.Start) {
+			return RepeatingInterval{}, fmt.Errorf("ISO 8601 repeating interval %q has a negative period", s)
+		}
+		r.Period = ISODuration{Time: r.End.Sub(r.Start)}
+	}
+	return r, nil
+}
+
+func parseISOTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// At returns the start of the i-th repetition, counting from 0, negative i
+// counting back from Start. The years, months, weeks and days of the period
+// are added i times at once, then its time part.
+func (r RepeatingInterval) At(i int) time.Time {
+	p := r.Period
+	t := r.Start.AddDate(p.Years*i, p.Months*i, (p.Weeks*7+p.Days)*i)
+	if p.Time == 0 {
+		return t
+	}
+	// add the largest multiples of the period a time.Duration holds
+	most := math.MaxInt64 / int64(p.Time)
+	if most < 0 {
+		most = -most
+	}
+	most = max(most, 1)
+	n := int64(i)
+	for ; n > most; n -= most {
+		t = t.Add(time.Duration(most) * p.Time)
+	}
+	for ; n < -most; n += most {
+		t = t.Add(-time.Duration(most) * p.Time)
+	}
+	return t.Add(time.Duration(n) * p.Time)
+}
+
+// String formats r in ISO 8601 as R[n]/start/period.
+func (r RepeatingInterval) String() string {
+	repeat := ""
+	if r.Repeat >= 0 {
+		repeat = strconv.Itoa(r.Repeat)
+	}
+	return fmt.Sprintf("R%s/%s/%s", repeat, r.Start.Format(time.RFC3339), r.Period)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r RepeatingInterval) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. An empty text is the zero
+// interval.
+func (r *RepeatingInterval) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*r = RepeatingInterval{}
+		return nil
+	}
+	parsed, err := ParseRepeatingInterval(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseISODuration(t *testing.T) {
+	for input, expected := range map[string]ISODuration{
+		"P3DT2H":        {Days: 3, Time: 2 * time.Hour},
+		"P1Y2M3W4D":     {Years: 1, Months: 2, Weeks: 3, Days: 4},
+		"PT1H30M":       {Time: 90 * time.Minute},
+		"PT0.5S":        {Time: 500 * time.Millisecond},
+		"PT1,5M":        {Time: 90 * time.Second},
+		"P1DT1H2M3.25S": {Days: 1, Time: time.Hour + 2*time.Minute + 3250*time.Millisecond},
+		"PT0S":          {},
+	} {
+		d, err := ParseISODuration(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, d, input)
+		if input != "PT1,5M" && input != "PT1H30M" {
+			assert.Equal(t, input, d.String())
+		}
+	}
+
+	for _, input := range []string{"", "P", "PT", "P1H", "PT1D", "P1D2Y", "P1.5D", "PT1.5H2M", "3D", "P1DT", "PxD", "P1DT1HT1M"} {
+		_, err := ParseISODuration(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParseISODurationOverflow(t *testing.T) {
+	for _, input := range []string{
+		"PT2562048H",
+		"PT2562047H48M",
+		"PT9223372037S",
+		"PT9223372037.5S",
+		"P99999999999999999999D",
+		"P1317624576693539402W",
+		"P1317624576693539401W7D",
+	} {
+		_, err := ParseISODuration(input)
+		assert.Error(t, err, input)
+	}
+
+	d, err := ParseISODuration("PT2562047H47M16S")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(2562047)*time.Hour+47*time.Minute+16*time.Second, d.Time)
+	_, err = ParseISODuration("PT2562047H47M17S")
+	assert.EqualError(t, err, `ISO 8601 duration "PT2562047H47M17S" overflows`)
+}
+
+func TestRepeatingIntervalAt(t *testing.T) {
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := RepeatingInterval{Start: start, Period: ISODuration{Time: time.Hour}}
+	assert.Equal(t, start, r.At(0))
+	assert.Equal(t, start.Add(1000000*time.Hour), r.At(1000000))
+	assert.Equal(t, start.Add(-3*time.Hour), RepeatingInterval{Start: start, Period: ISODuration{Time: -time.Hour}}.At(3))
+
+	// the repetitions of a second-long period overflow a time.Duration
+	r.Period.Time = time.Second
+	big := 1 << 40
+	expected := start
+	for k := 0; k < big>>32; k++ {
+		expected = expected.Add(time.Duration(1<<32) * time.Second)
+	}
+	assert.Equal(t, expected, r.At(big))
+
+	r.Period = ISODuration{Months: 1}
+	assert.Equal(t, time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC), r.At(3))
+	assert.Equal(t, time.Date(2017, 11, 1, 0, 0, 0, 0, time.UTC), r.At(-2))
+	assert.Equal(t, time.Date(85351, 5, 1, 0, 0, 0, 0, time.UTC), r.At(1000000))
+
+	r.Period = ISODuration{Weeks: 1, Days: 1, Time: time.Hour}
+	assert.Equal(t, time.Date(2018, 1, 17, 2, 0, 0, 0, time.UTC), r.At(2))
+	assert.Equal(t, time.Date(2017, 12, 23, 23, 0, 0, 0, time.UTC), r.At(-1))
+}
+
+func TestISODurationAddTo(t *testing.T) {
+	d := ISODuration{Months: 1, Days: 1, Time: time.Hour}
+	start := time.Date(2018, 1, 31, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2018, 3, 4, 1, 0, 0, 0, time.UTC), d.AddTo(start))
+	assert.Equal(t, time.Date(2018, 2, 3, 0, 0, 0, 0, time.UTC), d.SubFrom(d.AddTo(start)))
+}
+
+func TestParseRepeatingInterval(t *testing.T) {
+	day := ISODuration{Days: 1}
+	r, err := ParseRepeatingInterval("R5/2018-01-01/P1D")
+	assert.NoError(t, err)
+	assert.Equal(t, RepeatingInterval{
+		Repeat: 5,
+		Start:  time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:    time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC),
+		Period: day,
+	}, r)
+	assert.Equal(t, time.Date(2018, 1, 4, 0, 0, 0, 0, time.UTC), r.At(3))
+	assert.Equal(t, "R5/2018-01-01T00:00:00Z/P1D", r.String())
+
+	r, err = ParseRepeatingInterval("R/P1D/2018-01-02T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, r.Repeat)
+	assert.Equal(t, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), r.Start)
+
+	r, err = ParseRepeatingInterval("R2/2018-01-01T00:00:00Z/2018-01-01T06:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, ISODuration{Time: 6 * time.Hour}, r.Period)
+
+	_, err = ParseRepeatingInterval("R2/2018-01-01T06:00:00Z/2018-01-01T00:00:00Z")
+	assert.EqualError(t, err, `ISO 8601 repeating interval "R2/2018-01-01T06:00:00Z/2018-01-01T00:00:00Z" has a negative period`)
+
+	for _, input := range []string{"", "R5", "5/2018-01-01/P1D", "R-1/2018-01-01/P1D", "Rx/2018-01-01/P1D", "R/2018-01-01/P1X", "R/yesterday/P1D"} {
+		_, err := ParseRepeatingInterval(input)
+		assert.Error(t, err, input)
+	}
+}
+
+type FooStructForISO8601 struct {
+	Every    ISODuration        `form:"every" json:"every"`
+	Schedule RepeatingInterval  `form:"schedule" json:"schedule"`
+	Timeout  *ISODuration       `form:"timeout" json:"timeout"`
+	Retries  []ISODuration      `form:"retries" json:"retries"`
+	Cron     *RepeatingInterval `form:"cron" json:"cron"`
+}
+
+func TestMapFormISO8601(t *testing.T) {
+	var obj FooStructForISO8601
+	err := mapForm(&obj, map[string][]string{
+		"every":    {"P3DT2H"},
+		"schedule": {"R5/2018-01-01/P1D"},
+		"timeout":  {"PT30S"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ISODuration{Days: 3, Time: 2 * time.Hour}, obj.Every)
+	assert.Equal(t, 5, obj.Schedule.Repeat)
+	assert.Equal(t, 30*time.Second, obj.Timeout.Time)
+
+	err = mapForm(&obj, map[string][]string{"every": {"3d"}})
+	assert.Error(t, err)
+
+	obj = FooStructForISO8601{}
+	err = json.Unmarshal([]byte(`{"every": "P1W", "retries": ["PT1S", "PT5S"], "cron": "R/2018-01-01/PT1H"}`), &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, ISODuration{Weeks: 1}, obj.Every)
+	assert.Equal(t, []ISODuration{{Time: time.Second}, {Time: 5 * time.Second}}, obj.Retries)
+	assert.Equal(t, time.Hour, obj.Cron.Period.Time)
+
+	data, err := json.Marshal(obj.Every)
+	assert.NoError(t, err)
+	assert.Equal(t, `"P1W"`, string(data))
+}
@@ -20,13 +20,31 @@ func (jsonBinding) Name() string {
 	return "json"
 }
 
-func (jsonBinding) Bind(req *http.Request, obj interface{}) error {
-	decoder := json.NewDecoder(req.Body)
+func (b jsonBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (jsonBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	if err := s.watchDigest(req); err != nil {
+		return err
+	}
+	body, empty := openBody(req)
+	if empty {
+		return s.bindEmptyBody(obj)
+	}
+	r, _ := skipBOM(body)
+	decoder := json.NewDecoder(r)
 	if EnableDecoderUseNumber {
 		decoder.UseNumber()
 	}
 	if err := decoder.Decode(obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	if err := s.cleanStrings(obj); err != nil {
+		return err
+	}
+	if err := s.finishBody(req, body, obj); err != nil {
+		return err
+	}
+	return s.validate(obj)
 }
@@ -0,0 +1,95 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Lazy is a field whose value is kept raw when bound and only converted on
+// first access, for structs with hundreds of optional fields most requests
+// never read, e.g. in telemetry:
+//
+//	type Beacon struct {
+//		Event    string                  `form:"e"`
+//		Viewport binding.Lazy[int]       `form:"vw" min:"0"`
+//		Since    binding.Lazy[time.Time] `form:"since" time_format:"2006-01-02"`
+//	}
+//
+// Conversion errors, including those of the tags of the field, are returned
+// by Get rather than by the binding. Fields are bound from forms as well as
+// from JSON. A Lazy is not safe for concurrent use.
+type Lazy[T any] struct {
+	raw   string
+	field *fieldInfo
+	json  []byte
+	set   bool
+
+	done bool
+	v    T
+	err  error
+}
+
+// lazyValue is implemented by pointers to Lazy.
+type lazyValue interface {
+	setRaw(val string, field *fieldInfo)
+	// valueType returns T.
+	valueType() reflect.Type
+}
+
+var lazyValueType = reflect.TypeOf((*lazyValue)(nil)).Elem()
+
+func (l *Lazy[T]) setRaw(val string, field *fieldInfo) {
+	*l = Lazy[T]{raw: val, field: field, set: true}
+}
+
+func (l *Lazy[T]) valueType() reflect.Type {
+	return reflect.TypeOf(&l.v).Elem()
+}
+
+// Present reports whether a value was bound.
+func (l *Lazy[T]) Present() bool {
+	return l.set
+}
+
+// Get converts the bound value on first call and returns it, the zero value
+// if none was bound.
+func (l *Lazy[T]) Get() (T, error) {
+	if !l.done && l.set {
+		if l.json != nil {
+			l.err = json.Unmarshal(l.json, &l.v)
+			l.json = nil
+		} else {
+			v := reflect.ValueOf(&l.v).Elem()
+			l.err = setValue(l.raw, v.Type(), v, l.field)
+		}
+	}
+	l.done = true
+	return l.v, l.err
+}
+
+// UnmarshalJSON keeps a copy of data to be decoded by Get.
+func (l *Lazy[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*l = Lazy[T]{}
+		return nil
+	}
+	*l = Lazy[T]{json: append([]byte(nil), data...), set: true}
+	return nil
+}
+
+// MarshalJSON encodes the converted value, null if none was bound.
+func (l Lazy[T]) MarshalJSON() ([]byte, error) {
+	if !l.set {
+		return []byte("null"), nil
+	}
+	v, err := l.Get()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
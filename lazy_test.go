@@ -0,0 +1,82 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForLazy struct {
+	Event    string          `json:"e" form:"e"`
+	Viewport Lazy[int]       `json:"vw" form:"vw" min:"0"`
+	Seen     Lazy[time.Time] `json:"seen" form:"seen" time_format:"2006-01-02"`
+	Tags     []Lazy[uint8]   `json:"tags" form:"tags"`
+	Missing  Lazy[float64]   `json:"missing" form:"missing"`
+	Size     Lazy[int]       `json:"size" form:"size" default:"10"`
+}
+
+func TestBindLazy(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?e=view&vw=-5&seen=2018-05-01&tags=1&tags=300", nil)
+	var obj FooStructForLazy
+	assert.NoError(t, Form.Bind(req, &obj))
+	assert.Equal(t, "view", obj.Event)
+
+	assert.True(t, obj.Viewport.Present())
+	_, err := obj.Viewport.Get()
+	var rangeErr *RangeError
+	assert.True(t, errors.As(err, &rangeErr))
+
+	seen, err := obj.Seen.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 2018, seen.Year())
+
+	if assert.Len(t, obj.Tags, 2) {
+		tag, err := obj.Tags[0].Get()
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(1), tag)
+		_, err = obj.Tags[1].Get()
+		assert.Error(t, err)
+	}
+
+	assert.False(t, obj.Missing.Present())
+	missing, err := obj.Missing.Get()
+	assert.NoError(t, err)
+	assert.Zero(t, missing)
+
+	size, err := obj.Size.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, size)
+}
+
+func TestBindLazyJSON(t *testing.T) {
+	req := requestWithBody("POST", "/", `{"e": "click", "vw": 1280, "missing": null}`)
+	var obj FooStructForLazy
+	assert.NoError(t, JSON.Bind(req, &obj))
+	vw, err := obj.Viewport.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 1280, vw)
+	assert.False(t, obj.Missing.Present())
+
+	data, err := json.Marshal(obj.Viewport)
+	assert.NoError(t, err)
+	assert.Equal(t, "1280", string(data))
+	data, err = json.Marshal(obj.Missing)
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+}
+
+type FooStructForBadLazyBound struct {
+	Name Lazy[string] `form:"name" min:"1"`
+}
+
+func TestBadLazyTag(t *testing.T) {
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadLazyBound]() })
+}
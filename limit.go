@@ -0,0 +1,85 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"io"
+	"net/http"
+)
+
+// Limits reported by LimitError.
+const (
+	// LimitBodySize is the size of the body, see Options.MaxBodySize.
+	LimitBodySize = "body_size"
+	// LimitFormValues is the number of form values, see
+	// Options.MaxFormValues.
+	LimitFormValues = "form_values"
+	// LimitMultipartMemory is the size of the values of a multipart form
+	// read by the lazy multipart binding.
+	LimitMultipartMemory = "multipart_memory"
+	// LimitDepth is the nesting of the structs bound from a form, see
+	// Options.MaxDepth.
+	LimitDepth = "depth"
+)
+
+// limitError returns the error reporting that observed exceeds the limit
+// max.
+func (s *bindState) limitError(limit string, max, observed int64) *LimitError {
+	return &LimitError{Limit: limit, Max: max, Observed: observed, RetryAfter: s.opts.LimitRetryAfter}
+}
+
+// limitBody bounds the body of req to the MaxBodySize option, rejecting it
+// upfront if its length is known to exceed it.
+func (s *bindState) limitBody(req *http.Request) error {
+	max := s.opts.MaxBodySize
+	if max <= 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.ContentLength > max {
+		return s.limitError(LimitBodySize, max, req.ContentLength)
+	}
+	req.Body = &limitedBody{ReadCloser: req.Body, s: s, max: max}
+	return nil
+}
+
+// limitedBody fails reads once more than max bytes were read.
+type limitedBody struct {
+	io.ReadCloser
+	s   *bindState
+	n   int64
+	max int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.n > b.max {
+		return 0, b.s.limitError(LimitBodySize, b.max, b.n)
+	}
+	if rest := b.max - b.n + 1; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if b.n > b.max {
+		return 0, b.s.limitError(LimitBodySize, b.max, b.n)
+	}
+	return n, err
+}
+
+// checkFormValues checks the number of values of form against the
+// MaxFormValues option.
+func (s *bindState) checkFormValues(form map[string][]string) error {
+	max := s.opts.MaxFormValues
+	if max <= 0 {
+		return nil
+	}
+	n := 0
+	for _, values := range form {
+		n += len(values)
+	}
+	if n > max {
+		return s.limitError(LimitFormValues, int64(max), int64(n))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	defer withOptions(Options{MaxBodySize: 16, LimitRetryAfter: 1500 * time.Millisecond})()
+
+	req := requestWithBody("POST", "/", `{"foo": "bar"}`)
+	assert.NoError(t, JSON.Bind(req, &FooStruct{}))
+
+	req = requestWithBody("POST", "/", `{"foo": "`+strings.Repeat("x", 64)+`"}`)
+	err := JSON.Bind(req, &FooStruct{})
+	var limitErr *LimitError
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.Equal(t, LimitBodySize, limitErr.Limit)
+		assert.Equal(t, int64(16), limitErr.Max)
+		assert.Equal(t, int64(75), limitErr.Observed)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, limitErr.StatusCode())
+	}
+
+	// without a known length the body is cut once the limit is passed
+	req, _ = http.NewRequest("POST", "/", ioutil.NopCloser(strings.NewReader("foo="+strings.Repeat("x", 64))))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	err = FormPost.Bind(req, &FooStruct{})
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.Equal(t, int64(17), limitErr.Observed)
+	}
+}
+
+func TestMaxFormValues(t *testing.T) {
+	defer withOptions(Options{MaxFormValues: 2})()
+
+	req, _ := http.NewRequest("GET", "/?foo=1&bar=2", nil)
+	assert.NoError(t, Query.Bind(req, &FooBarStruct{}))
+
+	req, _ = http.NewRequest("GET", "/?foo=1&bar=2&bar=3", nil)
+	err := Query.Bind(req, &FooBarStruct{})
+	assert.EqualError(t, err, "binding: form_values limit exceeded: 3 > 2")
+}
+
+func TestLazyMultipartLimit(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("foo", strings.Repeat("x", defaultMemory+1))
+	mw.Close()
+	req, _ := http.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	err := FormMultipart.Bind(req, &FooStructForLazyMultipart{})
+	var limitErr *LimitError
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.Equal(t, LimitMultipartMemory, limitErr.Limit)
+		assert.Equal(t, int64(defaultMemory+1), limitErr.Observed)
+	}
+}
+
+func TestWriteLimitError(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteError(w, &LimitError{Limit: LimitBodySize, Max: 10, Observed: 20, RetryAfter: 1500 * time.Millisecond})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error": "binding: body_size limit exceeded: 20 > 10", "limit": "body_size", "max": 10, "observed": 20}`, w.Body.String())
+}
+
+func TestLimitErrorStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusRequestEntityTooLarge, (&LimitError{Limit: LimitFormValues}).StatusCode())
+	assert.Equal(t, http.StatusTooManyRequests, (&LimitError{Limit: LimitFormValues, RetryAfter: time.Second}).StatusCode())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, (&LimitError{Limit: LimitBodySize, RetryAfter: time.Second}).StatusCode())
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// knownMediaTypes are the media types Default has a built-in binding for.
+var knownMediaTypes = []string{
+	MIMEJSON, MIMEXML, MIMEXML2, MIMEPROTOBUF, MIMEMSGPACK, MIMEMSGPACK2,
+	MIMEPOSTForm, MIMEMultipartPOSTForm,
+}
+
+// registeredBindings holds the bindings registered by media type, see
+// RegisterBinding.
+var registeredBindings sync.Map // map[string]Binding
+
+// RegisterBinding registers b as the binding of Default, Decoder.Bind and
+// BindRequest for requests of mediaType, e.g.
+//
+//	binding.RegisterBinding("application/yaml", yamlBinding{})
+//
+// replacing the built-in binding of a known media type. Bindings are meant to
+// be registered at init time.
+func RegisterBinding(mediaType string, b Binding) {
+	registeredBindings.Store(strings.ToLower(strings.TrimSpace(mediaType)), b)
+}
+
+// registeredBinding returns the binding registered for mediaType, if any.
+func registeredBinding(mediaType string) (Binding, bool) {
+	b, ok := registeredBindings.Load(mediaType)
+	if !ok {
+		return nil, false
+	}
+	return b.(Binding), true
+}
+
+// Supports reports whether requests of contentType, e.g.
+// "application/json; charset=utf-8", are bound with a binding of their own,
+// built-in or registered, rather than with the UnknownContentType option.
+func Supports(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if builtIn(mediaType) {
+		return true
+	}
+	_, ok := registeredBinding(mediaType)
+	return ok
+}
+
+// builtIn reports whether mediaType is one of knownMediaTypes.
+func builtIn(mediaType string) bool {
+	for _, known := range knownMediaTypes {
+		if mediaType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportedMediaTypes returns the media types Supports reports, the built-in
+// ones first, then the registered ones in order, so that servers can
+// advertise them, e.g.
+//
+//	w.Header().Set("Accept-Post", strings.Join(binding.SupportedMediaTypes(), ", "))
+func SupportedMediaTypes() []string {
+	types := append([]string(nil), knownMediaTypes...)
+	var registered []string
+	registeredBindings.Range(func(key, _ interface{}) bool {
+		if !builtIn(key.(string)) {
+			registered = append(registered, key.(string))
+		}
+		return true
+	})
+	sort.Strings(registered)
+	return append(types, registered...)
+}
+
+// SetAcceptHeader sets the header of h advertising mediaTypes, or
+// SupportedMediaTypes if none are given, as the content types of the requests
+// of method, e.g. in the 415 Unsupported Media Type responses to them:
+// Accept-Post for POST, Accept-Patch for PATCH and Accept for other methods.
+func SetAcceptHeader(h http.Header, method string, mediaTypes ...string) {
+	if len(mediaTypes) == 0 {
+		mediaTypes = SupportedMediaTypes()
+	}
+	name := "Accept"
+	switch method {
+	case http.MethodPost:
+		name = "Accept-Post"
+	case http.MethodPatch:
+		name = "Accept-Patch"
+	}
+	h.Set(name, strings.Join(mediaTypes, ", "))
+}
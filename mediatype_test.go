@@ -0,0 +1,57 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fooTextBinding struct{}
+
+func (fooTextBinding) Name() string {
+	return "text"
+}
+
+func (fooTextBinding) Bind(req *http.Request, obj interface{}) error {
+	obj.(*FooStruct).Foo = "text"
+	return nil
+}
+
+func TestSupports(t *testing.T) {
+	assert.True(t, Supports(MIMEJSON))
+	assert.True(t, Supports("application/json; charset=utf-8"))
+	assert.True(t, Supports("Multipart/Form-Data; boundary=x"))
+	assert.False(t, Supports(MIMEPlain))
+	assert.False(t, Supports(""))
+	assert.False(t, Supports("application/json; charset"))
+	assert.Equal(t, knownMediaTypes, SupportedMediaTypes())
+
+	RegisterBinding(" Text/Plain", fooTextBinding{})
+	defer registeredBindings.Delete(MIMEPlain)
+	assert.True(t, Supports("text/plain; charset=utf-8"))
+	assert.Equal(t, append(append([]string(nil), knownMediaTypes...), MIMEPlain), SupportedMediaTypes())
+	assert.Equal(t, "text", Default("POST", MIMEPlain).Name())
+	assert.Equal(t, "text", NewDecoder(Options{}).Config().Bindings[MIMEPlain])
+
+	req := requestWithBody("POST", "/", "foo")
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	var obj FooStruct
+	assert.NoError(t, NewDecoder(Options{UnknownContentType: ContentTypeReject}).Bind(req, &obj))
+	assert.Equal(t, "text", obj.Foo)
+}
+
+func TestSetAcceptHeader(t *testing.T) {
+	h := make(http.Header)
+	SetAcceptHeader(h, "POST")
+	assert.Equal(t, "application/json, application/xml, text/xml, application/x-protobuf, application/x-msgpack, application/msgpack, application/x-www-form-urlencoded, multipart/form-data", h.Get("Accept-Post"))
+
+	SetAcceptHeader(h, "PATCH", MIMEJSON, MIMEPOSTForm)
+	assert.Equal(t, "application/json, application/x-www-form-urlencoded", h.Get("Accept-Patch"))
+	SetAcceptHeader(h, "PUT", MIMEJSON)
+	assert.Equal(t, MIMEJSON, h.Get("Accept"))
+}
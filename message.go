@@ -0,0 +1,85 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"reflect"
+)
+
+// Message is a message consumed from a broker such as AMQP or Kafka.
+type Message struct {
+	// Header holds the message headers.
+	Header map[string][]string
+	// ContentType is the media type of Body.
+	ContentType string
+	Body        []byte
+}
+
+// BindMessage binds msg onto obj, which must be a pointer to a struct, then
+// validates obj. Fields tagged with header are bound from the message headers,
+// matched case-insensitively, and the body is decoded by the binding handling
+// its content type, e.g.
+//
+//	type OrderCreated struct {
+//		EventID string `header:"X-Event-Id"`
+//		OrderID int    `json:"order_id"`
+//	}
+//
+// This lets event consumers reuse the structs bound from HTTP requests. An
+// empty body is skipped.
+func BindMessage(msg Message, obj interface{}) error {
+	s := newBindState()
+	s.zero(obj)
+	header := make(http.Header, len(msg.Header))
+	for key, values := range msg.Header {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		header[key] = append(header[key], values...)
+	}
+	m := &formMapper{form: header, tag: "header", state: s}
+	if err := m.mapStruct(reflect.ValueOf(obj).Elem(), ""); err != nil {
+		return err
+	}
+	if len(msg.Body) == 0 {
+		return s.validate(obj)
+	}
+
+	b, err := messageBinding(msg.ContentType)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", "/", bytes.NewReader(msg.Body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", msg.ContentType)
+	return b.bind(req, obj, s)
+}
+
+// messageBinding returns the binding decoding message bodies of contentType.
+func messageBinding(contentType string) (stateBinding, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("binding: invalid message content type %q", contentType)
+	}
+	switch mediaType {
+	case MIMEJSON:
+		return jsonBinding{}, nil
+	case MIMEXML, MIMEXML2:
+		return xmlBinding{}, nil
+	case MIMEPROTOBUF:
+		return protobufBinding{}, nil
+	case MIMEMSGPACK, MIMEMSGPACK2:
+		return msgpackBinding{}, nil
+	case MIMEPOSTForm:
+		return formPostBinding{}, nil
+	}
+	return nil, fmt.Errorf("binding: unsupported message content type %q", contentType)
+}
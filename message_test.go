@@ -0,0 +1,62 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForMessage struct {
+	EventID string `header:"x-event-id" json:"-" binding:"required"`
+	Retries int    `header:"X-Retries" json:"-"`
+	Foo     string `json:"foo" form:"foo"`
+}
+
+func TestBindMessage(t *testing.T) {
+	var obj FooStructForMessage
+	err := BindMessage(Message{
+		Header:      map[string][]string{"X-EVENT-ID": {"42"}, "x-retries": {"2"}},
+		ContentType: "application/json; charset=utf-8",
+		Body:        []byte(`{"foo": "bar"}`),
+	}, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, FooStructForMessage{EventID: "42", Retries: 2, Foo: "bar"}, obj)
+
+	obj = FooStructForMessage{}
+	err = BindMessage(Message{
+		Header:      map[string][]string{"X-Event-Id": {"43"}},
+		ContentType: MIMEPOSTForm,
+		Body:        []byte("foo=baz"),
+	}, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, FooStructForMessage{EventID: "43", Foo: "baz"}, obj)
+}
+
+func TestBindMessageHeadersOnly(t *testing.T) {
+	var obj FooStructForMessage
+	err := BindMessage(Message{Header: map[string][]string{"X-Event-Id": {"1"}}}, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", obj.EventID)
+
+	err = BindMessage(Message{}, &FooStructForMessage{})
+	assert.Error(t, err)
+}
+
+func TestBindMessageFail(t *testing.T) {
+	header := map[string][]string{"X-Event-Id": {"1"}}
+	err := BindMessage(Message{Header: header, ContentType: "text/csv", Body: []byte("a,b")}, &FooStructForMessage{})
+	assert.EqualError(t, err, `binding: unsupported message content type "text/csv"`)
+
+	err = BindMessage(Message{Header: header, ContentType: "", Body: []byte("{}")}, &FooStructForMessage{})
+	assert.Error(t, err)
+
+	err = BindMessage(Message{Header: header, ContentType: MIMEJSON, Body: []byte("{")}, &FooStructForMessage{})
+	assert.Error(t, err)
+
+	err = BindMessage(Message{Header: map[string][]string{"X-Retries": {"x"}}}, &FooStructForMessage{})
+	assert.Error(t, err)
+}
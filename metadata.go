@@ -0,0 +1,34 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import "strings"
+
+// gatewayPrefix is the prefix grpc-gateway adds to the HTTP headers it
+// forwards as gRPC metadata.
+const gatewayPrefix = "grpcgateway-"
+
+// BindMetadata binds gRPC metadata, such as a metadata.MD, and an optional
+// payload of the given content type onto obj like BindMessage does, so that
+// services can share parameter structs between their HTTP and gRPC surfaces.
+// Metadata forwarded by grpc-gateway is matched without its "grpcgateway-"
+// prefix, unless the metadata also holds the key without it.
+func BindMetadata(md map[string][]string, payload []byte, contentType string, obj interface{}) error {
+	header := make(map[string][]string, len(md))
+	for key, values := range md {
+		header[key] = values
+	}
+	for key, values := range md {
+		name := strings.ToLower(key)
+		if !strings.HasPrefix(name, gatewayPrefix) {
+			continue
+		}
+		name = name[len(gatewayPrefix):]
+		if _, ok := md[name]; !ok {
+			header[name] = values
+		}
+	}
+	return BindMessage(Message{Header: header, ContentType: contentType, Body: payload}, obj)
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ppltools/binding/example"
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForMetadata struct {
+	RequestID string `header:"X-Request-Id" binding:"required"`
+	Locale    string `header:"Accept-Language"`
+}
+
+func TestBindMetadata(t *testing.T) {
+	var obj FooStructForMetadata
+	err := BindMetadata(map[string][]string{
+		"x-request-id":                {"abc"},
+		"grpcgateway-accept-language": {"en"},
+	}, nil, "", &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, FooStructForMetadata{RequestID: "abc", Locale: "en"}, obj)
+
+	obj = FooStructForMetadata{}
+	err = BindMetadata(map[string][]string{
+		"grpcgateway-x-request-id": {"gateway"},
+		"x-request-id":             {"direct"},
+	}, nil, "", &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "direct", obj.RequestID)
+
+	err = BindMetadata(nil, nil, "", &FooStructForMetadata{})
+	assert.Error(t, err)
+}
+
+func TestBindMetadataPayload(t *testing.T) {
+	test := &example.Test{Label: proto.String("yes")}
+	data, _ := proto.Marshal(test)
+
+	var obj example.Test
+	err := BindMetadata(map[string][]string{"x-request-id": {"abc"}}, data, MIMEPROTOBUF, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "yes", obj.GetLabel())
+}
@@ -16,9 +16,26 @@ func (msgpackBinding) Name() string {
 	return "msgpack"
 }
 
-func (msgpackBinding) Bind(req *http.Request, obj interface{}) error {
-	if err := codec.NewDecoder(req.Body, new(codec.MsgpackHandle)).Decode(&obj); err != nil {
+func (b msgpackBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (msgpackBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	if err := s.watchDigest(req); err != nil {
+		return err
+	}
+	body, empty := openBody(req)
+	if empty {
+		return s.bindEmptyBody(obj)
+	}
+	if err := codec.NewDecoder(body, new(codec.MsgpackHandle)).Decode(&obj); err != nil {
+		return err
+	}
+	if err := s.cleanStrings(obj); err != nil {
+		return err
+	}
+	if err := s.finishBody(req, body, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return s.validate(obj)
 }
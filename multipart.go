@@ -0,0 +1,201 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// parseMultipartLazily streams through the multipart body of req, reading
+// only the parts named by a key obj is bound from and skipping all others
+// unread, see Options.LazyMultipart. Files are spooled to memory or to
+// temporary files, computing the checksums obj is bound with while reading
+// them and passing them to the inspection and store hooks of the options of s.
+// The values read are set as req.MultipartForm. The temporary files of large
+// files are removed if parsing fails.
+func parseMultipartLazily(req *http.Request, obj interface{}, s *bindState) (map[string][]string, map[string][]*UploadedFile, error) {
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := newFormKeys(reflect.TypeOf(obj).Elem(), s.keyTag(), s.opts.keySeparator())
+	keys.fold = s.opts.CaseInsensitiveKeys
+
+	values := make(map[string][]string)
+	files := make(map[string][]*UploadedFile)
+	// the temporary files of the parts read so far are removed on failure
+	fail := func(err error) (map[string][]string, map[string][]*UploadedFile, error) {
+		removeFiles(files)
+		return nil, nil, err
+	}
+	remaining := int64(defaultMemory)
+	var buf bytes.Buffer
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(err)
+		}
+		name := part.FormName()
+		switch {
+		case name == "":
+		case part.FileName() != "":
+			if sums, ok := keys.files[name]; ok {
+				f, err := spoolFile(part, remaining, sums, s.opts)
+				if err != nil {
+					part.Close()
+					return fail(err)
+				}
+				if f.path == "" {
+					remaining -= f.Size
+				}
+				files[name] = append(files[name], f)
+			}
+		case keys.wants(name):
+			buf.Reset()
+			n, err := io.CopyN(&buf, part, remaining+1)
+			if err != nil && err != io.EOF {
+				part.Close()
+				return fail(err)
+			}
+			if remaining -= n; remaining < 0 {
+				part.Close()
+				return fail(s.limitError(LimitMultipartMemory, defaultMemory, defaultMemory-remaining))
+			}
+			values[name] = append(values[name], buf.String())
+		}
+		part.Close()
+	}
+	req.MultipartForm = &multipart.Form{Value: values}
+	return values, files, nil
+}
+
+// formKeys are the form keys a struct is bound from.
+type formKeys struct {
+	// tag is the tag of the struct metadata the keys are read from, and sep
+	// the separator of keys in dot notation.
+	tag, sep string
+	exact    map[string]bool
+	// prefixes are the prefixes of bracketed keys.
+	prefixes []string
+	// files are the keys of files, with the checksums to compute.
+	files map[string][]string
+	// dotted are the struct types being added in dot notation, which
+	// recursive types aren't added in again.
+	dotted map[reflect.Type]bool
+	// fold matches the exact keys case-insensitively too, see
+	// Options.CaseInsensitiveKeys.
+	fold bool
+}
+
+func newFormKeys(typ reflect.Type, tag, sep string) *formKeys {
+	keys := &formKeys{
+		tag:    tag,
+		sep:    sep,
+		exact:  make(map[string]bool),
+		files:  make(map[string][]string),
+		dotted: make(map[reflect.Type]bool),
+	}
+	if typ.Kind() == reflect.Struct {
+		keys.add(typ, "")
+	}
+	return keys
+}
+
+// add adds the keys of the struct typ, bound in dot notation after prefix if
+// not empty. Keys in bracket notation are matched by prefix, see mapNested.
+func (k *formKeys) add(typ reflect.Type, prefix string) {
+	info, err := cachedStructInfo(typ, k.tag)
+	if err != nil {
+		return
+	}
+	for _, field := range info.fields {
+		fieldType := typ.Field(field.index).Type
+		key := prefix + field.key
+		switch {
+		case field.sourceOnly:
+		case field.recursive:
+			k.prefixes = append(k.prefixes, prefix+field.prefix)
+		case field.nested:
+			k.add(nestedType(fieldType), prefix+field.prefix)
+		case field.timeRange != nil:
+			for _, key := range append(field.timeRange.fromKeys, field.timeRange.toKeys...) {
+				k.exact[prefix+key] = true
+			}
+		case field.file:
+			if _, ok := k.files[key]; !ok {
+				k.files[key] = nil
+			}
+		case field.objectKey != "":
+			if _, ok := k.files[prefix+field.objectKey]; !ok {
+				k.files[prefix+field.objectKey] = nil
+			}
+		case field.checksum != nil:
+			k.files[prefix+field.checksum.file] = append(k.files[prefix+field.checksum.file], field.checksum.algorithm)
+		default:
+			k.exact[key] = true
+			if field.alias != "" {
+				k.exact[prefix+field.alias] = true
+			}
+			if _, ok := k.files[key]; !ok && decodedAsJSON(fieldType) {
+				k.files[key] = nil
+			}
+			if dotted(fieldType) {
+				elem := fieldType
+				if elem.Kind() == reflect.Ptr {
+					elem = elem.Elem()
+				}
+				if !k.dotted[elem] {
+					k.dotted[elem] = true
+					k.add(elem, key+k.sep)
+					delete(k.dotted, elem)
+				}
+				k.prefixes = append(k.prefixes, key+"[")
+			}
+			if fieldType.Kind() == reflect.Slice && dotted(fieldType.Elem()) {
+				k.prefixes = append(k.prefixes, key+"[", key+k.sep)
+			}
+			if fieldType.Kind() == reflect.Map {
+				k.prefixes = append(k.prefixes, key+"[", key+k.sep)
+			}
+			if isOrderedMap(fieldType) {
+				k.prefixes = append(k.prefixes, key+"[")
+			}
+			if implementationsOf(fieldType) != nil {
+				k.prefixes = append(k.prefixes, key+"[", key+k.sep)
+			}
+			if field.disc != "" {
+				k.exact[prefix+field.disc] = true
+			}
+		}
+	}
+}
+
+// wants reports whether name is one of the keys.
+func (k *formKeys) wants(name string) bool {
+	if k.exact[name] {
+		return true
+	}
+	if k.fold {
+		for key := range k.exact {
+			if strings.EqualFold(key, name) {
+				return true
+			}
+		}
+	}
+	for _, prefix := range k.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
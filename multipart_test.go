@@ -0,0 +1,103 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForLazyMultipart struct {
+	Foo    string            `form:"foo"`
+	Old    string            `form:"new" alias:"old"`
+	Meta   map[string]string `form:"meta"`
+	Nested struct {
+		Bar int `form:"bar"`
+	}
+}
+
+func createLazyMultipartRequest() *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("foo", "bar")
+	mw.WriteField("unrelated", strings.Repeat("x", 1<<20))
+	w, _ := mw.CreateFormFile("foo", "foo.txt")
+	w.Write([]byte("file"))
+	mw.WriteField("old", "alias")
+	mw.WriteField("meta[env]", "prod")
+	mw.WriteField("bar", "7")
+	mw.Close()
+	req, _ := http.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestLazyMultipart(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+
+	var obj FooStructForLazyMultipart
+	req := createLazyMultipartRequest()
+	assert.NoError(t, FormMultipart.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, "alias", obj.Old)
+	assert.Equal(t, map[string]string{"env": "prod"}, obj.Meta)
+	assert.Equal(t, 7, obj.Nested.Bar)
+
+	assert.Equal(t, map[string][]string{
+		"foo":       {"bar"},
+		"old":       {"alias"},
+		"meta[env]": {"prod"},
+		"bar":       {"7"},
+	}, req.MultipartForm.Value)
+	assert.Nil(t, req.MultipartForm.File)
+}
+
+func TestLazyMultipartFail(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+
+	req := requestWithBody("POST", "/", "foo=bar")
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	assert.Error(t, FormMultipart.Bind(req, &FooStructForLazyMultipart{}))
+
+	req = requestWithBody("POST", "/", "--x\r\nbroken")
+	req.Header.Set("Content-Type", MIMEMultipartPOSTForm+"; boundary=x")
+	assert.Error(t, FormMultipart.Bind(req, &FooStructForLazyMultipart{}))
+}
+
+type FooStructForLazyMultipartSpool struct {
+	Doc  *UploadedFile `form:"doc"`
+	Name string        `form:"name" binding:"required"`
+}
+
+func TestLazyMultipartFailRemovesFiles(t *testing.T) {
+	defer withOptions(Options{LazyMultipart: true})()
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		w, _ := mw.CreateFormFile("doc", "doc.bin")
+		chunk := bytes.Repeat([]byte("x"), 1<<20)
+		for i := 0; i <= defaultMemory>>20; i++ {
+			w.Write(chunk)
+		}
+		pw.CloseWithError(mw.Close())
+	}()
+	req, _ := http.NewRequest("POST", "/", pr)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var obj FooStructForLazyMultipartSpool
+	assert.Error(t, FormMultipart.Bind(req, &obj))
+	if assert.NotNil(t, obj.Doc) && assert.NotEmpty(t, obj.Doc.path) {
+		_, err := os.Stat(obj.Doc.path)
+		assert.True(t, os.IsNotExist(err))
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"strings"
+	"unicode"
+)
+
+// apply returns the key of the untagged field name with n.
+func (n Naming) apply(name string) string {
+	switch n {
+	case NamingSnakeCase:
+		return strings.ToLower(strings.Join(nameWords(name), "_"))
+	case NamingKebabCase:
+		return strings.ToLower(strings.Join(nameWords(name), "-"))
+	case NamingCamelCase:
+		var b strings.Builder
+		for i, word := range nameWords(name) {
+			word = strings.ToLower(word)
+			if i > 0 {
+				r := []rune(word)
+				r[0] = unicode.ToUpper(r[0])
+				word = string(r)
+			}
+			b.WriteString(word)
+		}
+		return b.String()
+	}
+	return name
+}
+
+// nameWords splits the Go identifier name into words, at underscores and
+// case changes, keeping acronyms and digits whole, e.g. HTTPServer2ID into
+// HTTP, Server2 and ID.
+func nameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i, r := range runes {
+		if r == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
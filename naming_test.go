@@ -0,0 +1,58 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNaming(t *testing.T) {
+	for name, expected := range map[string][3]string{
+		"UserName":      {"user_name", "userName", "user-name"},
+		"UserID":        {"user_id", "userId", "user-id"},
+		"HTTPServer2ID": {"http_server2_id", "httpServer2Id", "http-server2-id"},
+		"ID":            {"id", "id", "id"},
+		"Address2":      {"address2", "address2", "address2"},
+		"Max_Items":     {"max_items", "maxItems", "max-items"},
+	} {
+		assert.Equal(t, expected[0], NamingSnakeCase.apply(name), name)
+		assert.Equal(t, expected[1], NamingCamelCase.apply(name), name)
+		assert.Equal(t, expected[2], NamingKebabCase.apply(name), name)
+		assert.Equal(t, name, NamingFieldName.apply(name), name)
+	}
+}
+
+type FooStructForNaming struct {
+	UserID   int
+	FullName string `form:"name"`
+	Address  FooStructForAddress
+}
+
+func TestMappingFieldNaming(t *testing.T) {
+	form := map[string][]string{
+		"UserID":  {"1"},
+		"user_id": {"2"},
+		"userId":  {"3"},
+		"name":    {"Ada"},
+		"city":    {"Oslo"},
+	}
+	var obj FooStructForNaming
+	assert.NoError(t, mapForm(&obj, form))
+	assert.Equal(t, 1, obj.UserID)
+
+	for naming, expected := range map[Naming]int{NamingSnakeCase: 2, NamingCamelCase: 3} {
+		restore := withOptions(Options{FieldNaming: naming})
+		obj = FooStructForNaming{}
+		assert.NoError(t, mapForm(&obj, form))
+		assert.Equal(t, FooStructForNaming{UserID: expected, FullName: "Ada", Address: FooStructForAddress{City: "Oslo"}}, obj)
+
+		obj = FooStructForNaming{}
+		assert.NoError(t, MapForm(&obj, form, "query"))
+		assert.Equal(t, expected, obj.UserID)
+		restore()
+	}
+}
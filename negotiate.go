@@ -0,0 +1,109 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details.
+const MIMEProblemJSON = "application/problem+json"
+
+// errorMediaTypes are the media types errors can be rendered as, in order of
+// preference between ranges of equal quality.
+var errorMediaTypes = []string{MIMEJSON, MIMEProblemJSON, MIMEPlain}
+
+// NegotiatedError is returned by the bindings in place of the error of a
+// failed bind when the NegotiateErrors option is set. It carries the media
+// type the error should be rendered as according to the Accept header of
+// the request, which WriteError honours.
+type NegotiatedError struct {
+	Err error
+	// MediaType is MIMEJSON, MIMEProblemJSON or MIMEPlain.
+	MediaType string
+}
+
+func (e *NegotiatedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NegotiatedError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorMediaType returns the media type err should be rendered as, from the
+// *NegotiatedError it wraps, or MIMEJSON if it wraps none.
+func ErrorMediaType(err error) string {
+	var negotiated *NegotiatedError
+	if errors.As(err, &negotiated) {
+		return negotiated.MediaType
+	}
+	return MIMEJSON
+}
+
+// NegotiateErrorType returns the media type errors should be rendered as for
+// the Accept header accept: the one of MIMEJSON, MIMEProblemJSON and
+// MIMEPlain with the highest quality, MIMEJSON if it accepts none of them.
+func NegotiateErrorType(accept string) string {
+	best, bestQ := MIMEJSON, 0.0
+	for _, mediaType := range errorMediaTypes {
+		if q := acceptQuality(accept, mediaType); q > bestQ {
+			best, bestQ = mediaType, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality the Accept header accept gives to
+// mediaType, from its most specific matching media range. An empty header
+// accepts everything.
+func acceptQuality(accept, mediaType string) float64 {
+	if strings.TrimSpace(accept) == "" {
+		return 1
+	}
+	slash := strings.IndexByte(mediaType, '/')
+	q, specificity := 0.0, -1
+	for _, r := range strings.Split(accept, ",") {
+		rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(r))
+		if err != nil {
+			continue
+		}
+		var s int
+		switch rangeType {
+		case mediaType:
+			s = 2
+		case mediaType[:slash] + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+		rangeQ := 1.0
+		if v, ok := params["q"]; ok {
+			if rangeQ, err = strconv.ParseFloat(v, 64); err != nil || rangeQ < 0 || rangeQ > 1 {
+				continue
+			}
+		}
+		q, specificity = rangeQ, s
+	}
+	return q
+}
+
+// negotiate wraps err, if any, in a *NegotiatedError for req if the
+// NegotiateErrors option is set.
+func (o *Options) negotiate(req *http.Request, err error) error {
+	if err == nil || !o.NegotiateErrors {
+		return err
+	}
+	return &NegotiatedError{Err: err, MediaType: NegotiateErrorType(req.Header.Get("Accept"))}
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateErrorType(t *testing.T) {
+	for accept, want := range map[string]string{
+		"":                                    MIMEJSON,
+		"*/*":                                 MIMEJSON,
+		"text/html":                           MIMEJSON,
+		"text/plain":                          MIMEPlain,
+		"text/*, application/json;q=0.5":      MIMEPlain,
+		"application/problem+json":            MIMEProblemJSON,
+		"application/*;q=0.9, text/plain;q=1": MIMEPlain,
+		"application/*, application/json;q=0": MIMEProblemJSON,
+		"text/plain;q=0.2, */*;q=0.1":         MIMEPlain,
+		"text/plain;q=oops":                   MIMEJSON,
+	} {
+		assert.Equal(t, want, NegotiateErrorType(accept), accept)
+	}
+}
+
+func TestNegotiateErrors(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?int_foo=x", nil)
+	req.Header.Set("Accept", "text/plain")
+	var obj FooBarStructForIntType
+	err := Form.Bind(req, &obj)
+	assert.Equal(t, MIMEJSON, ErrorMediaType(err))
+
+	d := NewDecoder(Options{NegotiateErrors: true})
+	err = d.Bind(req, &obj)
+	var negotiated *NegotiatedError
+	if assert.True(t, errors.As(err, &negotiated)) {
+		assert.Equal(t, MIMEPlain, negotiated.MediaType)
+	}
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+
+	req = httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Accept", MIMEProblemJSON)
+	d.Options.UnknownContentType = ContentTypeReject
+	err = d.Bind(req, &obj)
+	assert.Equal(t, MIMEProblemJSON, ErrorMediaType(err))
+	var typeErr *ContentTypeError
+	assert.True(t, errors.As(err, &typeErr))
+	assert.NoError(t, d.Bind(httptest.NewRequest("GET", "/?int_foo=1&int_bar=2", nil), &obj))
+}
+
+func TestWriteNegotiatedError(t *testing.T) {
+	err := &FieldError{Path: "age", Key: "age", Err: errors.New("invalid")}
+
+	w := httptest.NewRecorder()
+	WriteError(w, &NegotiatedError{Err: err, MediaType: MIMEPlain})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "binding: field \"age\": invalid\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	WriteError(w, &NegotiatedError{Err: err, MediaType: MIMEProblemJSON})
+	assert.Equal(t, MIMEProblemJSON, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "binding: field \"age\": invalid", "field": "age"}`, w.Body.String())
+}
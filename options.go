@@ -0,0 +1,252 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"net/http"
+	"time"
+)
+
+// Options configures how the bindings process their input.
+type Options struct {
+	// Normalization is the Unicode normalization applied to every bound
+	// string. It defaults to NormalizeNone.
+	Normalization Normalization
+
+	// InvalidUTF8 is the policy applied to bound strings which aren't valid
+	// UTF-8. It defaults to UTF8Allow.
+	InvalidUTF8 UTF8Policy
+
+	// ControlChars is the policy applied to ASCII control characters in
+	// bound strings. The control tag of a field lists the ones it accepts
+	// regardless, e.g. `control:"newline,tab"` for multi-line text. It
+	// defaults to ControlAllow.
+	ControlChars ControlPolicy
+
+	// InternStrings makes the bindings intern bound strings of up to 64
+	// bytes, such as country codes or enum-like values, so that servers
+	// binding many similar requests keep a single copy of each in memory.
+	InternStrings bool
+
+	// EmptyBody is the policy applied by the JSON, XML and MsgPack bindings
+	// to requests without a body. It defaults to EmptyBodyError.
+	EmptyBody EmptyBodyPolicy
+
+	// VerifyDigest makes body bindings check the body against its
+	// Content-MD5 or Digest (MD5, SHA-256 or SHA-512) header while reading
+	// it, failing with ErrDigestMismatch.
+	VerifyDigest bool
+
+	// LazyMultipart makes the multipart binding stream through the body,
+	// reading only the parts named by a key the struct is bound from and
+	// skipping others unread, instead of parsing the whole form. Checksums
+	// of files are computed while streaming them. req.MultipartForm then only
+	// holds the values read; large files are kept in temporary files, which
+	// the caller removes with UploadedFile.Remove once the bind succeeds and
+	// the binding removes when it fails.
+	LazyMultipart bool
+
+	// InspectFile, if set, is passed the content of every file the
+	// multipart binding binds, e.g. to scan it for viruses or to generate
+	// thumbnails, and can veto the bind. With LazyMultipart it runs inline
+	// while the file is streamed from the body.
+	InspectFile FileInspector
+
+	// StoreFile, if set, is passed the content of every file the multipart
+	// binding binds, e.g. to upload it to object storage. The key it
+	// returns is set as UploadedFile.ObjectKey and bound into string fields
+	// tagged with the form key of the file, e.g.
+	//
+	//	AvatarKey string `object_key:"avatar"`
+	//
+	// With LazyMultipart files are streamed from the body to StoreFile and
+	// never kept locally.
+	StoreFile FileStore
+
+	// MultipartWorkers, if above 1, makes the multipart binding decode the
+	// JSON and CSV parts bound to different fields concurrently, on up to
+	// this many goroutines, which cuts the latency of requests carrying
+	// several large documents. Such parts are either values or files; files
+	// sent as text/csv are bound into slices of structs.
+	MultipartWorkers int
+
+	// MaxBodySize, if positive, bounds the size of request bodies in bytes,
+	// and MaxFormValues the number of values of forms, queries included.
+	// Requests exceeding them fail with a *LimitError, whose RetryAfter is
+	// set to LimitRetryAfter.
+	MaxBodySize     int64
+	MaxFormValues   int
+	LimitRetryAfter time.Duration
+
+	// ProfileLabels makes the bindings run under the pprof labels
+	// binding.struct, binding.content_type and binding.name, so that CPU
+	// profiles attribute the cost of decoding to each request struct.
+	ProfileLabels bool
+
+	// KeySeparator is the separator of the names of form keys in dot
+	// notation, e.g. "__" for user__address__city. It defaults to ".".
+	// Brackets, backslashes and the separator are literal in names when
+	// escaped with a backslash, e.g. meta.config\.yaml for the entry
+	// config.yaml of the map meta, or meta[a\]b] for a]b.
+	KeySeparator string
+
+	// MaxSliceIndex bounds the indices of slice elements in form keys, e.g.
+	// items.5.name, which the slice is grown to hold. Greater indices fail
+	// with an *IndexError. It defaults to 1000.
+	MaxSliceIndex int
+
+	// MaxDepth bounds the nesting of the structs bound from prefixed, dotted
+	// or bracketed keys, e.g. the levels of a category tree bound from
+	// parent.parent.name. Deeper keys fail with a *LimitError. It defaults
+	// to 32.
+	MaxDepth int
+
+	// CollectFieldErrors makes the form bindings bind every field they can
+	// rather than stop at the first value which can't be bound, failing
+	// with the FieldErrors of all such fields.
+	CollectFieldErrors bool
+
+	// HTMLBools makes the form bindings parse every boolean the way the
+	// bool_format:"html" tag makes them parse the booleans of a field, see
+	// htmlBool.
+	HTMLBools bool
+
+	// CaseInsensitiveKeys makes the form bindings match the keys of fields,
+	// and their aliases, with form keys differing in case only, e.g. userid
+	// or UserID for user_id, when no form key matches exactly. The first of
+	// such keys in sorted order is used. The keys of nested structs and
+	// maps are still matched exactly.
+	CaseInsensitiveKeys bool
+
+	// FormTagFirst makes the form bindings, MapAny, MapArgs and MapFlags
+	// read the keys of fields from their form tag before their json tag, so that structs
+	// also rendered as JSON responses can bind under other names, e.g.
+	//
+	//	UserID int `json:"userId" form:"user_id"`
+	//
+	// binds from user_id rather than userId. The json tag is read first by
+	// default.
+	FormTagFirst bool
+
+	// NoJSONValues makes the form bindings fail with ErrJSONValue rather
+	// than decode the values of struct, map, slice and interface fields as
+	// JSON, e.g. filter={"status": "open"}, so that they are only bound from
+	// their own keys, e.g. filter.status=open. JSON arrays of strings, bools
+	// and numbers are taken as single elements. The nojson option of the key
+	// tag of a field does the same for the field, e.g.
+	//
+	//	Filter Filter `form:"filter,nojson"`
+	NoJSONValues bool
+
+	// WindowsTimeZones makes the form bindings accept the Windows names of
+	// time zones, e.g. Romance Standard Time, for *time.Location fields,
+	// binding them to their IANA zone, e.g. Europe/Paris. The time_location
+	// tag always accepts them.
+	WindowsTimeZones bool
+
+	// Strict is the mode the strict checks of the form bindings are applied
+	// in, and ReportStrict, if set, is passed their violations in the
+	// StrictReport mode. It defaults to StrictOff.
+	Strict       StrictMode
+	ReportStrict func(req *http.Request, w Warning)
+
+	// CheckHeaders makes the Header binding check the custom request
+	// headers, those prefixed with X-, reporting the ones no field is bound
+	// from and not listed in AllowedHeaders, e.g. X-Forwarded-For, as
+	// WarningUnexpectedHeader warnings, which Strict applies to like to the
+	// other strict checks.
+	CheckHeaders   bool
+	AllowedHeaders []string
+
+	// Corpus, if set, records the anonymized shape of every request bound,
+	// see CorpusRecorder.
+	Corpus *CorpusRecorder
+
+	// Stats, if set, counts the binds of each struct type, see BindStats.
+	Stats *BindStats
+
+	// NegotiateErrors makes the bindings wrap their errors in a
+	// *NegotiatedError carrying the media type, JSON, problem+json or plain
+	// text, the Accept header of the request prefers them rendered as.
+	NegotiateErrors bool
+
+	// ZeroBeforeBind makes the bindings reset the bound struct to its zero
+	// value first, so that reused structs, e.g. from a Pool, never keep
+	// values of a previous request.
+	ZeroBeforeBind bool
+
+	// FieldNaming is the naming strategy of the keys the form bindings and
+	// MapAny bind the fields without json nor form tag from, e.g. user_id
+	// for UserID with NamingSnakeCase. It defaults to NamingFieldName, the
+	// Go name of the fields.
+	FieldNaming Naming
+
+	// UnknownContentType is the policy applied by Decoder.Bind to requests
+	// with a missing or unrecognized content type. It defaults to
+	// ContentTypeForm.
+	UnknownContentType ContentTypePolicy
+}
+
+// DefaultOptions are the Options used by the package-level bindings.
+var DefaultOptions Options
+
+// Normalization selects a Unicode normalization form.
+type Normalization int
+
+const (
+	// NormalizeNone leaves strings untouched.
+	NormalizeNone Normalization = iota
+	// NormalizeNFC applies canonical composition, so that canonically
+	// equivalent strings (e.g. "é" as one or two code points) compare equal.
+	NormalizeNFC
+	// NormalizeNFKC applies compatibility composition, additionally folding
+	// compatibility forms such as "ﬁ" or full-width letters.
+	NormalizeNFKC
+)
+
+// UTF8Policy selects how strings with invalid UTF-8 are handled.
+type UTF8Policy int
+
+const (
+	// UTF8Allow binds invalid UTF-8 as is.
+	UTF8Allow UTF8Policy = iota
+	// UTF8Reject fails the bind with ErrInvalidUTF8.
+	UTF8Reject
+	// UTF8Replace replaces each run of invalid bytes with U+FFFD.
+	UTF8Replace
+	// UTF8Strip removes invalid bytes.
+	UTF8Strip
+)
+
+// ControlPolicy selects how ASCII control characters are handled.
+type ControlPolicy int
+
+const (
+	// ControlAllow binds control characters as is.
+	ControlAllow ControlPolicy = iota
+	// ControlReject fails the bind with ErrControlChar.
+	ControlReject
+	// ControlStrip removes control characters.
+	ControlStrip
+)
+
+// Naming selects the keys untagged fields are bound from, see
+// Options.FieldNaming.
+type Naming int
+
+const (
+	// NamingFieldName binds untagged fields from their Go name, e.g.
+	// UserID.
+	NamingFieldName Naming = iota
+	// NamingSnakeCase binds them from their name in snake case, e.g.
+	// user_id.
+	NamingSnakeCase
+	// NamingCamelCase binds them from their name in camel case, e.g.
+	// userId.
+	NamingCamelCase
+	// NamingKebabCase binds them from their name in kebab case, e.g.
+	// user-id.
+	NamingKebabCase
+)
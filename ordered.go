@@ -0,0 +1,232 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// OrderedMap is a map keeping its keys in insertion order. Bound from a JSON
+// object or from bracketed form keys such as rules[deny]=...&rules[allow]=...,
+// it keeps the order the client sent the keys in, for inputs where it is
+// meaningful. The zero value is an empty map ready to use.
+type OrderedMap[V any] struct {
+	keys   []string
+	values map[string]V
+}
+
+// Set sets the value of key, appending key if it is new.
+func (m *OrderedMap[V]) Set(key string, value V) {
+	if m.values == nil {
+		m.values = make(map[string]V)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of key and whether it is set.
+func (m *OrderedMap[V]) Get(key string) (V, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Keys returns the keys of m in order.
+func (m *OrderedMap[V]) Keys() []string {
+	return append([]string(nil), m.keys...)
+}
+
+// Len returns the number of keys of m.
+func (m *OrderedMap[V]) Len() int {
+	return len(m.keys)
+}
+
+// MarshalJSON implements json.Marshaler, writing the keys in order.
+func (m OrderedMap[V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping the keys of the object
+// in order.
+func (m *OrderedMap[V]) UnmarshalJSON(data []byte) error {
+	m.reset()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('{') {
+		return errors.New("binding: OrderedMap needs a JSON object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value V
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		m.Set(tok.(string), value)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+func (m *OrderedMap[V]) reset() {
+	m.keys, m.values = nil, nil
+}
+
+func (m *OrderedMap[V]) setEntry(key, val string, field *fieldInfo) error {
+	var value V
+	v := reflect.ValueOf(&value).Elem()
+	if err := setValue(val, v.Type(), v, field); err != nil {
+		return err
+	}
+	m.Set(key, value)
+	return nil
+}
+
+// orderedMap is implemented by pointers to OrderedMap types.
+type orderedMap interface {
+	reset()
+	setEntry(key, val string, field *fieldInfo) error
+}
+
+var orderedMapType = reflect.TypeOf((*orderedMap)(nil)).Elem()
+
+// isOrderedMap reports whether typ is an OrderedMap type.
+func isOrderedMap(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && reflect.PtrTo(typ).Implements(orderedMapType)
+}
+
+// setOrderedEntries sets the OrderedMap field from the keys naming its
+// entries in brackets, in the order of the keys in the request, reporting
+// whether there are any.
+func (m *formMapper) setOrderedEntries(field *fieldInfo, structField reflect.Value) (bool, error) {
+	prefix := field.key + "["
+	var keys []string
+	for key, values := range m.form {
+		if len(values) == 0 || !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		if strings.ContainsAny(key[len(prefix):len(key)-1], "[]") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return false, nil
+	}
+	order := m.state.keyOrder
+	sort.Slice(keys, func(i, j int) bool {
+		pi, iok := order[m.inputKey(keys[i])]
+		pj, jok := order[m.inputKey(keys[j])]
+		if iok != jok {
+			return iok
+		}
+		if iok && pi != pj {
+			return pi < pj
+		}
+		return keys[i] < keys[j]
+	})
+
+	om := structField.Addr().Interface().(orderedMap)
+	om.reset()
+	for _, key := range keys {
+		m.markUsed(key)
+		entry := key[len(prefix) : len(key)-1]
+		if err := om.setEntry(entry, m.form[key][0], field); err != nil {
+			return true, &MapEntryError{Key: entry, Err: err}
+		}
+	}
+	return true, nil
+}
+
+// maxOrderedBody bounds the part of URL-encoded bodies whose key order is
+// recorded.
+const maxOrderedBody = 10 << 20
+
+// trackKeyOrder records the order of the keys of the query and, if body is
+// set, of the URL-encoded body of req, when obj has OrderedMap fields. The
+// body is restored for the binding to parse.
+func (s *bindState) trackKeyOrder(req *http.Request, obj interface{}, body bool) error {
+	typ := reflect.TypeOf(obj)
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	info, err := cachedStructInfo(typ.Elem(), s.keyTag())
+	if err != nil || !info.ordered {
+		return err
+	}
+	s.keyOrder = make(map[string]int)
+	addKeyOrder(s.keyOrder, req.URL.RawQuery)
+
+	if !body || req.Body == nil {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != MIMEPOSTForm {
+		return nil
+	}
+	buf, err := ioutil.ReadAll(io.LimitReader(req.Body, maxOrderedBody))
+	if err != nil {
+		return err
+	}
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), req.Body), req.Body}
+	addKeyOrder(s.keyOrder, string(buf))
+	return nil
+}
+
+// addKeyOrder numbers the keys of the URL-encoded query not in order yet.
+func addKeyOrder(order map[string]int, query string) {
+	for _, pair := range strings.Split(query, "&") {
+		if i := strings.IndexByte(pair, '='); i != -1 {
+			pair = pair[:i]
+		}
+		key, ok := queryUnescape(pair)
+		if !ok || key == "" {
+			continue
+		}
+		if _, ok := order[key]; !ok {
+			order[key] = len(order)
+		}
+	}
+}
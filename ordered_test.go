@@ -0,0 +1,72 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForOrderedMap struct {
+	Rules  OrderedMap[string] `form:"rules" json:"rules"`
+	Limits OrderedMap[int]    `form:"limits" json:"limits"`
+}
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap[int]
+	m.Set("b", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	assert.Equal(t, []string{"b", "a"}, m.Keys())
+	assert.Equal(t, 2, m.Len())
+	v, ok := m.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	_, ok = m.Get("c")
+	assert.False(t, ok)
+
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"b":3,"a":2}`, string(data))
+}
+
+func TestOrderedMapJSON(t *testing.T) {
+	obj := FooStructForOrderedMap{}
+	req := requestWithBody("POST", "/", `{"rules": {"z": "deny", "a": "allow", "m": "log"}}`)
+	assert.NoError(t, JSON.Bind(req, &obj))
+	assert.Equal(t, []string{"z", "a", "m"}, obj.Rules.Keys())
+	rule, _ := obj.Rules.Get("a")
+	assert.Equal(t, "allow", rule)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"rules": [1]}`), &obj))
+	assert.Error(t, json.Unmarshal([]byte(`{"limits": {"a": "x"}}`), &obj))
+	assert.NoError(t, json.Unmarshal([]byte(`{"rules": null}`), &obj))
+	assert.Equal(t, 0, obj.Rules.Len())
+}
+
+func TestOrderedMapForm(t *testing.T) {
+	obj := FooStructForOrderedMap{}
+	req := requestWithBody("POST", "/?limits[z]=1&limits[a]=2", "rules[z]=deny&rules[a]=allow&rules[m]=log")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	assert.NoError(t, Form.Bind(req, &obj))
+	assert.Equal(t, []string{"z", "a", "m"}, obj.Rules.Keys())
+	assert.Equal(t, []string{"z", "a"}, obj.Limits.Keys())
+	limit, _ := obj.Limits.Get("a")
+	assert.Equal(t, 2, limit)
+
+	obj = FooStructForOrderedMap{}
+	req = requestWithBody("GET", "/?rules[y]=1&rules[b]=2&rules[y]=3", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, []string{"y", "b"}, obj.Rules.Keys())
+
+	obj = FooStructForOrderedMap{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"rules[b]": {"1"}, "rules[a]": {"2"}}))
+	assert.Equal(t, []string{"a", "b"}, obj.Rules.Keys())
+
+	req = requestWithBody("GET", "/?limits[a]=x", "")
+	assert.Error(t, Query.Bind(req, &obj))
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"reflect"
+	"sync"
+)
+
+// decodedAsJSON reports whether values of typ are decoded from JSON as a
+// whole rather than converted, see setWithProperType.
+func decodedAsJSON(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType || convertible(typ) || isWeighted(typ) {
+		return false
+	}
+	if typ == rawMessageType {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Slice, reflect.Array:
+		return !repeatable(typ.Elem())
+	case reflect.Map:
+		return !convertible(typ.Key()) && !convertible(typ.Elem())
+	}
+	return false
+}
+
+// setPartField decodes the field from the file part of its key, as JSON or,
+// for slices of structs sent as text/csv, as CSV with a header row naming the
+// form keys of the columns. This lets clients upload large documents as
+// parts of a multipart form. It reports whether there is such a part.
+func (m *formMapper) setPartField(field *fieldInfo, typ reflect.Type, structField reflect.Value) (bool, error) {
+	files := m.files(field.key)
+	if len(files) == 0 || !decodedAsJSON(typ) {
+		return false, nil
+	}
+	m.markUsed(field.key)
+	f := files[0]
+	return true, m.decode(func() error {
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		mediaType, _, _ := mime.ParseMediaType(f.ContentType)
+		if mediaType == MIMECSV {
+			return decodeCSVPart(r, typ, structField, m.state.opts)
+		}
+		value := reflect.New(typ)
+		if err := json.NewDecoder(r).Decode(value.Interface()); err != nil {
+			return err
+		}
+		structField.Set(value.Elem())
+		return nil
+	})
+}
+
+// decodeCSVPart sets the slice of structs value from the CSV records of r.
+func decodeCSVPart(r io.Reader, typ reflect.Type, value reflect.Value, opts *Options) error {
+	elem := typ.Elem()
+	ptr := elem.Kind() == reflect.Ptr
+	if ptr {
+		elem = elem.Elem()
+	}
+	if typ.Kind() != reflect.Slice || elem.Kind() != reflect.Struct {
+		return errors.New("CSV parts need a slice of structs field")
+	}
+	s := &bindState{opts: opts}
+	slice := reflect.MakeSlice(typ, 0, 0)
+	var mapErr error
+	err := readCSVForms(r, func(n int, form map[string][]string) bool {
+		item := reflect.New(elem)
+		if err := mapFormState(item.Interface(), form, s); err != nil {
+			mapErr = &RecordError{Record: n, Err: err}
+			return false
+		}
+		if !ptr {
+			item = item.Elem()
+		}
+		slice = reflect.Append(slice, item)
+		return true
+	})
+	if err == nil {
+		err = mapErr
+	}
+	if err != nil {
+		return err
+	}
+	value.Set(slice)
+	return nil
+}
+
+// decode runs fn, decoding a field, unless the fields of a multipart form
+// are decoded in parallel, see Options.MultipartWorkers, in which case fn is
+// queued to run once every field has been visited.
+func (m *formMapper) decode(fn func() error) error {
+	if m.state.files == nil || !formTag(m.tag) || m.state.opts.MultipartWorkers < 2 {
+		return fn()
+	}
+	path, key := m.fieldPath, m.fieldKey
+	m.jobs = append(m.jobs, decodeJob{seq: m.seq, fn: func() error {
+		if err := fn(); err != nil {
+			return &FieldError{Path: path, Key: key, Err: err}
+		}
+		return nil
+	}})
+	return nil
+}
+
+// decodeJob is a queued decode of the field numbered seq, see
+// formMapper.seq.
+type decodeJob struct {
+	seq int
+	fn  func() error
+}
+
+// runJobs runs the queued decodes on up to workers goroutines, returning the
+// error of each.
+func runJobs(jobs []decodeJob, workers int) []error {
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, job decodeJob) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = job.fn()
+		}(i, job)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForPartRow struct {
+	ID    int    `form:"id"`
+	Label string `form:"label"`
+}
+
+type FooStructForParts struct {
+	Title  string                 `form:"title"`
+	Config map[string]int         `form:"config"`
+	Events []FooStructForPartRow  `form:"events"`
+	Rows   []*FooStructForPartRow `form:"rows"`
+}
+
+func createPartsRequest(config, events, rows string) *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("title", "ingest")
+	mw.WriteField("config", config)
+	w, _ := mw.CreateFormFile("events", "events.json")
+	w.Write([]byte(events))
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="rows"; filename="rows.csv"`)
+	h.Set("Content-Type", "text/csv")
+	w, _ = mw.CreatePart(h)
+	w.Write([]byte(rows))
+	mw.Close()
+	req, _ := http.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func testBindParts(t *testing.T) {
+	req := createPartsRequest(`{"batch": 100}`, `[{"ID": 1, "Label": "a"}]`, "id,label\n2,b\n3,c\n")
+	var obj FooStructForParts
+	assert.NoError(t, FormMultipart.Bind(req, &obj))
+	assert.Equal(t, "ingest", obj.Title)
+	assert.Equal(t, map[string]int{"batch": 100}, obj.Config)
+	assert.Equal(t, []FooStructForPartRow{{ID: 1, Label: "a"}}, obj.Events)
+	assert.Equal(t, []*FooStructForPartRow{{ID: 2, Label: "b"}, {ID: 3, Label: "c"}}, obj.Rows)
+
+	req = createPartsRequest(`{"batch": "x"}`, `[`, "id,label\nx,b\n")
+	err := FormMultipart.Bind(req, &FooStructForParts{})
+	if assert.IsType(t, &FieldError{}, err) {
+		assert.Equal(t, "config", err.(*FieldError).Key)
+	}
+
+	req = createPartsRequest(`{}`, `[]`, "id,label\nx,b\n")
+	err = FormMultipart.Bind(req, &FooStructForParts{})
+	assert.Contains(t, err.Error(), "record 1")
+}
+
+func TestBindParts(t *testing.T) {
+	testBindParts(t)
+}
+
+func TestBindPartsParallel(t *testing.T) {
+	defer withOptions(Options{MultipartWorkers: 4})()
+	testBindParts(t)
+}
+
+func TestBindPartsParallelLazy(t *testing.T) {
+	defer withOptions(Options{MultipartWorkers: 2, LazyMultipart: true})()
+	testBindParts(t)
+}
+
+func TestRunJobs(t *testing.T) {
+	var ran [5]bool
+	jobs := make([]decodeJob, len(ran))
+	for i := range jobs {
+		i := i
+		jobs[i].fn = func() error {
+			ran[i] = true
+			if i >= 3 {
+				return &RecordError{Record: i}
+			}
+			return nil
+		}
+	}
+	errs := runJobs(jobs, 2)
+	assert.Equal(t, [5]bool{true, true, true, true, true}, ran)
+	assert.Nil(t, errs[2])
+	assert.Equal(t, 3, errs[3].(*RecordError).Record)
+	assert.Equal(t, 4, errs[4].(*RecordError).Record)
+}
@@ -0,0 +1,375 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// PermissionFilter guards the fields tagged with perm against mass assignment.
+// A field tagged
+//
+//	Role string `json:"role" perm:"admin"`
+//
+// may only be bound by callers granted the admin permission. Several
+// permissions separated by commas grant the field to callers holding any of
+// them.
+type PermissionFilter struct {
+	// Granted holds the permissions of the caller.
+	Granted []string
+	// Reject fails the bind with a *PermissionError when a guarded field is
+	// bound. By default the field is reset to the value it held before
+	// binding, the zero value for a new struct.
+	Reject bool
+}
+
+// BindWithPermissions binds the request with b like b.Bind does, filtering
+// the fields the caller isn't granted with f before obj is validated.
+func BindWithPermissions(req *http.Request, obj interface{}, b Binding, f PermissionFilter) error {
+	s := newBindState()
+	// the guarded fields are looked up with the keys the binding reads, the
+	// Query binding reading query tags first
+	_, s.fromQuery = b.(queryBinding)
+	// the guarded fields are saved once zeroed, which run doesn't redo
+	s.zero(obj)
+	c, err := newPermCheck(obj, f, s.keyTag())
+	if err != nil {
+		return err
+	}
+	sb, ok := b.(stateBinding)
+	if !ok {
+		if err := b.Bind(req, obj); err != nil {
+			return err
+		}
+		return c.apply()
+	}
+	s.perms = c
+	return s.run(sb, req, obj)
+}
+
+// permCheck holds the values of the guarded fields of a struct before binding.
+type permCheck struct {
+	reject  bool
+	granted map[string]bool
+	// tag is the tag of the struct metadata the guarded fields are read
+	// from, see bindState.keyTag.
+	tag    string
+	fields []permField
+	// ptrs lists the nil nested pointer fields whose structs have guarded
+	// fields, checked if the binding allocates them.
+	ptrs []permField
+	// probing lists the structs of the nil pointers being probed for
+	// guarded fields.
+	probing map[reflect.Type]bool
+	// elems lists the pointer, slice, array and map fields whose elements
+	// have guarded fields, with a deep copy of their value, see restore.
+	elems []permField
+}
+
+type permField struct {
+	path string
+	// prefix is the prefix tag of the nested structs of c.ptrs, see collect.
+	prefix string
+	perm   string
+	value  reflect.Value
+	saved  reflect.Value
+}
+
+// newPermCheck saves the fields of obj f doesn't grant, read from the struct
+// metadata of tag, or returns nil if there are none.
+func newPermCheck(obj interface{}, f PermissionFilter, tag string) (*permCheck, error) {
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return nil, nil
+	}
+	granted := make(map[string]bool, len(f.Granted))
+	for _, perm := range f.Granted {
+		granted[perm] = true
+	}
+	c := &permCheck{reject: f.Reject, granted: granted, tag: tag}
+	if err := c.collect(val.Elem(), "", "", granted); err != nil {
+		return nil, err
+	}
+	if len(c.fields) == 0 && len(c.ptrs) == 0 && len(c.elems) == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// collect saves the guarded fields of the struct val. prefix is the prefix
+// tag of the nested structs val is in, which the keys of its fields have in
+// their path.
+func (c *permCheck) collect(val reflect.Value, path, prefix string, granted map[string]bool) error {
+	info, err := cachedStageInfo(val.Type(), c.tag)
+	if err != nil {
+		return err
+	}
+	for _, field := range info.fields {
+		structField := val.Field(field.index)
+		if field.nested {
+			nestedPrefix := prefix + field.prefix
+			nested := nestedValue(structField)
+			if nested.IsValid() {
+				if err := c.collect(nested, path, nestedPrefix, granted); err != nil {
+					return err
+				}
+				continue
+			}
+			// recursive structs aren't probed again
+			elem := structField.Type().Elem()
+			if c.probing[elem] {
+				continue
+			}
+			if c.probing == nil {
+				c.probing = make(map[reflect.Type]bool)
+			}
+			c.probing[elem] = true
+			guarded := &permCheck{tag: c.tag, probing: c.probing}
+			err := guarded.collect(reflect.New(elem).Elem(), path, nestedPrefix, granted)
+			delete(c.probing, elem)
+			if err != nil {
+				return err
+			}
+			if len(guarded.fields) > 0 || len(guarded.ptrs) > 0 || len(guarded.elems) > 0 {
+				c.ptrs = append(c.ptrs, permField{path: path, prefix: nestedPrefix, value: structField})
+			}
+			continue
+		}
+		fieldPath := joinPath(path, prefix+field.key)
+		if len(field.perms) == 0 {
+			switch structField.Kind() {
+			case reflect.Struct:
+				if err := c.collect(structField, fieldPath, "", granted); err != nil {
+					return err
+				}
+			case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+				ok, err := guardsElems(structField.Type(), c.tag, granted, make(map[reflect.Type]bool))
+				if err != nil {
+					return err
+				}
+				if ok {
+					c.elems = append(c.elems, permField{path: fieldPath, value: structField, saved: deepCopy(structField)})
+				}
+			}
+			continue
+		}
+		if hasAnyPerm(granted, field.perms) {
+			continue
+		}
+		saved := reflect.New(structField.Type()).Elem()
+		saved.Set(structField)
+		c.fields = append(c.fields, permField{
+			path:  fieldPath,
+			perm:  strings.Join(field.perms, ","),
+			value: structField,
+			saved: saved,
+		})
+	}
+	return nil
+}
+
+func hasAnyPerm(granted map[string]bool, perms []string) bool {
+	for _, perm := range perms {
+		if granted[perm] {
+			return true
+		}
+	}
+	return false
+}
+
+// apply resets or rejects the guarded fields changed by the binding. A nil
+// check does nothing.
+func (c *permCheck) apply() error {
+	if c == nil {
+		return nil
+	}
+	// allocated structs may in turn hold nil pointers, appended to c.ptrs
+	for i := 0; i < len(c.ptrs); i++ {
+		ptr := c.ptrs[i]
+		if ptr.value.IsNil() {
+			continue
+		}
+		// the struct was allocated by the binding, so its guarded fields
+		// held their zero values
+		n, nElems := len(c.fields), len(c.elems)
+		if err := c.collect(ptr.value.Elem(), ptr.path, ptr.prefix, c.granted); err != nil {
+			return err
+		}
+		for i := n; i < len(c.fields); i++ {
+			c.fields[i].saved = reflect.Zero(c.fields[i].value.Type())
+		}
+		for i := nElems; i < len(c.elems); i++ {
+			c.elems[i].saved = reflect.Value{}
+		}
+	}
+	c.ptrs = nil
+	for _, field := range c.elems {
+		if err := c.restore(field.path, "", field.value, field.saved); err != nil {
+			return err
+		}
+	}
+	for _, field := range c.fields {
+		if reflect.DeepEqual(field.value.Interface(), field.saved.Interface()) {
+			continue
+		}
+		if c.reject {
+			return &PermissionError{Path: field.path, Perm: field.perm}
+		}
+		field.value.Set(field.saved)
+	}
+	return nil
+}
+
+// guardsElems reports whether typ, or the elements of the pointers, slices,
+// arrays and maps it is made of, are structs with fields granted doesn't
+// grant, read from the struct metadata of tag.
+func guardsElems(typ reflect.Type, tag string, granted map[string]bool, seen map[reflect.Type]bool) (bool, error) {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return guardsElems(typ.Elem(), tag, granted, seen)
+	case reflect.Struct:
+	default:
+		return false, nil
+	}
+	if seen[typ] {
+		return false, nil
+	}
+	seen[typ] = true
+	info, err := cachedStageInfo(typ, tag)
+	if err != nil {
+		return false, err
+	}
+	for _, field := range info.fields {
+		if len(field.perms) > 0 && !hasAnyPerm(granted, field.perms) {
+			return true, nil
+		}
+		ok, err := guardsElems(typ.Field(field.index).Type, tag, granted, seen)
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// deepCopy returns a copy of v sharing none of the pointers, slices and maps
+// reached through its settable fields, so that the binding doesn't change it.
+func deepCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			c.Set(reflect.New(v.Type().Elem()))
+			c.Elem().Set(deepCopy(v.Elem()))
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				c.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			iter := v.MapRange()
+			for iter.Next() {
+				c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+			}
+		}
+	case reflect.Struct:
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+	default:
+		c.Set(v)
+	}
+	return c
+}
+
+// restore resets or rejects the guarded fields of the structs reached through
+// v, the value of path once bound, which differ from their value in saved, a
+// deep copy of v before binding. Elements the binding added are compared with
+// zero values. prefix is the prefix tag of the nested structs v is in, see
+// collect.
+func (c *permCheck) restore(path, prefix string, v, saved reflect.Value) error {
+	if !saved.IsValid() {
+		saved = reflect.Zero(v.Type())
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if saved.IsNil() {
+			return c.restore(path, prefix, v.Elem(), reflect.Value{})
+		}
+		return c.restore(path, prefix, v.Elem(), saved.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			var elem reflect.Value
+			if i < saved.Len() {
+				elem = saved.Index(i)
+			}
+			if err := c.restore(fmt.Sprintf("%s[%d]", path, i), "", v.Index(i), elem); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map elements aren't addressable, so they are restored in a
+			// copy stored back
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			var savedElem reflect.Value
+			if !saved.IsNil() {
+				savedElem = saved.MapIndex(iter.Key())
+			}
+			if err := c.restore(fmt.Sprintf("%s[%v]", path, iter.Key()), "", elem, savedElem); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Struct:
+		info, err := cachedStageInfo(v.Type(), c.tag)
+		if err != nil {
+			return err
+		}
+		for _, field := range info.fields {
+			fieldValue, savedValue := v.Field(field.index), saved.Field(field.index)
+			if !fieldValue.CanSet() {
+				continue
+			}
+			fieldPath, fieldPrefix := path, prefix+field.prefix
+			if !field.nested {
+				fieldPath, fieldPrefix = joinPath(path, prefix+field.key), ""
+			}
+			if len(field.perms) == 0 || hasAnyPerm(c.granted, field.perms) {
+				if err := c.restore(fieldPath, fieldPrefix, fieldValue, savedValue); err != nil {
+					return err
+				}
+				continue
+			}
+			if reflect.DeepEqual(fieldValue.Interface(), savedValue.Interface()) {
+				continue
+			}
+			if c.reject {
+				return &PermissionError{Path: fieldPath, Perm: strings.Join(field.perms, ",")}
+			}
+			fieldValue.Set(savedValue)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForPerm struct {
+	Name    string `json:"name" form:"name"`
+	Role    string `json:"role" form:"role" perm:"admin"`
+	Balance int    `json:"balance" form:"balance" perm:"admin, billing"`
+	Profile struct {
+		Verified bool `json:"verified" perm:"admin"`
+	} `json:"profile"`
+}
+
+func TestBindWithPermissions(t *testing.T) {
+	body := `{"name": "manu", "role": "admin", "balance": 10, "profile": {"verified": true}}`
+
+	obj := FooStructForPerm{Role: "user"}
+	err := BindWithPermissions(requestWithBody("POST", "/", body), &obj, JSON, PermissionFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, "manu", obj.Name)
+	assert.Equal(t, "user", obj.Role)
+	assert.Equal(t, 0, obj.Balance)
+	assert.False(t, obj.Profile.Verified)
+
+	obj = FooStructForPerm{}
+	err = BindWithPermissions(requestWithBody("POST", "/", body), &obj, JSON, PermissionFilter{Granted: []string{"billing"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "", obj.Role)
+	assert.Equal(t, 10, obj.Balance)
+
+	obj = FooStructForPerm{}
+	err = BindWithPermissions(requestWithBody("POST", "/", body), &obj, JSON, PermissionFilter{Granted: []string{"admin"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", obj.Role)
+	assert.True(t, obj.Profile.Verified)
+}
+
+func TestBindWithPermissionsReject(t *testing.T) {
+	obj := FooStructForPerm{}
+	req := requestWithBody("GET", "/?name=manu&role=admin", "")
+	err := BindWithPermissions(req, &obj, Query, PermissionFilter{Reject: true})
+	var permErr *PermissionError
+	if assert.True(t, errors.As(err, &permErr)) {
+		assert.Equal(t, "role", permErr.Path)
+	}
+	assert.EqualError(t, err, `binding: field "role" requires permission "admin"`)
+
+	obj = FooStructForPerm{}
+	req = requestWithBody("GET", "/?name=manu", "")
+	err = BindWithPermissions(req, &obj, Query, PermissionFilter{Reject: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "manu", obj.Name)
+}
+
+type FooStructForPermPointer struct {
+	Name    string `form:"name"`
+	Profile *struct {
+		Bio      string `form:"bio"`
+		Verified bool   `form:"verified" perm:"admin"`
+	}
+}
+
+func TestBindWithPermissionsPointer(t *testing.T) {
+	obj := FooStructForPermPointer{}
+	req := requestWithBody("GET", "/?name=manu&bio=hi&verified=true", "")
+	err := BindWithPermissions(req, &obj, Query, PermissionFilter{})
+	assert.NoError(t, err)
+	if assert.NotNil(t, obj.Profile) {
+		assert.Equal(t, "hi", obj.Profile.Bio)
+		assert.False(t, obj.Profile.Verified)
+	}
+
+	obj = FooStructForPermPointer{}
+	err = BindWithPermissions(req, &obj, Query, PermissionFilter{Reject: true})
+	assert.EqualError(t, err, `binding: field "verified" requires permission "admin"`)
+}
+
+type FooStructForPermRole struct {
+	Name string `json:"name"`
+	Role string `json:"role" perm:"admin"`
+}
+
+type FooStructForPermElems struct {
+	Profile *FooStructForPermRole            `json:"profile"`
+	Items   []FooStructForPermRole           `json:"items"`
+	Pair    [1]FooStructForPermRole          `json:"pair"`
+	ByName  map[string]*FooStructForPermRole `json:"by_name"`
+}
+
+type FooStructForPermNested struct {
+	*FooStructForPermElems
+}
+
+func TestBindWithPermissionsElems(t *testing.T) {
+	for _, body := range []string{
+		`{"profile": {"role": "root"}}`,
+		`{"items": [{"name": "a"}, {"role": "root"}]}`,
+		`{"pair": [{"role": "root"}]}`,
+		`{"by_name": {"a": {"role": "root"}}}`,
+	} {
+		var obj FooStructForPermElems
+		err := BindWithPermissions(requestWithBody("POST", "/", body), &obj, JSON, PermissionFilter{Reject: true})
+		var permErr *PermissionError
+		assert.True(t, errors.As(err, &permErr), body)
+	}
+
+	var nested FooStructForPermNested
+	err := BindWithPermissions(requestWithBody("POST", "/", `{"items": [{"role": "root"}]}`), &nested, JSON, PermissionFilter{Reject: true})
+	assert.EqualError(t, err, `binding: field "items[0].role" requires permission "admin"`)
+
+	obj := FooStructForPermElems{Items: []FooStructForPermRole{{Name: "a", Role: "user"}}}
+	body := `{"profile": {"name": "p", "role": "root"}, "items": [{"name": "b", "role": "root"}, {"role": "root"}], "pair": [{"role": "root"}], "by_name": {"a": {"name": "m", "role": "root"}}}`
+	assert.NoError(t, BindWithPermissions(requestWithBody("POST", "/", body), &obj, JSON, PermissionFilter{}))
+	assert.Equal(t, FooStructForPermRole{Name: "p"}, *obj.Profile)
+	assert.Equal(t, []FooStructForPermRole{{Name: "b", Role: "user"}, {}}, obj.Items)
+	assert.Equal(t, FooStructForPermRole{}, obj.Pair[0])
+	assert.Equal(t, FooStructForPermRole{Name: "m"}, *obj.ByName["a"])
+
+	obj = FooStructForPermElems{}
+	assert.NoError(t, BindWithPermissions(requestWithBody("POST", "/", body), &obj, JSON, PermissionFilter{Granted: []string{"admin"}}))
+	assert.Equal(t, "root", obj.Profile.Role)
+}
+
+func TestBindWithPermissionsOptions(t *testing.T) {
+	defer withOptions(Options{MaxBodySize: 8})()
+	req := requestWithBody("POST", "/?name=manu", "name=manuel")
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	err := BindWithPermissions(req, &FooStructForPerm{}, FormPost, PermissionFilter{})
+	var limitErr *LimitError
+	assert.True(t, errors.As(err, &limitErr), err)
+}
+
+type FooStructForPermFormFirst struct {
+	Name    string `json:"name" form:"name"`
+	IsAdmin bool   `json:"-" form:"is_admin" perm:"admin"`
+}
+
+func TestBindWithPermissionsFormTagFirst(t *testing.T) {
+	defer withOptions(Options{FormTagFirst: true})()
+
+	obj := FooStructForPermFormFirst{}
+	req := requestWithBody("POST", "/", "name=manu&is_admin=true")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	err := BindWithPermissions(req, &obj, FormPost, PermissionFilter{Reject: true})
+	assert.EqualError(t, err, `binding: field "is_admin" requires permission "admin"`)
+
+	obj = FooStructForPermFormFirst{}
+	req = requestWithBody("POST", "/", "name=manu&is_admin=true")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	assert.NoError(t, BindWithPermissions(req, &obj, FormPost, PermissionFilter{}))
+	assert.Equal(t, "manu", obj.Name)
+	assert.False(t, obj.IsAdmin)
+}
+
+type FooStructForPermQuery struct {
+	Name string `json:"name" query:"name"`
+	Role string `json:"-" query:"role" perm:"admin"`
+}
+
+func TestBindWithPermissionsQueryTag(t *testing.T) {
+	obj := FooStructForPermQuery{}
+	req := requestWithBody("GET", "/?name=manu&role=root", "")
+	err := BindWithPermissions(req, &obj, Query, PermissionFilter{Reject: true})
+	assert.EqualError(t, err, `binding: field "role" requires permission "admin"`)
+
+	obj = FooStructForPermQuery{}
+	req = requestWithBody("GET", "/?name=manu&role=root", "")
+	assert.NoError(t, BindWithPermissions(req, &obj, Query, PermissionFilter{}))
+	assert.Equal(t, "manu", obj.Name)
+	assert.Equal(t, "", obj.Role)
+}
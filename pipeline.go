@@ -0,0 +1,171 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Stage is a stage of the pipeline the values of fields go through when bound
+// from forms, queries, headers and other string sources. The stages run in
+// order:
+//
+//  1. StageLookup: the value is read from the key of the field, falling back
+//     to its deprecated alias.
+//  2. StageDefault: an absent value takes the default_if or default tag of
+//     the field.
+//  3. StageClean: the InvalidUTF8, ControlChars and Normalization policies of
+//     the Options apply, and WindowsTimeZones to time zone fields.
+//  4. StageModify: the value is modified by the steps of the field only.
+//  5. StageConvert: the value is converted to the type of the field, by a
+//     registered converter, encoding.TextUnmarshaler, or the built-in rules
+//     such as time_format.
+//  6. StageCheck: the min, max and pattern tags of the field are checked.
+//  7. StageValidate: the struct is completed from the in tag sources,
+//     permission filters and derive tags, then validated by Validator.
+//  8. StageHook: the AfterBind method of the struct is called.
+//
+// The steps listed by the steps tag of a field, e.g. `steps:"trim,lower"`, run
+// at the end of the stage they are registered for, see RegisterStep.
+type Stage int
+
+const (
+	StageLookup Stage = iota
+	StageDefault
+	StageClean
+	StageModify
+	StageConvert
+	StageCheck
+	StageValidate
+	StageHook
+)
+
+var stageNames = []string{"lookup", "default", "clean", "modify", "convert", "check", "validate", "hook"}
+
+func (s Stage) String() string {
+	return policyName(stageNames, int(s))
+}
+
+// Step is an extension of the pipeline of the fields listing it in their
+// steps tag, run at the end of its Stage.
+type Step struct {
+	Stage Stage
+	// Transform changes the string value of the field, for the stages up to
+	// StageModify. Repeated values are transformed one by one.
+	Transform func(val string) (string, error)
+	// Check checks the converted value of the field, for StageCheck.
+	Check func(v reflect.Value) error
+}
+
+// steps holds the registered steps, see RegisterStep.
+var steps sync.Map // map[string]Step
+
+// RegisterStep registers step under name, for fields to list in their steps
+// tag, e.g.
+//
+//	binding.RegisterStep("trim", binding.Step{Stage: binding.StageModify, Transform: func(s string) (string, error) {
+//		return strings.TrimSpace(s), nil
+//	}})
+//
+// Conversion is extended with RegisterConverter, validation with Validator
+// and the last stage with AfterBind, so RegisterStep panics for steps of
+// these stages or missing their function. Steps are meant to be registered at
+// init time, before the structs using them are bound.
+func RegisterStep(name string, step Step) {
+	switch {
+	case step.Stage <= StageModify && step.Transform != nil && step.Check == nil:
+	case step.Stage == StageCheck && step.Check != nil && step.Transform == nil:
+	default:
+		panic(fmt.Sprintf("binding: invalid step %q for stage %s", name, step.Stage))
+	}
+	steps.Store(name, step)
+}
+
+// AfterBinder is implemented by structs completing or checking themselves
+// once bound and validated, the last stage of the pipeline. Errors are
+// returned by Bind.
+type AfterBinder interface {
+	AfterBind() error
+}
+
+// afterBind calls the AfterBind method of obj, if any.
+func afterBind(obj interface{}) error {
+	if b, ok := obj.(AfterBinder); ok {
+		return b.AfterBind()
+	}
+	return nil
+}
+
+// compileSteps returns the steps listed by the steps tag of typeField.
+func compileSteps(typeField reflect.StructField) ([]Step, error) {
+	var list []Step
+	for _, name := range strings.Split(typeField.Tag.Get("steps"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		step, ok := steps.Load(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown step %q", name)
+		}
+		list = append(list, step.(Step))
+	}
+	return list, nil
+}
+
+// transform runs val, a value of field, through the string stages of the
+// pipeline from the stage from, StageLookup for the values read from keys and
+// StageDefault for default values, to StageModify.
+func (s *bindState) transform(val string, field *fieldInfo, from Stage) (string, error) {
+	for stage := from; stage <= StageModify; stage++ {
+		if stage == StageClean {
+			var err error
+			if val, err = s.cleanString(field.key, val, field.control); err != nil {
+				return "", err
+			}
+			if field.location && s.opts.WindowsTimeZones {
+				val = ianaZone(val)
+			}
+		}
+		for _, step := range field.steps {
+			if step.Stage != stage {
+				continue
+			}
+			var err error
+			if val, err = step.Transform(val); err != nil {
+				return "", err
+			}
+		}
+	}
+	return val, nil
+}
+
+// setDefault sets structField, of type typ, from val, the default value of
+// field, which goes through the stages from StageDefault on.
+func (s *bindState) setDefault(val string, typ reflect.Type, structField reflect.Value, field *fieldInfo) error {
+	val, err := s.transform(val, field, StageDefault)
+	if err != nil {
+		return err
+	}
+	if err := setDefaultValue(typ, val, structField, field); err != nil {
+		return err
+	}
+	return field.check(structField)
+}
+
+// check runs the StageCheck steps of field on v, its converted value.
+func (f *fieldInfo) check(v reflect.Value) error {
+	for _, step := range f.steps {
+		if step.Stage != StageCheck {
+			continue
+		}
+		if err := step.Check(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
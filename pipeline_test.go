@@ -0,0 +1,92 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	RegisterStep("hash", Step{Stage: StageLookup, Transform: func(s string) (string, error) {
+		return "#" + s, nil
+	}})
+	RegisterStep("trim", Step{Stage: StageModify, Transform: func(s string) (string, error) {
+		return strings.TrimSpace(s), nil
+	}})
+	RegisterStep("lower", Step{Stage: StageModify, Transform: func(s string) (string, error) {
+		return strings.ToLower(s), nil
+	}})
+	RegisterStep("even", Step{Stage: StageCheck, Check: func(v reflect.Value) error {
+		if v.Int()%2 != 0 {
+			return errors.New("odd")
+		}
+		return nil
+	}})
+}
+
+type FooStructForSteps struct {
+	Name  string   `form:"name" steps:"hash,trim,lower"`
+	Tags  []string `form:"tag" steps:"trim"`
+	Code  string   `form:"code" default:" ABC " steps:"hash,trim"`
+	Count int      `form:"count" steps:"trim,even"`
+}
+
+type FooStructForUnknownStep struct {
+	Name string `form:"name" steps:"trim,upper"`
+}
+
+type FooStructForAfterBind struct {
+	First string `form:"first"`
+	Last  string `form:"last"`
+	Full  string
+}
+
+func (f *FooStructForAfterBind) AfterBind() error {
+	if f.First == "" {
+		return errors.New("first name required")
+	}
+	f.Full = f.First + " " + f.Last
+	return nil
+}
+
+func TestMappingSteps(t *testing.T) {
+	var obj FooStructForSteps
+	err := mapForm(&obj, map[string][]string{"name": {" MANU"}, "tag": {" a ", "b "}, "count": {" 4 "}})
+	assert.NoError(t, err)
+	assert.Equal(t, FooStructForSteps{Name: "# manu", Tags: []string{"a", "b"}, Code: "ABC", Count: 4}, obj)
+
+	err = mapForm(&obj, map[string][]string{"count": {"3"}})
+	assert.EqualError(t, err, `binding: field "count": odd`)
+
+	obj = FooStructForSteps{}
+	assert.NoError(t, MapAny(&obj, map[string]interface{}{"name": "ANA ", "count": 2}))
+	assert.Equal(t, FooStructForSteps{Name: "#ana", Code: "ABC", Count: 2}, obj)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForUnknownStep]() })
+}
+
+func TestRegisterStepInvalid(t *testing.T) {
+	upper := func(s string) (string, error) { return strings.ToUpper(s), nil }
+	assert.Panics(t, func() { RegisterStep("upper", Step{Stage: StageConvert, Transform: upper}) })
+	assert.Panics(t, func() { RegisterStep("upper", Step{Stage: StageCheck, Transform: upper}) })
+	assert.Panics(t, func() { RegisterStep("upper", Step{Stage: StageModify}) })
+	assert.Equal(t, "modify", StageModify.String())
+}
+
+func TestBindAfterBind(t *testing.T) {
+	var obj FooStructForAfterBind
+	req := requestWithBody("GET", "/?first=manu&last=martinez", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, "manu martinez", obj.Full)
+
+	obj = FooStructForAfterBind{}
+	req = requestWithBody("POST", "/", `{"Last": "martinez"}`)
+	assert.EqualError(t, JSON.Bind(req, &obj), "first name required")
+}
@@ -0,0 +1,53 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import "sync"
+
+// Pool hands out zeroed request structs of type T for hot endpoints, saving
+// an allocation per request:
+//
+//	var loginPool binding.Pool[LoginForm]
+//
+//	func login(w http.ResponseWriter, req *http.Request) {
+//		form := loginPool.Get()
+//		defer loginPool.Release(form)
+//		if err := binding.Form.Bind(req, form); err != nil {
+//			...
+//		}
+//	}
+//
+// Structs must not be used once released. Release zeroes them so that Get
+// always returns zeroed structs; pools whose structs are only bound with the
+// ZeroBeforeBind option set can skip this by setting their own
+// ZeroBeforeBind. The zero Pool is ready to use.
+type Pool[T any] struct {
+	pool sync.Pool
+	// ZeroBeforeBind skips zeroing released structs, leaving it to the
+	// bindings run with the ZeroBeforeBind option. Get may then return
+	// structs holding the values of a previous request.
+	ZeroBeforeBind bool
+}
+
+// Get returns a struct from the pool, allocating one if the pool is empty.
+func (p *Pool[T]) Get() *T {
+	if v, ok := p.pool.Get().(*T); ok {
+		return v
+	}
+	return new(T)
+}
+
+// Release returns v to the pool, zeroing it first unless ZeroBeforeBind is
+// set.
+func (p *Pool[T]) Release(v *T) {
+	if v == nil {
+		return
+	}
+	if !p.ZeroBeforeBind {
+		var zero T
+		*v = zero
+	}
+	p.pool.Put(v)
+}
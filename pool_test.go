@@ -0,0 +1,52 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForPool struct {
+	Foo string `json:"foo" form:"foo"`
+	Bar string `json:"bar" form:"bar"`
+}
+
+func TestPool(t *testing.T) {
+	var p Pool[FooStructForPool]
+	obj := p.Get()
+	assert.Equal(t, FooStructForPool{}, *obj)
+	obj.Bar = "bar"
+	p.Release(obj)
+	p.Release(nil)
+	for i := 0; i < 3; i++ {
+		obj := p.Get()
+		assert.Equal(t, FooStructForPool{}, *obj)
+		p.Release(obj)
+	}
+}
+
+func TestZeroBeforeBind(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?foo=bar", nil)
+	obj := FooStructForPool{Foo: "old", Bar: "stale"}
+	assert.NoError(t, Form.Bind(req, &obj))
+	assert.Equal(t, "stale", obj.Bar)
+
+	defer withOptions(Options{ZeroBeforeBind: true})()
+	req, _ = http.NewRequest("GET", "/?foo=bar", nil)
+	assert.NoError(t, Form.Bind(req, &obj))
+	assert.Equal(t, FooStructForPool{Foo: "bar"}, obj)
+
+	p := Pool[FooStructForPool]{ZeroBeforeBind: true}
+	pooled := p.Get()
+	pooled.Bar = "stale"
+	p.Release(pooled)
+	pooled = p.Get()
+	req = requestWithBody("POST", "/", `{"foo": "baz"}`)
+	assert.NoError(t, JSON.Bind(req, pooled))
+	assert.Equal(t, FooStructForPool{Foo: "baz"}, *pooled)
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"mime"
+	"net/http"
+	"reflect"
+	"runtime/pprof"
+)
+
+// profileLabels returns the pprof labels of binding req onto obj with b, see
+// Options.ProfileLabels.
+func profileLabels(b stateBinding, req *http.Request, obj interface{}) pprof.LabelSet {
+	name := ""
+	if nb, ok := b.(Binding); ok {
+		name = nb.Name()
+	}
+	typ := reflect.TypeOf(obj)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	structName := "<nil>"
+	if typ != nil {
+		structName = typ.String()
+	}
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return pprof.Labels(
+		"binding.struct", structName,
+		"binding.content_type", contentType,
+		"binding.name", name,
+	)
+}
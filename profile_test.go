@@ -0,0 +1,40 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForProfile struct {
+	Foo string `json:"foo"`
+}
+
+func TestProfileLabels(t *testing.T) {
+	req := requestWithBody("POST", "/", `{"foo": "bar"}`)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	labels := profileLabels(jsonBinding{}, req, &FooStructForProfile{})
+
+	ctx := pprof.WithLabels(context.Background(), labels)
+	value, _ := pprof.Label(ctx, "binding.struct")
+	assert.Equal(t, "binding.FooStructForProfile", value)
+	value, _ = pprof.Label(ctx, "binding.content_type")
+	assert.Equal(t, MIMEJSON, value)
+	value, _ = pprof.Label(ctx, "binding.name")
+	assert.Equal(t, "json", value)
+}
+
+func TestBindWithProfileLabels(t *testing.T) {
+	d := NewDecoder(Options{ProfileLabels: true})
+	req := requestWithBody("POST", "/", `{"foo": "bar"}`)
+	req.Header.Set("Content-Type", MIMEJSON)
+	var obj FooStructForProfile
+	assert.NoError(t, d.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+}
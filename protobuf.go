@@ -17,7 +17,14 @@ func (protobufBinding) Name() string {
 	return "protobuf"
 }
 
-func (protobufBinding) Bind(req *http.Request, obj interface{}) error {
+func (b protobufBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (protobufBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	if err := s.watchDigest(req); err != nil {
+		return err
+	}
 
 	buf, err := ioutil.ReadAll(req.Body)
 	if err != nil {
@@ -27,9 +34,15 @@ func (protobufBinding) Bind(req *http.Request, obj interface{}) error {
 	if err = proto.Unmarshal(buf, obj.(proto.Message)); err != nil {
 		return err
 	}
+	if err = s.cleanStrings(obj); err != nil {
+		return err
+	}
+	if err = s.finishBody(req, req.Body, obj); err != nil {
+		return err
+	}
 
 	//Here it's same to return validate(obj), but util now we cann't add `binding:""` to the struct
 	//which automatically generate by gen-proto
-	return nil
+	return s.perms.apply()
 	//return validate(obj)
 }
@@ -6,16 +6,32 @@ package binding
 
 import "net/http"
 
+// queryBinding binds the query of requests, reading the keys of fields from
+// their query tag before their json and form ones, so that the same struct
+// can bind query parameters and form bodies under different names, e.g.
+//
+//	Page int `query:"page" form:"page_number"`
+//
+// binds from page with Query and from page_number with FormPost. A query tag
+// of "-" leaves a field out of the query.
 type queryBinding struct{}
 
 func (queryBinding) Name() string {
 	return "query"
 }
 
-func (queryBinding) Bind(req *http.Request, obj interface{}) error {
-	values := req.URL.Query()
-	if err := mapForm(obj, values); err != nil {
+func (b queryBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (queryBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	s.fromQuery = true
+	if err := s.trackKeyOrder(req, obj, false); err != nil {
+		return err
+	}
+	values := parseQuery(req.URL.RawQuery)
+	if err := mapFormState(obj, values, s); err != nil {
 		return err
 	}
-	return validate(obj)
+	return s.validate(obj)
 }
@@ -0,0 +1,102 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// numBound is a compiled min, max or step tag, parsed according to the kind
+// of its field. Bounds of time.Duration fields are durations, e.g. 5m, or
+// numbers of nanoseconds.
+type numBound struct {
+	raw string
+	i   int64
+	u   uint64
+	f   float64
+}
+
+func compileBound(typ reflect.Type, tag string) (*numBound, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	b := &numBound{raw: tag}
+	var err error
+	switch elemKind(typ) {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if elemType(typ) == durationType {
+			if d, derr := time.ParseDuration(tag); derr == nil {
+				b.i = int64(d)
+				break
+			}
+		}
+		b.i, err = strconv.ParseInt(tag, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b.u, err = strconv.ParseUint(tag, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		b.f, err = strconv.ParseFloat(tag, 64)
+	default:
+		return nil, errors.New("min and max tags need a numeric field")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (f *fieldInfo) checkIntRange(raw string, v int64) error {
+	if f == nil {
+		return nil
+	}
+	if (f.min != nil && v < f.min.i) || (f.max != nil && v > f.max.i) {
+		return f.rangeError(raw)
+	}
+	return nil
+}
+
+func (f *fieldInfo) checkUintRange(raw string, v uint64) error {
+	if f == nil {
+		return nil
+	}
+	if (f.min != nil && v < f.min.u) || (f.max != nil && v > f.max.u) {
+		return f.rangeError(raw)
+	}
+	return nil
+}
+
+// checkFloatRange checks v, parsed from raw, against the min and max tags.
+// NaN and infinities are out of any range.
+func (f *fieldInfo) checkFloatRange(raw string, v float64) error {
+	if f == nil || f.min == nil && f.max == nil {
+		return nil
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) || (f.min != nil && v < f.min.f) || (f.max != nil && v > f.max.f) {
+		return f.rangeError(raw)
+	}
+	return nil
+}
+
+// checkStep checks that d, parsed from raw, is a multiple of the step tag.
+func (f *fieldInfo) checkStep(raw string, d time.Duration) error {
+	if f == nil || f.step == nil || int64(d)%f.step.i == 0 {
+		return nil
+	}
+	return &StepError{Value: raw, Step: f.step.raw}
+}
+
+func (f *fieldInfo) rangeError(raw string) error {
+	err := &RangeError{Value: raw}
+	if f.min != nil {
+		err.Min = f.min.raw
+	}
+	if f.max != nil {
+		err.Max = f.max.raw
+	}
+	return err
+}
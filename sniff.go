@@ -0,0 +1,82 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// Sniff is a binding choosing how to decode the body from its first
+// non-whitespace byte: '{' or '[' for JSON, '<' for XML and a URL-encoded form
+// otherwise. Bodies starting with a UTF-16 byte order mark, which can't be
+// sniffed, fail with a *ContentTypeError. It suits clients which don't send a
+// Content-Type, and is used by Decoder with the ContentTypeSniff policy.
+var Sniff = sniffBinding{}
+
+type sniffBinding struct{}
+
+func (sniffBinding) Name() string {
+	return "sniff"
+}
+
+func (b sniffBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (sniffBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	if req.Body == nil {
+		return formBinding{}.bind(req, obj, s)
+	}
+	body := bufio.NewReader(req.Body)
+	if prefix, _ := body.Peek(len(bomUTF16BE)); bytes.Equal(prefix, bomUTF16BE) || bytes.Equal(prefix, bomUTF16LE) {
+		return &ContentTypeError{
+			ContentType: req.Header.Get("Content-Type"),
+			Method:      req.Method,
+			Accepted:    SupportedMediaTypes(),
+		}
+	}
+	var (
+		b           stateBinding = formBinding{}
+		contentType              = MIMEPOSTForm
+	)
+	switch sniffByte(body) {
+	case '{', '[':
+		b, contentType = jsonBinding{}, MIMEJSON
+	case '<':
+		b, contentType = xmlBinding{}, MIMEXML
+	}
+	r := withContentType(req, contentType)
+	// the peeked bytes are still buffered, so the binding sees the full body
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, req.Body}
+	return b.bind(r, obj, s)
+}
+
+// sniffByte returns the first byte of body which isn't whitespace, after a
+// leading UTF-8 byte order mark, without consuming it. It returns 0 if there
+// is none within the buffer of body.
+func sniffByte(body *bufio.Reader) byte {
+	start := 0
+	if prefix, _ := body.Peek(len(bomUTF8)); bytes.Equal(prefix, bomUTF8) {
+		start = len(bomUTF8)
+	}
+	for n := start + 1; n <= body.Size(); n++ {
+		buf, err := body.Peek(n)
+		if err != nil {
+			return 0
+		}
+		switch c := buf[n-1]; c {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return c
+		}
+	}
+	return 0
+}
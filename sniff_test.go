@@ -0,0 +1,68 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniffBinding(t *testing.T) {
+	assert.Equal(t, "sniff", Sniff.Name())
+
+	for _, body := range []string{
+		`{"foo": "bar"}`,
+		"\n\t {\"foo\": \"bar\"}",
+		"\xef\xbb\xbf{\"foo\": \"bar\"}",
+		"<map><foo>bar</foo></map>",
+		"foo=bar",
+	} {
+		obj := FooStruct{}
+		req := requestWithBody("POST", "/", body)
+		assert.NoError(t, Sniff.Bind(req, &obj), body)
+		assert.Equal(t, "bar", obj.Foo, body)
+		assert.Equal(t, "", req.Header.Get("Content-Type"))
+	}
+
+	obj := FooStruct{}
+	req := requestWithBody("POST", "/", `{"foo": `)
+	assert.Error(t, Sniff.Bind(req, &obj))
+
+	req, _ = http.NewRequest("POST", "/", nil)
+	assert.Error(t, Sniff.Bind(req, &obj))
+
+	for _, body := range []string{"\xff\xfe{\x00}\x00", "\xfe\xff\x00{\x00}"} {
+		req = requestWithBody("POST", "/", body)
+		err := Sniff.Bind(req, &obj)
+		var ctErr *ContentTypeError
+		assert.True(t, errors.As(err, &ctErr), body)
+		assert.Equal(t, http.StatusUnsupportedMediaType, StatusCode(err))
+	}
+}
+
+func TestDecoderSniff(t *testing.T) {
+	obj := FooStruct{}
+	req := requestWithBody("POST", "/", `{"foo": "bar"}`)
+	err := NewDecoder(Options{UnknownContentType: ContentTypeSniff}).Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", obj.Foo)
+}
+
+func TestSniffByte(t *testing.T) {
+	for body, c := range map[string]byte{
+		"\xef\xbb\xbf {":    '{',
+		"\xef\xbb\xbf":      0,
+		"\xbb\xef\xbf{":     0xbb,
+		" \xef\xbb\xbf{":    0xef,
+		"\xef\xbb\xbf\xef{": 0xef,
+	} {
+		assert.Equal(t, c, sniffByte(bufio.NewReader(strings.NewReader(body))), body)
+	}
+}
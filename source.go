@@ -0,0 +1,50 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import "strings"
+
+// Source is a flat key/value store a struct can be bound from with MapSource,
+// such as a Redis hash or a set of etcd keys.
+type Source interface {
+	// Form returns the values of the source keyed like a form.
+	Form() map[string][]string
+}
+
+// MapSource binds the values of src onto obj, which must be a pointer to a
+// struct, using the same tags and conversions as the form bindings, then
+// validates obj.
+func MapSource(obj interface{}, src Source) error {
+	s := newBindState()
+	if err := mapFormState(obj, src.Form(), s); err != nil {
+		return err
+	}
+	return s.validate(obj)
+}
+
+// StringMap is a Source of single valued keys, as returned by Redis HGETALL.
+type StringMap map[string]string
+
+// Form returns the values of m keyed like a form.
+func (m StringMap) Form() map[string][]string {
+	form := make(map[string][]string, len(m))
+	for key, value := range m {
+		form[key] = []string{value}
+	}
+	return form
+}
+
+// PrefixedMap returns the keys of m starting with prefix as a StringMap, with
+// the prefix removed. It suits stores listing keys by prefix, e.g. the keys
+// under "/config/app/" in etcd.
+func PrefixedMap(prefix string, m map[string]string) StringMap {
+	result := make(StringMap, len(m))
+	for key, value := range m {
+		if strings.HasPrefix(key, prefix) {
+			result[key[len(prefix):]] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForSource struct {
+	User  string `form:"user" binding:"required"`
+	Count int    `form:"count"`
+	Admin bool   `form:"admin" default:"false"`
+}
+
+func TestMapSourceStringMap(t *testing.T) {
+	var obj FooStructForSource
+	err := MapSource(&obj, StringMap{"user": "manu", "count": "3", "other": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, FooStructForSource{User: "manu", Count: 3}, obj)
+
+	obj = FooStructForSource{}
+	err = MapSource(&obj, StringMap{"count": "3"})
+	assert.Error(t, err)
+
+	err = MapSource(&obj, StringMap{"user": "manu", "count": "x"})
+	assert.Error(t, err)
+}
+
+func TestMapSourcePrefixedMap(t *testing.T) {
+	src := PrefixedMap("/config/app/", map[string]string{
+		"/config/app/user":   "manu",
+		"/config/app/admin":  "true",
+		"/config/other/user": "other",
+	})
+	assert.Equal(t, StringMap{"user": "manu", "admin": "true"}, src)
+
+	var obj FooStructForSource
+	assert.NoError(t, MapSource(&obj, src))
+	assert.Equal(t, FooStructForSource{User: "manu", Admin: true}, obj)
+}
+
+func TestMapSourceDerive(t *testing.T) {
+	var obj FooStructForDerive
+	assert.NoError(t, MapSource(&obj, StringMap{"email": "Ada@Example.com", "last": "Lovelace"}))
+	assert.Equal(t, "ada@example.com", obj.NormalizedEmail)
+	assert.Equal(t, "Lovelace", obj.FullName)
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+var nullTimeType = reflect.TypeOf(sql.NullTime{})
+
+// isSQLNull reports whether typ is one of the nullable types of database/sql,
+// such as sql.NullString, sql.NullInt64 or sql.Null[T]: a value followed by
+// its Valid flag.
+func isSQLNull(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.PkgPath() == "database/sql" && typ.NumField() == 2 &&
+		typ.Field(1).Name == "Valid" && typ.Field(1).Type.Kind() == reflect.Bool
+}
+
+// setSQLNullField sets the sql.Null* value from val, converted like the
+// values of fields of the type it wraps are, with Valid set. An empty val
+// makes it null.
+func setSQLNullField(val string, field *fieldInfo, value reflect.Value) error {
+	if val == "" {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+	result := reflect.New(value.Type()).Elem()
+	if err := setWithProperType(result.Field(0).Type(), val, result.Field(0), field); err != nil {
+		return err
+	}
+	result.Field(1).SetBool(true)
+	value.Set(result)
+	return nil
+}
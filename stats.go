@@ -0,0 +1,174 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/go-playground/validator.v8"
+)
+
+// statsSamples is the number of most recent durations a BindStats keeps per
+// struct type to compute their 99th percentile.
+const statsSamples = 1024
+
+// BindStats counts the binds of each struct type, see Options.Stats, so that
+// operators can tell which requests fail to bind most often. It is safe for
+// concurrent use and serves its snapshot as JSON.
+type BindStats struct {
+	types sync.Map // map[reflect.Type]*typeStats
+}
+
+// TypeStats is the snapshot of the binds of a struct type.
+type TypeStats struct {
+	Type   string `json:"type"`
+	Binds  uint64 `json:"binds"`
+	Failed uint64 `json:"failed"`
+	// Errors counts the failed binds by category: limit, content_type,
+	// permission, strict, field, validation or decode.
+	Errors map[string]uint64 `json:"errors,omitempty"`
+	// P99 is the 99th percentile of the durations of the last 1024 binds.
+	P99 time.Duration `json:"p99"`
+	// Bytes is the total size of the bodies read.
+	Bytes int64 `json:"bytes"`
+}
+
+type typeStats struct {
+	mu        sync.Mutex
+	binds     uint64
+	failed    uint64
+	errors    map[string]uint64
+	durations [statsSamples]time.Duration
+	bytes     int64
+}
+
+// Snapshot returns the stats of every struct type bound, the most failing
+// first.
+func (s *BindStats) Snapshot() []TypeStats {
+	var snapshot []TypeStats
+	s.types.Range(func(key, value interface{}) bool {
+		snapshot = append(snapshot, value.(*typeStats).snapshot(key.(reflect.Type).String()))
+		return true
+	})
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Failed != snapshot[j].Failed {
+			return snapshot[i].Failed > snapshot[j].Failed
+		}
+		return snapshot[i].Type < snapshot[j].Type
+	})
+	return snapshot
+}
+
+// ServeHTTP writes the snapshot of s as JSON.
+func (s *BindStats) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", MIMEJSON+"; charset=utf-8")
+	json.NewEncoder(w).Encode(s.Snapshot())
+}
+
+func (t *typeStats) snapshot(typ string) TypeStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := TypeStats{Type: typ, Binds: t.binds, Failed: t.failed, Bytes: t.bytes}
+	if len(t.errors) > 0 {
+		stats.Errors = make(map[string]uint64, len(t.errors))
+		for category, n := range t.errors {
+			stats.Errors[category] = n
+		}
+	}
+	n := t.binds
+	if n > statsSamples {
+		n = statsSamples
+	}
+	if n > 0 {
+		durations := append([]time.Duration(nil), t.durations[:n]...)
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		stats.P99 = durations[int(math.Ceil(float64(n)*0.99))-1]
+	}
+	return stats
+}
+
+// watch counts the bytes of the body of req as it is read.
+func (s *BindStats) watch(req *http.Request) *countingBody {
+	body := &countingBody{ReadCloser: req.Body}
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = body
+	}
+	return body
+}
+
+// record counts the bind of obj, which took d, read body and returned err.
+func (s *BindStats) record(obj interface{}, d time.Duration, body *countingBody, err error) {
+	typ := reflect.TypeOf(obj)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return
+	}
+	value, ok := s.types.Load(typ)
+	if !ok {
+		value, _ = s.types.LoadOrStore(typ, &typeStats{})
+	}
+	t := value.(*typeStats)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.durations[t.binds%statsSamples] = d
+	t.binds++
+	t.bytes += body.n
+	if err != nil {
+		t.failed++
+		if t.errors == nil {
+			t.errors = make(map[string]uint64)
+		}
+		t.errors[errorCategory(err)]++
+	}
+}
+
+// errorCategory returns the category of a bind error counted by BindStats.
+func errorCategory(err error) string {
+	var (
+		limitErr       *LimitError
+		contentTypeErr *ContentTypeError
+		permErr        *PermissionError
+		strictErr      *StrictError
+		fieldErr       *FieldError
+		validationErrs validator.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &limitErr):
+		return "limit"
+	case errors.As(err, &contentTypeErr):
+		return "content_type"
+	case errors.As(err, &permErr):
+		return "permission"
+	case errors.As(err, &strictErr):
+		return "strict"
+	case errors.As(err, &fieldErr):
+		return "field"
+	case errors.As(err, &validationErrs):
+		return "validation"
+	}
+	return "decode"
+}
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
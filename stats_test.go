@@ -0,0 +1,58 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindStats(t *testing.T) {
+	stats := &BindStats{}
+	defer withOptions(Options{Stats: stats, MaxBodySize: 32})()
+
+	body := `{"foo": "bar"}`
+	assert.NoError(t, JSON.Bind(requestWithBody("POST", "/", body), &FooStruct{}))
+	assert.Error(t, JSON.Bind(requestWithBody("POST", "/", `{}`), &FooStruct{}))
+	assert.Error(t, JSON.Bind(requestWithBody("POST", "/", `{"foo": "a very long value for the limit"}`), &FooStruct{}))
+	assert.Error(t, Query.Bind(requestWithBody("GET", "/?page=x", ""), &FooStructForPagination{}))
+	assert.Error(t, JSON.Bind(requestWithBody("POST", "/", `{`), &FooBarStruct{}))
+
+	snapshot := stats.Snapshot()
+	if assert.Len(t, snapshot, 3) {
+		foo := snapshot[0]
+		assert.Equal(t, "binding.FooStruct", foo.Type)
+		assert.Equal(t, uint64(3), foo.Binds)
+		assert.Equal(t, uint64(2), foo.Failed)
+		assert.Equal(t, map[string]uint64{"validation": 1, "limit": 1}, foo.Errors)
+		assert.Equal(t, int64(len(body)+len(`{}`)), foo.Bytes)
+		assert.True(t, foo.P99 > 0)
+
+		assert.Equal(t, "binding.FooBarStruct", snapshot[1].Type)
+		assert.Equal(t, map[string]uint64{"decode": 1}, snapshot[1].Errors)
+		assert.Equal(t, "binding.FooStructForPagination", snapshot[2].Type)
+		assert.Equal(t, map[string]uint64{"field": 1}, snapshot[2].Errors)
+	}
+
+	w := httptest.NewRecorder()
+	stats.ServeHTTP(w, requestWithBody("GET", "/", ""))
+	var served []TypeStats
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &served))
+	assert.Equal(t, snapshot, served)
+}
+
+func TestTypeStatsP99(t *testing.T) {
+	s := &typeStats{}
+	for i := 1; i <= statsSamples+100; i++ {
+		s.durations[s.binds%statsSamples] = time.Duration(i)
+		s.binds++
+	}
+	// the first 100 samples were overwritten
+	assert.Equal(t, time.Duration(1114), s.snapshot("").P99)
+}
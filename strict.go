@@ -0,0 +1,73 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// StrictMode selects how the strict checks of the form bindings are applied:
+// input keys no field is bound from, deprecated aliases and, with
+// Options.CheckHeaders, unexpected custom headers, the issues reported as
+// Warnings, are then violations.
+type StrictMode int
+
+const (
+	// StrictOff binds leniently.
+	StrictOff StrictMode = iota
+	// StrictReport binds leniently but passes violations to
+	// Options.ReportStrict, e.g. to count them, so that API owners can
+	// measure what enforcing them would break.
+	StrictReport
+	// StrictEnforce fails binds with violations with a *StrictError.
+	StrictEnforce
+)
+
+// StrictError is returned by binds with violations of the strict checks when
+// the StrictEnforce mode is used.
+type StrictError struct {
+	Violations Warnings
+}
+
+func (e *StrictError) Error() string {
+	if len(e.Violations) == 1 {
+		return fmt.Sprintf("binding: strict check failed: %s", e.Violations[0])
+	}
+	return fmt.Sprintf("binding: strict check failed: %s (and %d more)", e.Violations[0], len(e.Violations)-1)
+}
+
+// checking reports whether warnings are collected, for the caller or for
+// the Strict option.
+func (s *bindState) checking() bool {
+	return s.collectWarnings || s.opts.Strict != StrictOff
+}
+
+// checkStrict applies the Strict option to the warnings of binding req,
+// returning err, the error of the bind, or the *StrictError failing it.
+func (s *bindState) checkStrict(req *http.Request, err error) error {
+	var violations Warnings
+	for _, w := range s.warnings {
+		if w.Kind != WarningNormalizedValue {
+			violations = append(violations, w)
+		}
+	}
+	if len(violations) == 0 {
+		return err
+	}
+	switch s.opts.Strict {
+	case StrictReport:
+		if report := s.opts.ReportStrict; report != nil {
+			for _, w := range violations {
+				report(req, w)
+			}
+		}
+	case StrictEnforce:
+		if err == nil {
+			return &StrictError{Violations: violations}
+		}
+	}
+	return err
+}
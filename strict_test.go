@@ -0,0 +1,55 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictReport(t *testing.T) {
+	var reported []Warning
+	defer withOptions(Options{
+		Strict: StrictReport,
+		ReportStrict: func(req *http.Request, w Warning) {
+			assert.Equal(t, "/users", req.URL.Path)
+			reported = append(reported, w)
+		},
+	})()
+
+	var obj FooStruct
+	req := requestWithBody("GET", "/users?foo=bar&extra=1", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+	if assert.Len(t, reported, 1) {
+		assert.Equal(t, WarningUnknownKey, reported[0].Kind)
+		assert.Equal(t, "extra", reported[0].Key)
+	}
+
+	reported = nil
+	assert.NoError(t, Query.Bind(requestWithBody("GET", "/users?foo=bar", ""), &obj))
+	assert.Empty(t, reported)
+}
+
+func TestStrictEnforce(t *testing.T) {
+	d := NewDecoder(Options{Strict: StrictEnforce})
+
+	var obj FooStruct
+	req := requestWithBody("GET", "/?foo=bar&extra=1&other=2", "")
+	err := d.Bind(req, &obj)
+	var strictErr *StrictError
+	if assert.True(t, errors.As(err, &strictErr)) {
+		assert.Len(t, strictErr.Violations, 2)
+		assert.EqualError(t, err, `binding: strict check failed: unknown_key: unknown key "extra" ignored (and 1 more)`)
+	}
+	assert.NoError(t, d.Bind(requestWithBody("GET", "/?foo=bar", ""), &obj))
+
+	warnings, err := BindWithWarnings(requestWithBody("GET", "/?foo=bar&extra=1", ""), &obj, Query)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+}
@@ -0,0 +1,216 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+	"unique"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrInvalidUTF8 is returned for strings which aren't valid UTF-8 when the
+// UTF8Reject policy is in use.
+var ErrInvalidUTF8 = errors.New("binding: invalid UTF-8")
+
+// ErrControlChar is returned for strings containing a control character when
+// the ControlReject policy is in use.
+var ErrControlChar = errors.New("binding: control character in string")
+
+// controlSet is the set of control characters a field accepts, parsed from
+// its control tag.
+type controlSet uint8
+
+const (
+	// allowNewline accepts \n and \r, since browsers send text area line
+	// breaks as CRLF.
+	allowNewline controlSet = 1 << iota
+	allowTab
+)
+
+func parseControlTag(tag string) (controlSet, error) {
+	var set controlSet
+	if tag == "" {
+		return set, nil
+	}
+	for _, name := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(name) {
+		case "newline":
+			set |= allowNewline
+		case "tab":
+			set |= allowTab
+		default:
+			return 0, fmt.Errorf("invalid control tag %q", tag)
+		}
+	}
+	return set, nil
+}
+
+// disallows reports whether c is a control character outside of set.
+func (set controlSet) disallows(c byte) bool {
+	switch {
+	case c == '\n' || c == '\r':
+		return set&allowNewline == 0
+	case c == '\t':
+		return set&allowTab == 0
+	}
+	return c < 0x20 || c == 0x7f
+}
+
+// maxInterned is the length of the longest strings interned with the
+// InternStrings option.
+const maxInterned = 64
+
+// cleansStrings reports whether o changes bound strings at all.
+func (o *Options) cleansStrings() bool {
+	return o.Normalization != NormalizeNone || o.InvalidUTF8 != UTF8Allow || o.ControlChars != ControlAllow || o.InternStrings
+}
+
+// cleanString applies the string options of o to a single value, allowing
+// the control characters in allowed.
+func (o *Options) cleanString(val string, allowed controlSet) (string, error) {
+	if o.InvalidUTF8 != UTF8Allow && !utf8.ValidString(val) {
+		switch o.InvalidUTF8 {
+		case UTF8Reject:
+			return "", ErrInvalidUTF8
+		case UTF8Replace:
+			val = strings.ToValidUTF8(val, string(utf8.RuneError))
+		case UTF8Strip:
+			val = strings.ToValidUTF8(val, "")
+		}
+	}
+
+	if o.ControlChars != ControlAllow {
+		var err error
+		if val, err = o.cleanControl(val, allowed); err != nil {
+			return "", err
+		}
+	}
+
+	switch o.Normalization {
+	case NormalizeNFC:
+		val = norm.NFC.String(val)
+	case NormalizeNFKC:
+		val = norm.NFKC.String(val)
+	}
+
+	if o.InternStrings && len(val) <= maxInterned {
+		val = unique.Make(val).Value()
+	}
+	return val, nil
+}
+
+func (o *Options) cleanControl(val string, allowed controlSet) (string, error) {
+	i := 0
+	for i < len(val) && !allowed.disallows(val[i]) {
+		i++
+	}
+	if i == len(val) {
+		return val, nil
+	}
+	if o.ControlChars == ControlReject {
+		return "", ErrControlChar
+	}
+	b := []byte(val[:i])
+	for ; i < len(val); i++ {
+		if !allowed.disallows(val[i]) {
+			b = append(b, val[i])
+		}
+	}
+	return string(b), nil
+}
+
+// cleanString applies the string options to val, bound from key, warning
+// when they change it.
+func (s *bindState) cleanString(key, val string, allowed controlSet) (string, error) {
+	cleaned, err := s.opts.cleanString(val, allowed)
+	if err == nil && cleaned != val {
+		s.warn(Warning{
+			Kind:    WarningNormalizedValue,
+			Key:     key,
+			Message: fmt.Sprintf("value of %q changed by normalization", key),
+		})
+	}
+	return cleaned, err
+}
+
+// cleanStrings applies the string options to every string reachable from
+// obj, which body bindings decode as a whole.
+func (s *bindState) cleanStrings(obj interface{}) error {
+	if !s.opts.cleansStrings() {
+		return nil
+	}
+	return s.cleanValue(reflect.ValueOf(obj), "", 0)
+}
+
+// cleanValue cleans the strings of v, found at path.
+func (s *bindState) cleanValue(v reflect.Value, path string, allowed controlSet) error {
+	switch v.Kind() {
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+		str, err := s.cleanString(path, v.String(), allowed)
+		if err != nil {
+			return err
+		}
+		v.SetString(str)
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return s.cleanValue(v.Elem(), path, allowed)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		elem := v.Elem()
+		if elem.Kind() == reflect.String && v.CanSet() {
+			str, err := s.cleanString(path, elem.String(), allowed)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(str).Convert(elem.Type()))
+			return nil
+		}
+		return s.cleanValue(elem, path, allowed)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			typeField := v.Type().Field(i)
+			fieldAllowed, err := parseControlTag(typeField.Tag.Get("control"))
+			if err != nil {
+				return err
+			}
+			if err := s.cleanValue(v.Field(i), joinPath(path, typeField.Name), fieldAllowed); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := s.cleanValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), allowed); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if !v.CanInterface() {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			// map elements aren't addressable, so clean a copy and store
+			// it back
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := s.cleanValue(elem, fmt.Sprintf("%s[%v]", path, iter.Key()), allowed); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
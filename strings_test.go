@@ -0,0 +1,185 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForNestedStrings struct {
+	Name  string                 `json:"name"`
+	Tags  []string               `json:"tags"`
+	Attrs map[string]string      `json:"attrs"`
+	Any   map[string]interface{} `json:"any"`
+	Ptr   *string                `json:"ptr"`
+}
+
+func withOptions(opts Options) func() {
+	saved := DefaultOptions
+	DefaultOptions = opts
+	return func() { DefaultOptions = saved }
+}
+
+func TestNormalizationForm(t *testing.T) {
+	defer withOptions(Options{Normalization: NormalizeNFC})()
+
+	obj := FooBarStruct{}
+	req := requestWithBody("GET", "/?foo=caf%65%CC%81&bar=%EF%AC%81", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, "caf\u00e9", obj.Foo)
+	assert.Equal(t, "\ufb01", obj.Bar)
+
+	DefaultOptions.Normalization = NormalizeNFKC
+	obj = FooBarStruct{}
+	req = requestWithBody("GET", "/?foo=caf%65%CC%81&bar=%EF%AC%81", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, "caf\u00e9", obj.Foo)
+	assert.Equal(t, "fi", obj.Bar)
+}
+
+func TestNormalizationJSON(t *testing.T) {
+	defer withOptions(Options{Normalization: NormalizeNFC})()
+
+	obj := FooStructForNestedStrings{}
+	req := requestWithBody("POST", "/", `{
+		"name": "e\u0301",
+		"tags": ["e\u0301"],
+		"attrs": {"k": "e\u0301"},
+		"any": {"k": "e\u0301", "l": ["e\u0301"]},
+		"ptr": "e\u0301"
+	}`)
+	assert.NoError(t, JSON.Bind(req, &obj))
+	assert.Equal(t, "\u00e9", obj.Name)
+	assert.Equal(t, []string{"\u00e9"}, obj.Tags)
+	assert.Equal(t, map[string]string{"k": "\u00e9"}, obj.Attrs)
+	assert.Equal(t, "\u00e9", obj.Any["k"])
+	assert.Equal(t, []interface{}{"\u00e9"}, obj.Any["l"])
+	assert.Equal(t, "\u00e9", *obj.Ptr)
+}
+
+func TestNormalizationDisabled(t *testing.T) {
+	obj := FooBarStruct{}
+	req := requestWithBody("GET", "/?foo=caf%65%CC%81&bar=x", "")
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, "cafe\u0301", obj.Foo)
+}
+
+func TestInvalidUTF8Policy(t *testing.T) {
+	defer withOptions(Options{})()
+
+	bind := func() (FooBarStruct, error) {
+		obj := FooBarStruct{}
+		req := requestWithBody("GET", "/?foo=a%FF%FEb&bar=ok", "")
+		err := Query.Bind(req, &obj)
+		return obj, err
+	}
+
+	obj, err := bind()
+	assert.NoError(t, err)
+	assert.Equal(t, "a\xff\xfeb", obj.Foo)
+
+	DefaultOptions.InvalidUTF8 = UTF8Reject
+	_, err = bind()
+	assert.True(t, errors.Is(err, ErrInvalidUTF8))
+
+	DefaultOptions.InvalidUTF8 = UTF8Replace
+	obj, err = bind()
+	assert.NoError(t, err)
+	assert.Equal(t, "a\ufffdb", obj.Foo)
+	assert.Equal(t, "ok", obj.Bar)
+
+	DefaultOptions.InvalidUTF8 = UTF8Strip
+	obj, err = bind()
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", obj.Foo)
+}
+
+func TestInvalidUTF8PolicyNested(t *testing.T) {
+	opts := Options{InvalidUTF8: UTF8Reject}
+	s := &bindState{opts: &opts}
+	err := s.cleanStrings(&FooStructForNestedStrings{Tags: []string{"ok", "\xff"}})
+	assert.True(t, errors.Is(err, ErrInvalidUTF8))
+	assert.NoError(t, s.cleanStrings(&FooStructForNestedStrings{Tags: []string{"ok"}}))
+
+	opts.InvalidUTF8 = UTF8Strip
+	obj := FooStructForNestedStrings{Attrs: map[string]string{"k": "a\xffb"}}
+	assert.NoError(t, s.cleanStrings(&obj))
+	assert.Equal(t, "ab", obj.Attrs["k"])
+}
+
+type FooStructForControlChars struct {
+	Title string `form:"title" json:"title"`
+	Body  string `form:"body" json:"body" control:"newline,tab"`
+}
+
+type FooStructForBadControlTag struct {
+	Body string `form:"body" control:"bell"`
+}
+
+func TestControlCharPolicy(t *testing.T) {
+	defer withOptions(Options{ControlChars: ControlStrip})()
+
+	form := map[string][]string{
+		"title": {"a\x1b[31mb\r\nc\x7f"},
+		"body":  {"line\r\n\tindented\x00"},
+	}
+	obj := FooStructForControlChars{}
+	assert.NoError(t, mapForm(&obj, form))
+	assert.Equal(t, "a[31mbc", obj.Title)
+	assert.Equal(t, "line\r\n\tindented", obj.Body)
+
+	DefaultOptions.ControlChars = ControlReject
+	obj = FooStructForControlChars{}
+	err := mapForm(&obj, map[string][]string{"body": {"a\nb"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb", obj.Body)
+	err = mapForm(&obj, map[string][]string{"title": {"a\nb"}})
+	assert.True(t, errors.Is(err, ErrControlChar))
+
+	obj = FooStructForControlChars{}
+	req := requestWithBody("POST", "/", `{"title": "x\ty", "body": "x\ty"}`)
+	assert.True(t, errors.Is(JSON.Bind(req, &obj), ErrControlChar))
+
+	DefaultOptions.ControlChars = ControlStrip
+	obj = FooStructForControlChars{}
+	req = requestWithBody("POST", "/", `{"title": "x\ty", "body": "x\ty"}`)
+	assert.NoError(t, JSON.Bind(req, &obj))
+	assert.Equal(t, "xy", obj.Title)
+	assert.Equal(t, "x\ty", obj.Body)
+}
+
+func TestControlCharBadTag(t *testing.T) {
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadControlTag]() })
+}
+
+func TestInternStrings(t *testing.T) {
+	bindCountry := func() string {
+		var obj FooStruct
+		// built at run time, so that requests don't share the data of a
+		// string constant
+		assert.NoError(t, Query.Bind(requestWithBody("GET", "/?foo="+strings.ToUpper("no"), ""), &obj))
+		return obj.Foo
+	}
+	a, b := bindCountry(), bindCountry()
+	assert.False(t, unsafe.StringData(a) == unsafe.StringData(b))
+
+	defer withOptions(Options{InternStrings: true})()
+	a, b = bindCountry(), bindCountry()
+	assert.Equal(t, "NO", a)
+	assert.True(t, unsafe.StringData(a) == unsafe.StringData(b))
+
+	var obj FooStructForNestedStrings
+	long := strings.Repeat("x", 65)
+	req := requestWithBody("POST", "/", `{"name": "NO", "tags": ["NO", "`+long+`"]}`)
+	assert.NoError(t, JSON.Bind(req, &obj))
+	assert.True(t, unsafe.StringData(a) == unsafe.StringData(obj.Name))
+	assert.True(t, unsafe.StringData(a) == unsafe.StringData(obj.Tags[0]))
+	assert.Equal(t, long, obj.Tags[1])
+}
@@ -0,0 +1,646 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// structInfo is the compiled binding metadata of a struct type. It is built
+// once per type and cached, so tags are parsed and checked on first use only.
+type structInfo struct {
+	fields []*fieldInfo
+	// ordered is set if the struct has OrderedMap fields bound from
+	// bracketed keys, see trackKeyOrder.
+	ordered bool
+	// derives reports whether the struct or its nested structs have derived
+	// fields.
+	derives bool
+	// derived lists the fields computed once the struct is bound, in field
+	// order.
+	derived []*derivation
+}
+
+// fieldInfo is the compiled binding metadata of a single struct field.
+type fieldInfo struct {
+	index int
+	name  string
+	key   string
+	// alias is a deprecated key the field is still bound from when key is
+	// absent.
+	alias string
+
+	// nested is set for untagged struct fields, such as embedded ones, whose
+	// own fields are bound from the same (flat) form, with their keys
+	// prefixed by prefix, from the prefix tag, e.g.
+	//
+	//	Audit `prefix:"audit_"`
+	nested bool
+	prefix string
+	// recursive is set for nested pointer fields whose struct holds the
+	// field again, e.g. Parent *Category `prefix:"parent_"`. They are bound
+	// as deep as the form has keys for, up to Options.MaxDepth.
+	recursive bool
+
+	defaultValue string
+	// defaultIf are the cases of the default_if tag, see defaultCase.
+	defaultIf []defaultCase
+	// steps are the steps of the steps tag, see Stage.
+	steps []Step
+
+	// min and max bound numeric fields, checked right after conversion.
+	min, max *numBound
+	// step is the step tag of time.Duration fields, which values have to be
+	// a multiple of.
+	step *numBound
+	// pattern is the compiled pattern tag of string fields.
+	pattern *regexp.Regexp
+	// control is the set of control characters accepted by the field.
+	control controlSet
+	// htmlBool is set by the bool_format:"html" tag, see htmlBool.
+	htmlBool bool
+	// separator splits the values of slice fields, from the
+	// collection_format tag, see collectionFormats.
+	separator string
+	// encoding is the encoding tag of []byte fields, see byteEncodings.
+	encoding string
+	// length is the len tag of []byte fields, the number of bytes their
+	// values have to decode to.
+	length int
+	// maxItems is the max_items tag of slice fields, the most values they
+	// accept.
+	maxItems int
+
+	timeFormat   string
+	timeLocation *time.Location
+	// noJSON is set by the nojson option of the key tag, e.g.
+	// `form:"filter,nojson"`, see Options.NoJSONValues.
+	noJSON bool
+	// location is set for *time.Location and []*time.Location fields, see
+	// Options.WindowsTimeZones.
+	location bool
+
+	// disc is the disc tag of interface fields, the sibling key naming the
+	// implementation they are bound into, see RegisterDiscriminated.
+	disc string
+
+	// timeRange is set for TimeRange fields.
+	timeRange *timeRangeSpec
+	// file is set for UploadedFile and *UploadedFile fields.
+	file bool
+	// checksum is set for fields receiving the checksum of a file.
+	checksum *checksumSpec
+	// timeout is set for fields receiving a client timeout.
+	timeout *timeoutSpec
+	// objectKey is the key of the file whose object key the field
+	// receives, see Options.StoreFile.
+	objectKey string
+
+	// sources are the sources of the field tagged with in.
+	sources []fieldSource
+	// sourceOnly is set for fields omitted from binding but tagged with in.
+	sourceOnly bool
+
+	// perms lists the permissions any of which grants binding the field,
+	// see PermissionFilter.
+	perms []string
+}
+
+// structKey identifies the metadata of a struct type bound using a given tag.
+// The empty tag stands for the json and form tags of form bindings, whose
+// tags carry the naming of untagged fields, see withNaming.
+type structKey struct {
+	typ reflect.Type
+	tag string
+	// stages is set for the metadata read by the post-bind stages, see
+	// cachedStageInfo.
+	stages bool
+}
+
+var structCache sync.Map // map[structKey]*structInfo
+
+// structTags lists the tags struct metadata is compiled for.
+var structTags = []string{"", formFirstTag, queryTag, queryFormFirstTag, "header", "trailer"}
+
+// formFirstTag is the tag struct metadata is compiled for by the form
+// bindings with the FormTagFirst option, reading form tags before json ones,
+// while they read json tags first with the empty tag. queryTag and
+// queryFormFirstTag are their counterparts for the Query binding, reading
+// query tags first.
+const (
+	formFirstTag      = "form,json"
+	queryTag          = "query,json,form"
+	queryFormFirstTag = "query,form,json"
+)
+
+// formTag reports whether the form bindings compile struct metadata for tag,
+// reading keys from json and form tags.
+func formTag(tag string) bool {
+	tag, _ = splitNaming(tag)
+	switch tag {
+	case "", formFirstTag, queryTag, queryFormFirstTag:
+		return true
+	}
+	return false
+}
+
+// tagKey returns the key of f with tag, from the first of the tags it lists
+// f has, e.g. from its json tag, else its form tag, for the empty tag.
+func tagKey(f reflect.StructField, tag string) string {
+	tag, _ = splitNaming(tag)
+	names := []string{"json", "form"}
+	if tag != "" {
+		names = strings.Split(tag, ",")
+	}
+	for _, name := range names {
+		if key := f.Tag.Get(name); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// keyTag returns the tag the form bindings compile struct metadata for with
+// the options of s, the query tags being read first by the Query binding.
+func (s *bindState) keyTag() string {
+	var tag string
+	switch {
+	case s.fromQuery && s.opts.FormTagFirst:
+		tag = queryFormFirstTag
+	case s.fromQuery:
+		tag = queryTag
+	case s.opts.FormTagFirst:
+		tag = formFirstTag
+	}
+	return withNaming(tag, s.opts.FieldNaming)
+}
+
+// withNaming returns the form tag tag compiling the keys of untagged fields
+// with naming, suffixed with it unless it is NamingFieldName.
+func withNaming(tag string, naming Naming) string {
+	if naming == NamingFieldName {
+		return tag
+	}
+	return tag + "|" + strconv.Itoa(int(naming))
+}
+
+// splitNaming splits the naming suffix of withNaming off tag.
+func splitNaming(tag string) (string, Naming) {
+	i := strings.LastIndexByte(tag, '|')
+	if i < 0 {
+		return tag, NamingFieldName
+	}
+	n, _ := strconv.Atoi(tag[i+1:])
+	return tag[:i], Naming(n)
+}
+
+// headerTags are the tags naming HTTP header fields, whose keys are matched in
+// canonical form.
+var headerTags = map[string]bool{"header": true, "trailer": true}
+
+// MustValidateStruct compiles and checks the binding metadata of T, panicking
+// if any of its tags are misconfigured. It is meant to be called at init time
+// so that broken tags fail on deploy rather than on the first request using
+// them:
+//
+//	func init() {
+//		binding.MustValidateStruct[LoginForm]()
+//	}
+func MustValidateStruct[T any]() {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	for _, tag := range structTags {
+		if _, err := cachedStructInfo(typ, tag); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func cachedStructInfo(typ reflect.Type, tag string) (*structInfo, error) {
+	return cachedInfo(structKey{typ: typ, tag: tag})
+}
+
+// cachedStageInfo returns the metadata of typ read by the post-bind stages
+// of bindState.validate, which the body bindings run too: the keys and
+// nesting of fields and their derive and perm tags. The other tags are only
+// compiled for the fields tagged with in, which resolveSources binds, so that
+// body bindings don't fail on form tags they don't use.
+func cachedStageInfo(typ reflect.Type, tag string) (*structInfo, error) {
+	return cachedInfo(structKey{typ: typ, tag: tag, stages: true})
+}
+
+func cachedInfo(key structKey) (*structInfo, error) {
+	if info, ok := structCache.Load(key); ok {
+		return info.(*structInfo), nil
+	}
+	info, err := compileStruct(key.typ, key.tag, key.stages)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := structCache.LoadOrStore(key, info)
+	return actual.(*structInfo), nil
+}
+
+func compileStruct(typ reflect.Type, tag string, stages bool) (*structInfo, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("binding: %s is not a struct", typ)
+	}
+	info := &structInfo{}
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		// unexported fields can't be set, but the exported fields of
+		// unexported embedded structs can, as they are promoted. Pointers to
+		// them are skipped since they couldn't be allocated.
+		exported := typeField.PkgPath == ""
+		if !exported && (!typeField.Anonymous || typeField.Type.Kind() != reflect.Struct) {
+			continue
+		}
+		field, err := compileField(typeField, tag, stages)
+		if err != nil {
+			return nil, fmt.Errorf("binding: %s.%s: %v", typ.Name(), typeField.Name, err)
+		}
+		if !exported && field != nil && !field.nested {
+			field = nil
+		}
+		if formTag(tag) && exported {
+			d, err := compileDerivation(typ, typeField)
+			if err != nil {
+				return nil, fmt.Errorf("binding: %s.%s: %v", typ.Name(), typeField.Name, err)
+			}
+			if d != nil {
+				info.derived = append(info.derived, d)
+			}
+		}
+		if field == nil {
+			continue
+		}
+		if !stages || len(field.sources) > 0 {
+			if field.defaultIf, err = compileDefaultIf(typ, typeField); err != nil {
+				return nil, fmt.Errorf("binding: %s.%s: %v", typ.Name(), typeField.Name, err)
+			}
+		}
+		field.index = i
+		info.fields = append(info.fields, field)
+		if field.recursive {
+			// the metadata of the struct may still be compiling
+			info.ordered = info.ordered || holdsOrderedMap(nestedType(typeField.Type), make(map[reflect.Type]bool))
+		} else if field.nested {
+			nested, err := cachedInfo(structKey{nestedType(typeField.Type), tag, stages})
+			if err != nil {
+				return nil, err
+			}
+			info.ordered = info.ordered || nested.ordered
+			// the methods of structs reached through unexported fields
+			// can't be called
+			if nested.derives && !exported {
+				return nil, fmt.Errorf("binding: %s.%s: derive tag needs an exported embedded struct", typ.Name(), typeField.Name)
+			}
+			info.derives = info.derives || nested.derives
+		} else if isOrderedMap(typeField.Type) {
+			info.ordered = true
+		}
+	}
+	info.derives = info.derives || len(info.derived) > 0
+	return info, nil
+}
+
+// compileField returns the metadata of a single field, or nil if the field is
+// omitted from binding using tag. For the post-bind stages, only the tags
+// they read are compiled, see cachedStageInfo.
+func compileField(typeField reflect.StructField, tag string, stages bool) (*fieldInfo, error) {
+	field := &fieldInfo{
+		name:         typeField.Name,
+		defaultValue: typeField.Tag.Get("default"),
+	}
+
+	key := tagKey(typeField, tag)
+	if key == "" {
+		// if "form" tag is nil, we inspect if the field is a struct, or a
+		// pointer to one. this would not make sense for JSON parsing but it
+		// does for a form since data is flatten. Structs bound as a whole,
+		// such as TimeRange or converted types, aren't nested. Pointers to
+		// structs reaching back to themselves are only nested with a prefix,
+		// which lengthens their keys at each level.
+		ptr := typeField.Type.Kind() == reflect.Ptr
+		typ := nestedType(typeField.Type)
+		recursive := ptr && typ.Kind() == reflect.Struct && reaches(typ, typ, tag, make(map[reflect.Type]bool))
+		if recursive && typeField.Tag.Get("prefix") != "" {
+			field.nested = true
+			field.recursive = true
+			field.prefix = typeField.Tag.Get("prefix")
+			return field, nil
+		}
+		if typ.Kind() == reflect.Struct && !boundWhole(typ) && !recursive {
+			nested, err := cachedInfo(structKey{typ, tag, stages})
+			if err != nil {
+				return nil, err
+			}
+			if len(nested.fields) > 0 {
+				field.nested = true
+				field.prefix = typeField.Tag.Get("prefix")
+				return field, nil
+			}
+			// pointers to structs without bound fields, such as *time.Time,
+			// are still bound by name below
+			if !ptr {
+				return nil, nil
+			}
+		}
+		// only the form bindings fall back to the field name
+		if !formTag(tag) {
+			return nil, nil
+		}
+		_, naming := splitNaming(tag)
+		key = naming.apply(typeField.Name)
+	}
+	if typeField.Tag.Get("prefix") != "" {
+		return nil, errors.New("prefix tag needs an untagged struct field")
+	}
+	// omit field, unless it is only resolved from the sources of its in tag
+	if strings.HasPrefix(key, "-") {
+		if typeField.Tag.Get("in") == "" {
+			return nil, nil
+		}
+		field.sourceOnly = true
+	}
+	field.noJSON = hasTagOption(key, "nojson") || hasTagOption(typeField.Tag.Get("form"), "nojson")
+	if idx := strings.Index(key, ","); idx != -1 {
+		key = key[:idx]
+	}
+	if headerTags[tag] {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+	}
+	field.key = key
+	field.perms = compilePerms(typeField)
+	if stages && typeField.Tag.Get("in") == "" {
+		return field, nil
+	}
+	field.alias = typeField.Tag.Get("alias")
+
+	field.timeFormat = typeField.Tag.Get("time_format")
+	if layout, ok := timeFormatAliases[field.timeFormat]; ok {
+		field.timeFormat = layout
+	}
+	field.timeLocation = time.Local
+	field.location = locationValued(typeField.Type)
+	if utcTag := typeField.Tag.Get("time_utc"); utcTag != "" {
+		isUTC, err := strconv.ParseBool(utcTag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time_utc %q", utcTag)
+		}
+		if isUTC {
+			field.timeLocation = time.UTC
+		}
+	}
+	if locTag := typeField.Tag.Get("time_location"); locTag != "" {
+		loc, err := time.LoadLocation(ianaZone(locTag))
+		if err != nil {
+			return nil, err
+		}
+		field.timeLocation = loc
+	}
+
+	var err error
+	if formTag(tag) && typeField.Type == timeRangeType {
+		if field.timeRange, err = compileTimeRange(typeField); err != nil {
+			return nil, err
+		}
+	}
+
+	if field.timeout, err = compileTimeout(typeField); err != nil {
+		return nil, err
+	}
+
+	if formTag(tag) {
+		field.file = isFileType(typeField.Type)
+		if field.checksum, err = compileChecksum(typeField); err != nil {
+			return nil, err
+		}
+		if field.objectKey = typeField.Tag.Get("object_key"); field.objectKey != "" && typeField.Type.Kind() != reflect.String {
+			return nil, errors.New("object_key tag needs a string field")
+		}
+	}
+
+	if field.min, err = compileBound(typeField.Type, typeField.Tag.Get("min")); err != nil {
+		return nil, fmt.Errorf("invalid min: %v", err)
+	}
+	if field.max, err = compileBound(typeField.Type, typeField.Tag.Get("max")); err != nil {
+		return nil, fmt.Errorf("invalid max: %v", err)
+	}
+	if stepTag := typeField.Tag.Get("step"); stepTag != "" {
+		if elemType(typeField.Type) != durationType {
+			return nil, errors.New("step tag needs a time.Duration field")
+		}
+		if field.step, err = compileBound(typeField.Type, stepTag); err != nil || field.step.i <= 0 {
+			return nil, fmt.Errorf("invalid step %q", stepTag)
+		}
+	}
+
+	if field.control, err = parseControlTag(typeField.Tag.Get("control")); err != nil {
+		return nil, err
+	}
+	if field.encoding = typeField.Tag.Get("encoding"); field.encoding != "" {
+		if _, ok := byteEncodings[field.encoding]; !ok {
+			return nil, fmt.Errorf("unknown encoding %q", field.encoding)
+		}
+		// string fields are copied []byte values into by Copy
+		if typ := nestedType(typeField.Type); !isBytes(typ) && typ.Kind() != reflect.String {
+			return nil, errors.New("encoding tag needs a []byte or string field")
+		}
+	}
+	if lenTag := typeField.Tag.Get("len"); lenTag != "" {
+		if field.length, err = strconv.Atoi(lenTag); err != nil || field.length <= 0 {
+			return nil, fmt.Errorf("invalid len %q", lenTag)
+		}
+		if !isBytes(nestedType(typeField.Type)) {
+			return nil, errors.New("len tag needs a []byte field")
+		}
+	}
+	if maxTag := typeField.Tag.Get("max_items"); maxTag != "" {
+		if field.maxItems, err = strconv.Atoi(maxTag); err != nil || field.maxItems <= 0 {
+			return nil, fmt.Errorf("invalid max_items %q", maxTag)
+		}
+		if typeField.Type.Kind() != reflect.Slice || isBytes(typeField.Type) {
+			return nil, errors.New("max_items tag needs a slice field")
+		}
+	}
+	if format := typeField.Tag.Get("collection_format"); format != "" {
+		sep, ok := collectionFormats[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown collection_format %q", format)
+		}
+		if kind := typeField.Type.Kind(); kind != reflect.Slice && kind != reflect.Array || !repeatable(typeField.Type.Elem()) || isBytes(typeField.Type) {
+			return nil, errors.New("collection_format tag needs a slice or array of strings, bools or numbers")
+		}
+		field.separator = sep
+	}
+	switch format := typeField.Tag.Get("bool_format"); format {
+	case "":
+	case "html":
+		field.htmlBool = true
+	default:
+		return nil, fmt.Errorf("invalid bool_format %q", format)
+	}
+
+	if field.disc = typeField.Tag.Get("disc"); field.disc != "" && typeField.Type.Kind() != reflect.Interface {
+		return nil, errors.New("disc tag needs an interface field")
+	}
+
+	if pattern := typeField.Tag.Get("pattern"); pattern != "" {
+		if elemKind(typeField.Type) != reflect.String {
+			return nil, errors.New("pattern tag needs a string field")
+		}
+		if field.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+	}
+
+	if field.sources, err = compileSources(typeField.Tag.Get("in")); err != nil {
+		return nil, err
+	}
+
+	if field.steps, err = compileSteps(typeField); err != nil {
+		return nil, err
+	}
+
+	if field.defaultValue != "" {
+		value := reflect.New(typeField.Type).Elem()
+		if err := setDefaultValue(typeField.Type, field.defaultValue, value, field); err != nil {
+			return nil, fmt.Errorf("invalid default %q: %v", field.defaultValue, err)
+		}
+	}
+	return field, nil
+}
+
+// compilePerms returns the permissions listed by the perm tag of typeField.
+func compilePerms(typeField reflect.StructField) []string {
+	var perms []string
+	for _, perm := range strings.Split(typeField.Tag.Get("perm"), ",") {
+		if perm = strings.TrimSpace(perm); perm != "" {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+// nestedType returns the struct type of the nested fields of type typ,
+// looking through a pointer.
+func nestedType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		return typ.Elem()
+	}
+	return typ
+}
+
+// reaches reports whether the struct typ holds target through the fields
+// without a key using tag, which would be nested.
+func reaches(typ, target reflect.Type, tag string, seen map[reflect.Type]bool) bool {
+	if seen[typ] {
+		return false
+	}
+	seen[typ] = true
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if tagKey(f, tag) != "" {
+			continue
+		}
+		elem := nestedType(f.Type)
+		if elem == target || elem.Kind() == reflect.Struct && reaches(elem, target, tag, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// holdsOrderedMap reports whether the struct typ has OrderedMap fields,
+// directly or through its struct and pointer to struct fields.
+func holdsOrderedMap(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[typ] {
+		return false
+	}
+	seen[typ] = true
+	for i := 0; i < typ.NumField(); i++ {
+		elem := nestedType(typ.Field(i).Type)
+		if isOrderedMap(elem) || elem.Kind() == reflect.Struct && holdsOrderedMap(elem, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedValue returns the struct held by the nested field val, or an invalid
+// Value if val is a nil pointer.
+func nestedValue(val reflect.Value) reflect.Value {
+	if val.Kind() != reflect.Ptr {
+		return val
+	}
+	if val.IsNil() {
+		return reflect.Value{}
+	}
+	return val.Elem()
+}
+
+// bindNested calls bind with the struct held by the nested field val. If val
+// is a nil pointer, a new struct is allocated, which val is only set to if
+// bind leaves it non-zero, so that optional sections stay nil when absent.
+func bindNested(val reflect.Value, bind func(reflect.Value) error) error {
+	if val.Kind() != reflect.Ptr {
+		return bind(val)
+	}
+	if !val.IsNil() {
+		return bind(val.Elem())
+	}
+	v := reflect.New(val.Type().Elem())
+	err := bind(v.Elem())
+	if !v.Elem().IsZero() {
+		val.Set(v)
+	}
+	return err
+}
+
+// boundWhole reports whether struct fields of type typ are bound as a whole
+// rather than as nested structs.
+func boundWhole(typ reflect.Type) bool {
+	return typ == timeRangeType || typ == uploadedFileType || convertible(typ) || isOrderedMap(typ)
+}
+
+// hasTagOption reports whether the options of tag, after its key, include
+// option.
+func hasTagOption(tag, option string) bool {
+	opts := strings.Split(tag, ",")
+	for _, opt := range opts[1:] {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// elemType returns the type of the values of typ, looking through a pointer,
+// Lazy and the sql.Null* types.
+func elemType(typ reflect.Type) reflect.Type {
+	if reflect.PtrTo(typ).Implements(lazyValueType) {
+		typ = reflect.New(typ).Interface().(lazyValue).valueType()
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if isSQLNull(typ) {
+		typ = typ.Field(0).Type
+	}
+	return typ
+}
+
+// elemKind returns the kind of elemType(typ).
+func elemKind(typ reflect.Type) reflect.Kind {
+	return elemType(typ).Kind()
+}
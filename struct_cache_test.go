@@ -0,0 +1,88 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForBadDefault struct {
+	IntFoo int `form:"int_foo" default:"abc"`
+}
+
+type FooStructForBadTimeUTC struct {
+	TimeFoo time.Time `form:"time_foo" time_format:"2006-01-02" time_utc:"maybe"`
+}
+
+type FooStructForNestedBadTag struct {
+	Nested FooStructForTimeTypeFailLocation
+}
+
+func TestMustValidateStruct(t *testing.T) {
+	assert.NotPanics(t, func() { MustValidateStruct[FooBarStruct]() })
+	assert.NotPanics(t, func() { MustValidateStruct[FooBarStructForTimeType]() })
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadDefault]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadTimeUTC]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForTimeTypeFailLocation]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForNestedBadTag]() })
+	assert.Panics(t, func() { MustValidateStruct[int]() })
+}
+
+func TestStructInfoCached(t *testing.T) {
+	typ := reflect.TypeOf(FooBarStructForTimeType{})
+	info, err := cachedStructInfo(typ, "")
+	assert.NoError(t, err)
+	again, err := cachedStructInfo(typ, "")
+	assert.NoError(t, err)
+	assert.True(t, info == again)
+
+	assert.Len(t, info.fields, 2)
+	assert.Equal(t, "time_foo", info.fields[0].key)
+	assert.Equal(t, "Asia/Chongqing", info.fields[0].timeLocation.String())
+	assert.Equal(t, "UTC", info.fields[1].timeLocation.String())
+}
+
+func TestMapFormBadTag(t *testing.T) {
+	var obj FooStructForBadDefault
+	err := mapForm(&obj, map[string][]string{"int_foo": {"1"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "FooStructForBadDefault.IntFoo")
+}
+
+type FooStructForBodyFormTags struct {
+	Name    string `json:"name" min:"1"`
+	IntFoo  int    `json:"int_foo" default:"abc"`
+	Derived string `json:"-" derive:"method=Upper"`
+	Role    string `json:"role" perm:"admin"`
+}
+
+func (f *FooStructForBodyFormTags) Upper() string {
+	return strings.ToUpper(f.Name)
+}
+
+func TestBodyBindingFormTags(t *testing.T) {
+	var obj FooStructForBodyFormTags
+	err := JSON.Bind(requestWithBody("POST", "/", `{"name": "manu", "int_foo": 1}`), &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "MANU", obj.Derived)
+	assert.Equal(t, 1, obj.IntFoo)
+
+	obj = FooStructForBodyFormTags{}
+	err = BindWithPermissions(requestWithBody("POST", "/", `{"name": "manu", "role": "admin"}`), &obj, JSON, PermissionFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", obj.Role)
+
+	// form bindings still check every tag
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBodyFormTags]() })
+	var bad FooStructForBadDefault
+	err = mapForm(&bad, map[string][]string{"int_foo": {"1"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// timeoutUnits are the units of grpc-timeout values.
+var timeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// maxDuration is the longest time.Duration, which timeouts saturate at.
+const maxDuration = time.Duration(1<<63 - 1)
+
+// timeoutParsers parse timeouts by format of the timeout tag.
+var timeoutParsers = map[string]func(string) (time.Duration, error){
+	"duration": time.ParseDuration,
+	"grpc":     parseGRPCTimeout,
+	"seconds":  timeoutInUnit(time.Second),
+	"millis":   timeoutInUnit(time.Millisecond),
+}
+
+// timeoutSpec is the compiled binding metadata of a field receiving a client
+// timeout, e.g.
+//
+//	Timeout  time.Duration `header:"X-Request-Timeout" timeout:"seconds" timeout_max:"30s"`
+//	Deadline time.Time     `header:"Grpc-Timeout" timeout:"grpc"`
+//
+// The timeout tag selects the format of the value and timeout_max clamps it.
+// time.Time fields receive the deadline the timeout ends at.
+type timeoutSpec struct {
+	parse func(string) (time.Duration, error)
+	// max is the longest timeout bound, 0 for none.
+	max time.Duration
+}
+
+func compileTimeout(typeField reflect.StructField) (*timeoutSpec, error) {
+	format := typeField.Tag.Get("timeout")
+	if format == "" {
+		return nil, nil
+	}
+	spec := &timeoutSpec{parse: timeoutParsers[format]}
+	if spec.parse == nil {
+		return nil, fmt.Errorf("unknown timeout format %q", format)
+	}
+	if typeField.Type != durationType && typeField.Type != timeType {
+		return nil, errors.New("timeout tag needs a time.Duration or time.Time field")
+	}
+	if max := typeField.Tag.Get("timeout_max"); max != "" {
+		d, err := time.ParseDuration(max)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout_max %q", max)
+		}
+		spec.max = d
+	}
+	return spec, nil
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// setTimeout binds the timeout or deadline field from its key.
+func (m *formMapper) setTimeout(field *fieldInfo, structField reflect.Value) error {
+	values, ok := m.lookup(field)
+	if !ok {
+		return nil
+	}
+	val, err := m.state.transform(values[0], field, StageLookup)
+	if err != nil {
+		return err
+	}
+	d, err := field.timeout.parse(val)
+	if err != nil {
+		return err
+	}
+	if d < 0 {
+		return fmt.Errorf("negative timeout %q", val)
+	}
+	if max := field.timeout.max; max != 0 && d > max {
+		d = max
+	}
+	if structField.Type() == timeType {
+		structField.Set(reflect.ValueOf(timeNow().Add(d)))
+	} else {
+		structField.SetInt(int64(d))
+	}
+	return nil
+}
+
+// parseGRPCTimeout parses a grpc-timeout value: at most 8 digits followed by
+// a unit.
+func parseGRPCTimeout(val string) (time.Duration, error) {
+	if len(val) < 2 || len(val) > 9 {
+		return 0, fmt.Errorf("invalid grpc timeout %q", val)
+	}
+	unit, ok := timeoutUnits[val[len(val)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid grpc timeout unit in %q", val)
+	}
+	n, err := strconv.ParseUint(val[:len(val)-1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid grpc timeout %q", val)
+	}
+	if d := time.Duration(n); d > maxDuration/unit {
+		return maxDuration, nil
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// timeoutInUnit returns a parser of timeouts given as a decimal number of
+// unit.
+func timeoutInUnit(unit time.Duration) func(string) (time.Duration, error) {
+	return func(val string) (time.Duration, error) {
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, err
+		}
+		if math.IsNaN(f) {
+			return 0, fmt.Errorf("invalid timeout %q", val)
+		}
+		if f*float64(unit) >= float64(maxDuration) {
+			return maxDuration, nil
+		}
+		return time.Duration(f * float64(unit)), nil
+	}
+}
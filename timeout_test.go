@@ -0,0 +1,70 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForTimeout struct {
+	Timeout  time.Duration `header:"X-Request-Timeout" timeout:"seconds" timeout_max:"30s"`
+	Deadline time.Time     `header:"Grpc-Timeout" timeout:"grpc"`
+	Budget   time.Duration `form:"budget" timeout:"duration"`
+}
+
+type FooStructForBadTimeout struct {
+	Timeout int `header:"X-Request-Timeout" timeout:"seconds"`
+}
+
+type FooStructForBadTimeoutFormat struct {
+	Timeout time.Duration `header:"X-Request-Timeout" timeout:"weeks"`
+}
+
+func TestBindTimeout(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	req, _ := http.NewRequest("GET", "/?budget=1m30s", nil)
+	req.Header.Set("X-Request-Timeout", "2.5")
+	req.Header.Set("Grpc-Timeout", "250m")
+	var obj FooStructForTimeout
+	assert.NoError(t, Header.Bind(req, &obj))
+	assert.Equal(t, 2500*time.Millisecond, obj.Timeout)
+	assert.Equal(t, now.Add(250*time.Millisecond), obj.Deadline)
+
+	assert.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, 90*time.Second, obj.Budget)
+
+	req.Header.Set("X-Request-Timeout", "3600")
+	req.Header.Set("Grpc-Timeout", "99999999H")
+	assert.NoError(t, Header.Bind(req, &obj))
+	assert.Equal(t, 30*time.Second, obj.Timeout)
+	assert.Equal(t, now.Add(maxDuration), obj.Deadline)
+}
+
+func TestBindTimeoutFail(t *testing.T) {
+	for _, header := range []http.Header{
+		{"X-Request-Timeout": {"-1"}},
+		{"X-Request-Timeout": {"NaN"}},
+		{"X-Request-Timeout": {"soon"}},
+		{"Grpc-Timeout": {"1s"}},
+		{"Grpc-Timeout": {"123456789S"}},
+		{"Grpc-Timeout": {"S"}},
+	} {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header = header
+		assert.Error(t, Header.Bind(req, &FooStructForTimeout{}), "%v", header)
+	}
+}
+
+func TestBadTimeoutTag(t *testing.T) {
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadTimeout]() })
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadTimeoutFormat]() })
+}
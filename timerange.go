@@ -0,0 +1,127 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TimeRange is a span of time bound by the form bindings from a pair of keys,
+// "from" and "to" or else "start" and "end". Its field may set:
+//
+//   - range: the pair of keys, e.g. `range:"created_from,created_to"`
+//   - range_default: the span used when both keys are absent, ending now,
+//     e.g. `range_default:"last 24h"`; it also sets the start when only the
+//     end is given
+//   - range_max: the longest span accepted, e.g. `range_max:"720h"`
+//
+// A missing end defaults to now. Times are parsed using the time_format,
+// time_utc and time_location tags of the field, with the formats of time.Time
+// fields by default, e.g. 2006-01-02. The
+// start must not be after the end.
+type TimeRange struct {
+	From, To time.Time
+}
+
+// Duration returns the length of r.
+func (r TimeRange) Duration() time.Duration {
+	return r.To.Sub(r.From)
+}
+
+var timeRangeType = reflect.TypeOf(TimeRange{})
+
+// timeNow returns the current time, replaced in tests.
+var timeNow = time.Now
+
+// timeRangeSpec is the compiled binding metadata of a TimeRange field.
+type timeRangeSpec struct {
+	fromKeys, toKeys []string
+	// last is the span bound when both keys are absent.
+	last time.Duration
+	// max is the longest span accepted, 0 for none.
+	max time.Duration
+}
+
+func compileTimeRange(typeField reflect.StructField) (*timeRangeSpec, error) {
+	spec := &timeRangeSpec{
+		fromKeys: []string{"from", "start"},
+		toKeys:   []string{"to", "end"},
+	}
+	if keys := typeField.Tag.Get("range"); keys != "" {
+		parts := strings.Split(keys, ",")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid range %q", keys)
+		}
+		spec.fromKeys, spec.toKeys = parts[:1], parts[1:]
+	}
+	if last := typeField.Tag.Get("range_default"); last != "" {
+		d, err := time.ParseDuration(strings.TrimPrefix(last, "last "))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid range_default %q", last)
+		}
+		spec.last = d
+	}
+	if max := typeField.Tag.Get("range_max"); max != "" {
+		d, err := time.ParseDuration(max)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid range_max %q", max)
+		}
+		spec.max = d
+	}
+	return spec, nil
+}
+
+// setTimeRange binds the TimeRange field from its pair of keys.
+func (m *formMapper) setTimeRange(field *fieldInfo, structField reflect.Value) error {
+	spec := field.timeRange
+	var r TimeRange
+	hasFrom, err := m.lookupTime(spec.fromKeys, field, &r.From)
+	if err != nil {
+		return err
+	}
+	hasTo, err := m.lookupTime(spec.toKeys, field, &r.To)
+	if err != nil {
+		return err
+	}
+	if !hasFrom && !hasTo && spec.last == 0 {
+		return nil
+	}
+	if !hasTo {
+		r.To = timeNow().In(field.timeLocation)
+	}
+	if !hasFrom && spec.last != 0 {
+		r.From = r.To.Add(-spec.last)
+	}
+
+	if r.From.After(r.To) {
+		return errors.New("range start is after its end")
+	}
+	if spec.max != 0 && r.Duration() > spec.max {
+		return fmt.Errorf("range is longer than %s", spec.max)
+	}
+	structField.Set(reflect.ValueOf(r))
+	return nil
+}
+
+// lookupTime parses the value of the first of keys present into t.
+func (m *formMapper) lookupTime(keys []string, field *fieldInfo, t *time.Time) (bool, error) {
+	for _, key := range keys {
+		values := m.form[key]
+		if len(values) == 0 {
+			continue
+		}
+		m.markUsed(key)
+		val, err := m.state.transform(values[0], field, StageLookup)
+		if err != nil {
+			return true, err
+		}
+		return true, setTimeField(val, field, reflect.ValueOf(t).Elem())
+	}
+	return false, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForTimeRange struct {
+	Period  TimeRange `form:"period" range_default:"last 24h" range_max:"168h" time_utc:"1"`
+	Created TimeRange `range:"created_from,created_to" time_format:"2006-01-02" time_utc:"1"`
+}
+
+type FooStructForBadTimeRange struct {
+	Period TimeRange `range:"from"`
+}
+
+func TestMapFormTimeRange(t *testing.T) {
+	now := time.Date(2018, 9, 15, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	var obj FooStructForTimeRange
+	err := mapForm(&obj, map[string][]string{
+		"start":        {"2018-09-14T00:00:00Z"},
+		"end":          {"2018-09-15T00:00:00Z"},
+		"created_from": {"2018-01-01"},
+		"created_to":   {"2018-02-01"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, obj.Period.Duration())
+	assert.Equal(t, time.Date(2018, 9, 14, 0, 0, 0, 0, time.UTC), obj.Period.From)
+	assert.Equal(t, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), obj.Created.From)
+	assert.Equal(t, time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC), obj.Created.To)
+
+	obj = FooStructForTimeRange{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{}))
+	assert.Equal(t, TimeRange{From: now.Add(-24 * time.Hour), To: now}, obj.Period)
+	assert.Equal(t, TimeRange{}, obj.Created)
+
+	obj = FooStructForTimeRange{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"from": {"2018-09-15T10:00:00Z"}}))
+	assert.Equal(t, TimeRange{From: now.Add(-2 * time.Hour), To: now}, obj.Period)
+
+	obj = FooStructForTimeRange{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"to": {"2018-09-15T10:00:00Z"}}))
+	assert.Equal(t, 24*time.Hour, obj.Period.Duration())
+
+	obj = FooStructForTimeRange{}
+	assert.NoError(t, mapForm(&obj, map[string][]string{"from": {"2018-09-14"}, "to": {"2018-09-15T00:00:00.5Z"}}))
+	assert.Equal(t, time.Date(2018, 9, 14, 0, 0, 0, 0, time.UTC), obj.Period.From)
+	assert.Equal(t, time.Date(2018, 9, 15, 0, 0, 0, 5e8, time.UTC), obj.Period.To)
+}
+
+func TestMapFormTimeRangeFail(t *testing.T) {
+	var obj FooStructForTimeRange
+	err := mapForm(&obj, map[string][]string{"from": {"2018-09-15T00:00:00Z"}, "to": {"2018-09-14T00:00:00Z"}})
+	assert.EqualError(t, err, `binding: field "period": range start is after its end`)
+
+	err = mapForm(&obj, map[string][]string{"from": {"2018-01-01T00:00:00Z"}, "to": {"2018-09-14T00:00:00Z"}})
+	assert.EqualError(t, err, `binding: field "period": range is longer than 168h0m0s`)
+
+	err = mapForm(&obj, map[string][]string{"created_from": {"yesterday"}})
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MustValidateStruct[FooStructForBadTimeRange]() })
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+)
+
+// finishBody runs the steps common to all bindings reading a body, once obj
+// has been decoded from it: binding trailers and verifying the body digest.
+func (s *bindState) finishBody(req *http.Request, body io.Reader, obj interface{}) error {
+	if err := s.bindTrailers(req, body, obj); err != nil {
+		return err
+	}
+	return s.digest.verify()
+}
+
+// bindTrailers binds the fields of obj tagged with trailer from the trailers
+// of req, e.g.
+//
+//	Checksum string `trailer:"X-Checksum"`
+//
+// Trailers are only known once the body has been read to its end, so body is
+// drained first if obj has any such field.
+func (s *bindState) bindTrailers(req *http.Request, body io.Reader, obj interface{}) error {
+	typ := reflect.TypeOf(obj)
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	info, err := cachedStructInfo(typ.Elem(), "trailer")
+	if err != nil {
+		return err
+	}
+	if len(info.fields) == 0 {
+		return nil
+	}
+	if body != nil {
+		if _, err := io.Copy(ioutil.Discard, body); err != nil {
+			return err
+		}
+	}
+	m := &formMapper{form: req.Trailer, tag: "trailer", state: s}
+	return m.mapStruct(reflect.ValueOf(obj).Elem(), "")
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForTrailer struct {
+	Foo      string `json:"foo" form:"foo"`
+	Checksum string `json:"-" form:"-" trailer:"x-checksum"`
+	Size     int    `json:"-" form:"-" trailer:"X-Size" default:"-1"`
+}
+
+func bindWithTrailer(t *testing.T, b Binding, contentType, body string, trailer http.Header) FooStructForTrailer {
+	var obj FooStructForTrailer
+	var bindErr error
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		bindErr = b.Bind(req, &obj)
+	}))
+	defer ts.Close()
+
+	// wrapping the body hides its length, so it is sent chunked with trailers
+	req, err := http.NewRequest("POST", ts.URL, ioutil.NopCloser(strings.NewReader(body)))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	req.Trailer = trailer
+	resp, err := http.DefaultClient.Do(req)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+	assert.NoError(t, bindErr)
+	return obj
+}
+
+func TestBindingTrailers(t *testing.T) {
+	trailer := http.Header{"X-Checksum": {"abc"}, "X-Size": {"42"}}
+
+	obj := bindWithTrailer(t, JSON, MIMEJSON, `{"foo": "bar"}  `, trailer)
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, "abc", obj.Checksum)
+	assert.Equal(t, 42, obj.Size)
+
+	obj = bindWithTrailer(t, FormPost, MIMEPOSTForm, "foo=bar", trailer)
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, "abc", obj.Checksum)
+
+	obj = bindWithTrailer(t, JSON, MIMEJSON, `{"foo": "bar"}`, http.Header{"X-Checksum": nil})
+	assert.Equal(t, "", obj.Checksum)
+	assert.Equal(t, -1, obj.Size)
+}
+
+func TestBindingTrailersNotTagged(t *testing.T) {
+	obj := FooStruct{}
+	req := requestWithBody("POST", "/", `{"foo": "bar"}`)
+	req.Trailer = http.Header{"Foo": {"baz"}}
+	assert.NoError(t, JSON.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TusHeaders are the headers of the tus resumable upload protocol, to be
+// embedded in the struct bound by the Header binding of upload endpoints:
+//
+//	type PatchUpload struct {
+//		binding.TusHeaders
+//		UploadID string `header:"X-Upload-Id"`
+//	}
+type TusHeaders struct {
+	// Version is the version of the protocol used by the client.
+	Version string `header:"Tus-Resumable"`
+	// Offset is the offset of the content of the request in the upload.
+	Offset int64 `header:"Upload-Offset" min:"0"`
+	// Length is the size of the whole upload, nil if unknown.
+	Length *int64 `header:"Upload-Length" min:"0"`
+	// DeferLength is set when the size of the upload is not yet known.
+	DeferLength bool `header:"Upload-Defer-Length"`
+	// Metadata is the metadata of the upload.
+	Metadata UploadMetadata `header:"Upload-Metadata"`
+}
+
+// UploadMetadata is the Upload-Metadata header of the tus protocol: comma
+// separated pairs of a key and its base64 encoded value, the value being
+// optional, e.g.
+//
+//	filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential
+type UploadMetadata map[string]string
+
+// UnmarshalText decodes the header.
+func (m *UploadMetadata) UnmarshalText(text []byte) error {
+	md := make(UploadMetadata)
+	for _, pair := range strings.Split(string(text), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded := pair, ""
+		if i := strings.IndexByte(pair, ' '); i >= 0 {
+			key, encoded = pair[:i], strings.TrimSpace(pair[i+1:])
+		}
+		if _, ok := md[key]; ok {
+			return fmt.Errorf("duplicate upload metadata key %q", key)
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("upload metadata key %q: %v", key, err)
+		}
+		md[key] = string(value)
+	}
+	*m = md
+	return nil
+}
+
+// MarshalText encodes the header, sorting the keys.
+func (m UploadMetadata) MarshalText() ([]byte, error) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key
+		if value := m[key]; value != "" {
+			pairs[i] += " " + base64.StdEncoding.EncodeToString([]byte(value))
+		}
+	}
+	return []byte(strings.Join(pairs, ",")), nil
+}
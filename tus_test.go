@@ -0,0 +1,75 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForTus struct {
+	TusHeaders
+	UploadID string `header:"x-upload-id" binding:"required"`
+}
+
+func TestBindTusHeaders(t *testing.T) {
+	req, _ := http.NewRequest("PATCH", "/files/1", nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Offset", "1024")
+	req.Header.Set("Upload-Length", "4096")
+	req.Header.Set("Upload-Metadata", "filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==, is_confidential")
+	req.Header.Set("X-Upload-Id", "abc")
+
+	var obj FooStructForTus
+	assert.NoError(t, Header.Bind(req, &obj))
+	assert.Equal(t, "header", Header.Name())
+	assert.Equal(t, "1.0.0", obj.Version)
+	assert.Equal(t, int64(1024), obj.Offset)
+	if assert.NotNil(t, obj.Length) {
+		assert.Equal(t, int64(4096), *obj.Length)
+	}
+	assert.False(t, obj.DeferLength)
+	assert.Equal(t, UploadMetadata{"filename": "world_domination_plan.pdf", "is_confidential": ""}, obj.Metadata)
+	assert.Equal(t, "abc", obj.UploadID)
+
+	req.Header.Del("Upload-Length")
+	req.Header.Set("Upload-Defer-Length", "1")
+	obj = FooStructForTus{}
+	assert.NoError(t, Header.Bind(req, &obj))
+	assert.Nil(t, obj.Length)
+	assert.True(t, obj.DeferLength)
+}
+
+func TestBindTusHeadersFail(t *testing.T) {
+	req, _ := http.NewRequest("PATCH", "/files/1", nil)
+	req.Header.Set("X-Upload-Id", "abc")
+	req.Header.Set("Upload-Offset", "-1")
+	var rangeErr *RangeError
+	assert.True(t, errors.As(Header.Bind(req, &FooStructForTus{}), &rangeErr))
+
+	req.Header.Set("Upload-Offset", "0")
+	req.Header.Set("Upload-Metadata", "filename !!")
+	assert.Error(t, Header.Bind(req, &FooStructForTus{}))
+
+	req.Header.Set("Upload-Metadata", "a YQ==,a Yg==")
+	assert.Error(t, Header.Bind(req, &FooStructForTus{}))
+
+	req.Header.Del("Upload-Metadata")
+	req.Header.Del("X-Upload-Id")
+	assert.Error(t, Header.Bind(req, &FooStructForTus{}))
+}
+
+func TestUploadMetadataMarshalText(t *testing.T) {
+	text, err := UploadMetadata{"name": "a.txt", "flag": ""}.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "flag,name YS50eHQ=", string(text))
+
+	var md UploadMetadata
+	assert.NoError(t, md.UnmarshalText(text))
+	assert.Equal(t, UploadMetadata{"name": "a.txt", "flag": ""}, md)
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"a=1&b=2&a=3",
+		"a+b=c+d&e%20f=%41%2b%2F%c3%a9",
+		"&&a=&=b&c",
+		"bad=%zz&ok=1&trunc=%4&semi=a;b&x=%",
+		"k%3Dv=1&%26=2",
+	} {
+		expected, _ := url.ParseQuery(query)
+		assert.Equal(t, expected, parseQuery(query), query)
+	}
+}
+
+func TestQueryUnescape(t *testing.T) {
+	for _, s := range []string{"plain", "a+b", "%e4%bd%a0%E5%A5%BD", "100%25", "%", "%a", "%g0", "+%41+"} {
+		expected, err := url.QueryUnescape(s)
+		actual, ok := queryUnescape(s)
+		assert.Equal(t, err == nil, ok, s)
+		assert.Equal(t, expected, actual, s)
+	}
+}
+
+func largeQuery() string {
+	pairs := make([]string, 150)
+	for i := range pairs {
+		if i%10 == 0 {
+			pairs[i] = fmt.Sprintf("ref%d=https%%3A%%2F%%2Fexample.com%%2Fpage%%3Fid%%3D%d", i, i)
+		} else {
+			pairs[i] = fmt.Sprintf("p%d=value%d", i, i)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func BenchmarkParseQuery(b *testing.B) {
+	query := largeQuery()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parseQuery(query)
+	}
+}
+
+func BenchmarkURLParseQuery(b *testing.B) {
+	query := largeQuery()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		url.ParseQuery(query)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// WarningKind classifies a Warning.
+type WarningKind string
+
+const (
+	// WarningUnknownKey is reported by the form bindings for input keys no
+	// field is bound from. The body bindings, such as JSON and XML, leave the
+	// keys they don't know to their decoder, which ignores them.
+	WarningUnknownKey WarningKind = "unknown_key"
+	// WarningDeprecatedAlias is reported when a field is bound from the key
+	// named by its alias tag instead of its primary key.
+	WarningDeprecatedAlias WarningKind = "deprecated_alias"
+	// WarningUnexpectedHeader is reported by the Header binding for custom
+	// headers no field is bound from, see Options.CheckHeaders.
+	WarningUnexpectedHeader WarningKind = "unexpected_header"
+	// WarningNormalizedValue is reported when the string options, such as
+	// Options.Normalization or Options.InvalidUTF8, change a bound value. It
+	// isn't a violation of the strict checks.
+	WarningNormalizedValue WarningKind = "normalized_value"
+)
+
+// Warning describes a non-fatal issue found while binding. Warnings never fail
+// a bind; they let lenient endpoints log questionable input.
+type Warning struct {
+	Kind WarningKind
+	// Key is the input key the warning relates to.
+	Key     string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Kind, w.Message)
+}
+
+// Warnings is a list of Warning.
+type Warnings []Warning
+
+// BindWithWarnings binds the request with b like b.Bind does, additionally
+// returning the warnings found along the way. Bindings which can't report
+// warnings always return nil Warnings. Unknown keys are only reported by the
+// form bindings, see WarningUnknownKey.
+func BindWithWarnings(req *http.Request, obj interface{}, b Binding) (Warnings, error) {
+	sb, ok := b.(stateBinding)
+	if !ok {
+		return nil, b.Bind(req, obj)
+	}
+	s := newBindState()
+	s.collectWarnings = true
+	err := s.run(sb, req, obj)
+	return s.warnings, err
+}
+
+// warnUnknownKeys reports every key of form which wasn't used, in key order.
+func (s *bindState) warnUnknownKeys(form map[string][]string, used map[string]bool) {
+	var unknown []string
+	for key := range form {
+		if !used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		s.warn(Warning{
+			Kind:    WarningUnknownKey,
+			Key:     key,
+			Message: fmt.Sprintf("unknown key %q ignored", key),
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForAlias struct {
+	Foo string `form:"foo" alias:"old_foo"`
+}
+
+func TestBindWithWarningsUnknownKeys(t *testing.T) {
+	obj := FooBarStruct{}
+	req := requestWithBody("GET", "/?foo=bar&bar=foo&zzz=1&aaa=2", "")
+	warnings, err := BindWithWarnings(req, &obj, Query)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, Warnings{
+		{Kind: WarningUnknownKey, Key: "aaa", Message: `unknown key "aaa" ignored`},
+		{Kind: WarningUnknownKey, Key: "zzz", Message: `unknown key "zzz" ignored`},
+	}, warnings)
+}
+
+func TestBindWithWarningsDeprecatedAlias(t *testing.T) {
+	obj := FooStructForAlias{}
+	req := requestWithBody("POST", "/", "old_foo=bar")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	warnings, err := BindWithWarnings(req, &obj, FormPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, WarningDeprecatedAlias, warnings[0].Kind)
+	assert.Equal(t, "old_foo", warnings[0].Key)
+
+	obj = FooStructForAlias{}
+	req = requestWithBody("POST", "/", "foo=new&old_foo=old")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	warnings, err = BindWithWarnings(req, &obj, FormPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", obj.Foo)
+	assert.Equal(t, WarningUnknownKey, warnings[0].Kind)
+}
+
+func TestBindWithWarningsUnsupported(t *testing.T) {
+	obj := FooStruct{}
+	req := requestWithBody("POST", "/", `{"foo": "bar", "baz": 1}`)
+	warnings, err := BindWithWarnings(req, &obj, JSON)
+	assert.NoError(t, err)
+	assert.Nil(t, warnings)
+	assert.Equal(t, "bar", obj.Foo)
+}
+
+func TestBindWithWarningsNormalizedValue(t *testing.T) {
+	defer withOptions(Options{Normalization: NormalizeNFC, Strict: StrictEnforce})()
+
+	obj := FooStructForAlias{}
+	req := requestWithBody("POST", "/", "foo=e%CC%81")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	warnings, err := BindWithWarnings(req, &obj, FormPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "é", obj.Foo)
+	assert.Equal(t, Warnings{
+		{Kind: WarningNormalizedValue, Key: "foo", Message: `value of "foo" changed by normalization`},
+	}, warnings)
+
+	req = requestWithBody("POST", "/", "foo=plain")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	warnings, err = BindWithWarnings(req, &obj, FormPost)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	jsonObj := FooStruct{}
+	req = requestWithBody("POST", "/", "{\"foo\": \"é\"}")
+	warnings, err = BindWithWarnings(req, &jsonObj, JSON)
+	assert.NoError(t, err)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, WarningNormalizedValue, warnings[0].Kind)
+		assert.Equal(t, "Foo", warnings[0].Key)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WeightedValue is an element of a header weighted with q-values, such as
+// Accept, Accept-Encoding or Accept-Language, e.g. text/html;level=1;q=0.7.
+// Fields of type []WeightedValue or WeightedValues are bound from all the
+// values of their header, e.g.
+//
+//	Accept binding.WeightedValues `header:"Accept"`
+type WeightedValue struct {
+	// Value is the lowercased value, e.g. text/html, gzip or *.
+	Value string
+	// Params are its parameters other than q, with lowercased names.
+	Params map[string]string
+	Q      float64
+}
+
+// WeightedValues are the values of a weighted header, ordered from the most
+// preferred: by decreasing quality, then from the most specific, then in
+// header order.
+type WeightedValues []WeightedValue
+
+var weightedValueType = reflect.TypeOf(WeightedValue{})
+
+// isWeighted reports whether typ is a slice of WeightedValue.
+func isWeighted(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem() == weightedValueType
+}
+
+// ParseWeighted parses the weighted header h, whose values are separated by
+// commas, into WeightedValues. Values without a q parameter have a quality
+// of 1.
+func ParseWeighted(h string) (WeightedValues, error) {
+	var values WeightedValues
+	for _, elem := range strings.Split(h, ",") {
+		parts := strings.Split(elem, ";")
+		v := WeightedValue{Value: strings.ToLower(strings.TrimSpace(parts[0])), Q: 1}
+		if v.Value == "" {
+			continue
+		}
+		for _, param := range parts[1:] {
+			name, value, _ := strings.Cut(param, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			if name == "" {
+				continue
+			}
+			if name == "q" {
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil || q < 0 || q > 1 {
+					return nil, fmt.Errorf("invalid q-value %q", value)
+				}
+				v.Q = q
+				continue
+			}
+			if v.Params == nil {
+				v.Params = make(map[string]string)
+			}
+			v.Params[name] = value
+		}
+		values = append(values, v)
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		if values[i].Q != values[j].Q {
+			return values[i].Q > values[j].Q
+		}
+		return values[i].specificity() > values[j].specificity()
+	})
+	return values, nil
+}
+
+// specificity ranks v from the wildcards, * and */*, to media ranges, e.g.
+// text/*, and to values with more parameters.
+func (v WeightedValue) specificity() int {
+	switch {
+	case v.Value == "*" || v.Value == "*/*":
+		return 0
+	case strings.HasSuffix(v.Value, "/*"):
+		return 1
+	}
+	return 2 + len(v.Params)
+}
+
+// matches reports whether v matches offer, a value with optional parameters,
+// e.g. text/html or text/html;level=1.
+func (v WeightedValue) matches(offer string) bool {
+	parts := strings.Split(offer, ";")
+	value := strings.ToLower(strings.TrimSpace(parts[0]))
+	switch {
+	case v.Value == "*" || v.Value == "*/*":
+		return true
+	case strings.HasSuffix(v.Value, "/*"):
+		return strings.HasPrefix(value, strings.TrimSuffix(v.Value, "*"))
+	case v.Value != value:
+		return false
+	}
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		params[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	for name, want := range v.Params {
+		if got, ok := params[name]; !ok || !strings.EqualFold(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Quality returns the quality vs give to offer, from the most specific value
+// matching it, 0 if none does.
+func (vs WeightedValues) Quality(offer string) float64 {
+	q, specificity := 0.0, -1
+	for _, v := range vs {
+		if s := v.specificity(); s > specificity && v.matches(offer) {
+			q, specificity = v.Q, s
+		}
+	}
+	return q
+}
+
+// Best returns the offer vs give the highest quality, the first one between
+// offers of equal quality, or "" if they accept none. Without values, as for
+// an absent header, every offer is accepted.
+func (vs WeightedValues) Best(offers ...string) string {
+	if len(vs) == 0 && len(offers) > 0 {
+		return offers[0]
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := vs.Quality(offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// setWeightedField sets the slice of WeightedValue value from val.
+func setWeightedField(val string, value reflect.Value) error {
+	values, err := ParseWeighted(val)
+	if err != nil {
+		return err
+	}
+	value.Set(reflect.ValueOf(values).Convert(value.Type()))
+	return nil
+}
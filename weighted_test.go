@@ -0,0 +1,64 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FooStructForWeighted struct {
+	Accept   WeightedValues  `header:"Accept"`
+	Encoding []WeightedValue `header:"Accept-Encoding"`
+}
+
+func TestParseWeighted(t *testing.T) {
+	values, err := ParseWeighted(`text/*;q=0.3, text/html;q=0.7, text/html;level=1, */*;q=0.5, TEXT/HTML;Level="2";q=0.4,`)
+	assert.NoError(t, err)
+	assert.Equal(t, WeightedValues{
+		{Value: "text/html", Params: map[string]string{"level": "1"}, Q: 1},
+		{Value: "text/html", Q: 0.7},
+		{Value: "*/*", Q: 0.5},
+		{Value: "text/html", Params: map[string]string{"level": "2"}, Q: 0.4},
+		{Value: "text/*", Q: 0.3},
+	}, values)
+
+	for offer, q := range map[string]float64{
+		"text/html;level=1":  1,
+		"text/html":          0.7,
+		"text/plain":         0.3,
+		"image/jpeg":         0.5,
+		"text/html;level=2":  0.4,
+		"text/html;level=10": 0.7,
+	} {
+		assert.Equal(t, q, values.Quality(offer), offer)
+	}
+
+	_, err = ParseWeighted("gzip;q=2")
+	assert.EqualError(t, err, `invalid q-value "2"`)
+}
+
+func TestWeightedBest(t *testing.T) {
+	values, _ := ParseWeighted("gzip;q=0.5, br, identity;q=0")
+	assert.Equal(t, "br", values.Best("gzip", "br"))
+	assert.Equal(t, "gzip", values.Best("gzip", "identity"))
+	assert.Equal(t, "", values.Best("identity", "zstd"))
+	assert.Equal(t, "gzip", WeightedValues(nil).Best("gzip", "br"))
+}
+
+func TestBindWeighted(t *testing.T) {
+	var obj FooStructForWeighted
+	req := requestWithBody("GET", "/", "")
+	req.Header.Add("Accept", "text/html;q=0.5")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip, br;q=0.8")
+	assert.NoError(t, Header.Bind(req, &obj))
+	assert.Equal(t, WeightedValues{{Value: "application/json", Q: 1}, {Value: "text/html", Q: 0.5}}, obj.Accept)
+	assert.Equal(t, []WeightedValue{{Value: "gzip", Q: 1}, {Value: "br", Q: 0.8}}, obj.Encoding)
+
+	req.Header.Set("Accept", "text/html;q=x")
+	assert.EqualError(t, Header.Bind(req, &obj), `binding: field "Accept": invalid q-value "x"`)
+}
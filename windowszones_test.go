@@ -0,0 +1,44 @@
+// Copyright 2018 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowsZones(t *testing.T) {
+	for name, zone := range windowsZones {
+		_, err := time.LoadLocation(zone)
+		assert.NoError(t, err, name)
+	}
+}
+
+type FooStructForWindowsZones struct {
+	Zone   *time.Location   `form:"zone"`
+	Start  time.Time        `form:"start" time_format:"datetime" time_location:"Romance Standard Time"`
+	Others []*time.Location `form:"others"`
+}
+
+func TestMappingWindowsZones(t *testing.T) {
+	var obj FooStructForWindowsZones
+	form := map[string][]string{
+		"zone":   {"Tokyo Standard Time"},
+		"start":  {"2024-07-01 10:00:00"},
+		"others": {"UTC", "Europe/Madrid"},
+	}
+	err := mapForm(&obj, form)
+	assert.EqualError(t, err, `binding: field "zone": unknown time zone "Tokyo Standard Time"`)
+
+	defer withOptions(Options{WindowsTimeZones: true})()
+	assert.NoError(t, mapForm(&obj, form))
+	assert.Equal(t, "Asia/Tokyo", obj.Zone.String())
+	assert.Equal(t, "Europe/Paris", obj.Start.Location().String())
+	assert.Equal(t, time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC), obj.Start.UTC())
+	assert.Equal(t, "Etc/UTC", obj.Others[0].String())
+	assert.Equal(t, "Europe/Madrid", obj.Others[1].String())
+}
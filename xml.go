@@ -15,10 +15,31 @@ func (xmlBinding) Name() string {
 	return "xml"
 }
 
-func (xmlBinding) Bind(req *http.Request, obj interface{}) error {
-	decoder := xml.NewDecoder(req.Body)
+func (b xmlBinding) Bind(req *http.Request, obj interface{}) error {
+	return newBindState().run(b, req, obj)
+}
+
+func (xmlBinding) bind(req *http.Request, obj interface{}, s *bindState) error {
+	if err := s.watchDigest(req); err != nil {
+		return err
+	}
+	body, empty := openBody(req)
+	if empty {
+		return s.bindEmptyBody(obj)
+	}
+	r, transcoded := skipBOM(body)
+	decoder := xml.NewDecoder(r)
+	if transcoded {
+		decoder.CharsetReader = utf16CharsetReader
+	}
 	if err := decoder.Decode(obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	if err := s.cleanStrings(obj); err != nil {
+		return err
+	}
+	if err := s.finishBody(req, body, obj); err != nil {
+		return err
+	}
+	return s.validate(obj)
 }